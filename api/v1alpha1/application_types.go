@@ -43,6 +43,39 @@ type ApplicationSpec struct {
 
 	// Description refers to a brief description of the application.
 	Description string `json:"description,omitempty"`
+
+	// GitOpsCommit configures the identity, signing and message of the commits pushed to the GitOps repository.
+	GitOpsCommit GitOpsCommitConfiguration `json:"gitOpsCommit,omitempty"`
+
+	// ImageTagging is the default image tagging of the Components of the Application.
+	// +optional
+	ImageTagging *ImageTagging `json:"imageTagging,omitempty"`
+
+	// BuildCredentials are the default build credentials of the Components of the Application.
+	// +optional
+	BuildCredentials *BuildCredentials `json:"buildCredentials,omitempty"`
+}
+
+// GitOpsCommitConfiguration defines how the commits pushed to the GitOps repository of an Application are made
+type GitOpsCommitConfiguration struct {
+	// AuthorName is the name of the author and committer of the commits.
+	// Defaults to the identity in the git configuration of the service.
+	AuthorName string `json:"authorName,omitempty"`
+
+	// AuthorEmail is the email of the author and committer of the commits.
+	// Defaults to the identity in the git configuration of the service.
+	AuthorEmail string `json:"authorEmail,omitempty"`
+
+	// SigningSecret is the name of a Secret in the Application's namespace containing the key to sign the commits with.
+	// An SSH key is read from the "ssh-privatekey" key, an armored GPG key from the "gpg-privatekey" key, optionally
+	// along with the ID of the signing key in "gpg-keyid". Signing with an SSH key requires git 2.34 or later in the
+	// image of the service. The commits are not signed if left blank.
+	SigningSecret string `json:"signingSecret,omitempty"`
+
+	// MessageTemplate is a Go template for the commit messages. The template can reference .Component, .Application,
+	// .Namespace, .Resource (the kind and name of the resource that triggered the commit) and .Message (the default message).
+	// Defaults to the default message.
+	MessageTemplate string `json:"messageTemplate,omitempty"`
 }
 
 // ApplicationGitRepository defines a git repository for a given Application resource (either appmodel or gitops)
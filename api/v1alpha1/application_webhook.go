@@ -18,7 +18,9 @@ package v1alpha1
 
 import (
 	"fmt"
+	"io/ioutil"
 	"reflect"
+	"text/template"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -54,9 +56,9 @@ var _ webhook.Validator = &Application{}
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (r *Application) ValidateCreate() error {
+	applicationlog.Info("validating the create request", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object creation.
-	return nil
+	return validateGitOpsCommit(r.Spec.GitOpsCommit)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
@@ -77,6 +79,37 @@ func (r *Application) ValidateUpdate(old runtime.Object) error {
 		return fmt.Errorf("runtime object is not of type Application")
 	}
 
+	return validateGitOpsCommit(r.Spec.GitOpsCommit)
+}
+
+// commitMessageSample holds sample values of the fields the GitOps commit message templates can reference,
+// the same as those of the data the messages are rendered with
+var commitMessageSample = struct {
+	Component   string
+	Application string
+	Namespace   string
+	Resource    string
+	Message     string
+}{
+	Component:   "component",
+	Application: "application",
+	Namespace:   "namespace",
+	Resource:    "Component/component",
+	Message:     "Generating GitOps resources",
+}
+
+// validateGitOpsCommit validates the GitOps commit configuration of an Application. The message template is rendered
+// with sample data, so that references to unknown fields are rejected as well as syntax errors.
+func validateGitOpsCommit(commit GitOpsCommitConfiguration) error {
+	if commit.MessageTemplate != "" {
+		tmpl, err := template.New("message").Option("missingkey=error").Parse(commit.MessageTemplate)
+		if err != nil {
+			return fmt.Errorf("gitops commit message template is invalid: %v", err)
+		}
+		if err := tmpl.Execute(ioutil.Discard, commitMessageSample); err != nil {
+			return fmt.Errorf("gitops commit message template is invalid: %v", err)
+		}
+	}
 	return nil
 }
 
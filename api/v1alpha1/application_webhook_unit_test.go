@@ -84,6 +84,24 @@ func TestApplicationValidatingWebhook(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "commit message template cannot be invalid",
+			err:  "gitops commit message template is invalid",
+			updateApp: Application{
+				Spec: ApplicationSpec{
+					DisplayName: "My App",
+					AppModelRepository: ApplicationGitRepository{
+						URL: "http://appmodelrepo",
+					},
+					GitOpsRepository: ApplicationGitRepository{
+						URL: "http://gitopsrepo",
+					},
+					GitOpsCommit: GitOpsCommitConfiguration{
+						MessageTemplate: "{{ .Component ",
+					},
+				},
+			},
+		},
 		{
 			name: "not application",
 			err:  "runtime object is not of type Application",
@@ -118,6 +136,62 @@ func TestApplicationValidatingWebhook(t *testing.T) {
 	}
 }
 
+func TestApplicationCreateValidatingWebhook(t *testing.T) {
+
+	tests := []struct {
+		name string
+		app  Application
+		err  string
+	}{
+		{
+			name: "valid commit message template",
+			app: Application{
+				Spec: ApplicationSpec{
+					DisplayName: "My App",
+					GitOpsCommit: GitOpsCommitConfiguration{
+						MessageTemplate: "[{{ .Application }}] {{ .Message }}",
+					},
+				},
+			},
+		},
+		{
+			name: "invalid commit message template",
+			err:  "gitops commit message template is invalid",
+			app: Application{
+				Spec: ApplicationSpec{
+					DisplayName: "My App",
+					GitOpsCommit: GitOpsCommitConfiguration{
+						MessageTemplate: "{{ .Application ",
+					},
+				},
+			},
+		},
+		{
+			name: "commit message template referencing an unknown field",
+			err:  "gitops commit message template is invalid",
+			app: Application{
+				Spec: ApplicationSpec{
+					DisplayName: "My App",
+					GitOpsCommit: GitOpsCommitConfiguration{
+						MessageTemplate: "{{ .Environment }}: {{ .Message }}",
+					},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.app.ValidateCreate()
+
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.Contains(t, err.Error(), test.err)
+			}
+		})
+	}
+}
+
 func TestApplicationDeleteValidatingWebhook(t *testing.T) {
 
 	tests := []struct {
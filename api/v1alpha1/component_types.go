@@ -42,11 +42,26 @@ type GitSource struct {
 	// A relative path inside the git repo containing the component
 	Context string `json:"context,omitempty"`
 
+	// Additional relative paths inside the git repo whose changes trigger a build of the component.
+	// Only the changes to the context, unless it is the whole repository, and to these paths trigger a build.
+	// +optional
+	BuildTriggerPaths []string `json:"buildTriggerPaths,omitempty"`
+
 	// If specified, the devfile at the URL will be used for the component.
 	DevfileURL string `json:"devfileUrl,omitempty"`
 
 	// If specified, the dockerfile at the URL will be used for the component.
 	DockerfileURL string `json:"dockerfileUrl,omitempty"`
+
+	// The API URL of the git provider hosting the repository, used by Pipelines as Code.
+	// If not specified, it is taken from the git-provider-url annotation, or derived from the host of the repository URL.
+	// +optional
+	GitProviderURL string `json:"gitProviderUrl,omitempty"`
+
+	// The name of the user of the git provider token, which Pipelines as Code needs for Bitbucket repositories.
+	// If not specified, it is taken from the git-provider-user annotation, or from the Pipelines as Code secret.
+	// +optional
+	GitProviderUser string `json:"gitProviderUser,omitempty"`
 }
 
 // ComponentSource describes the Component source
@@ -105,6 +120,95 @@ type ComponentSpec struct {
 
 	// Whether or not to bypass the generation of GitOps resources for the Component. Defaults to false.
 	SkipGitOpsResourceGeneration bool `json:"skipGitOpsResourceGeneration,omitempty"`
+
+	// BuildPipeline overrides the build pipeline selected for the Component from its devfile.
+	// +optional
+	BuildPipeline *BuildPipelineOverride `json:"buildPipeline,omitempty"`
+
+	// ImageTagging configures the tags of the images built for the Component.
+	// Defaults to the image tagging of the Application, or to the revision strategy.
+	// +optional
+	ImageTagging *ImageTagging `json:"imageTagging,omitempty"`
+
+	// BuildCredentials configures the credentials the builds of the Component run with.
+	// Each of its fields defaults to the build credentials of the Application.
+	// +optional
+	BuildCredentials *BuildCredentials `json:"buildCredentials,omitempty"`
+}
+
+// BuildCredentials configures the credentials the builds of a Component run with
+type BuildCredentials struct {
+	// RegistrySecrets are the names of the docker-config Secrets of the namespace holding the credentials of the image
+	// registries, bound to the registry-auth workspace of the build pipelines. Several Secrets are merged into the
+	// <component>-registry-auth Secret. Defaults to the redhat-appstudio-registry-pull-secret Secret, if it exists.
+	// +optional
+	RegistrySecrets []string `json:"registrySecrets,omitempty"`
+
+	// ServiceAccountName is the ServiceAccount running the build PipelineRuns and the build EventListener.
+	// Defaults to pipeline.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// ImageTagStrategy is the way the tag of an image built for a Component is derived from the built source.
+// The tag is appended to the tag of the Component's container image, if it has one.
+// +kubebuilder:validation:Enum=revision;short-revision;branch;git-tag;date
+type ImageTagStrategy string
+
+const (
+	// RevisionImageTagStrategy tags the images with the built commit, prefixed with latest- if the container image has no tag
+	RevisionImageTagStrategy ImageTagStrategy = "revision"
+	// ShortRevisionImageTagStrategy tags the images with the first 7 characters of the built commit
+	ShortRevisionImageTagStrategy ImageTagStrategy = "short-revision"
+	// BranchImageTagStrategy tags the images with the built branch, with slashes replaced by dashes
+	BranchImageTagStrategy ImageTagStrategy = "branch"
+	// GitTagImageTagStrategy builds the pushed semantic version git tags, e.g. v1.2.3, and tags the images with
+	// the version without the v prefix
+	GitTagImageTagStrategy ImageTagStrategy = "git-tag"
+	// DateImageTagStrategy tags the images with the date of the built commit followed by its short revision, e.g. 20220601-abc1234
+	DateImageTagStrategy ImageTagStrategy = "date"
+)
+
+// ImageTagging configures the tags of the images built for a Component
+type ImageTagging struct {
+	// Strategy is the way the tag of the images is derived from the built source. Defaults to revision.
+	// +optional
+	Strategy ImageTagStrategy `json:"strategy,omitempty"`
+
+	// AdditionalTags are tags also pushed for every build of the Component's source, e.g. latest.
+	// They are passed to the build pipeline in the additional-tags parameter.
+	// +optional
+	AdditionalTags []ImageTag `json:"additionalTags,omitempty"`
+}
+
+// ImageTag is a container image tag
+// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`
+type ImageTag string
+
+// BuildPipelineOverride selects the build pipeline of a Component
+type BuildPipelineOverride struct {
+	// Name is the name of the pipeline in the build bundle. Defaults to the pipeline selected from the Component's devfile.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Bundle pins the Tekton bundle holding the build pipelines of the Component, instead of the bundle configured in the
+	// build-pipelines-defaults ConfigMap
+	// +optional
+	Bundle string `json:"bundle,omitempty"`
+
+	// Params are additional parameters passed to the pipeline. They take precedence over the parameters
+	// derived from the Component.
+	// +optional
+	Params []BuildPipelineParam `json:"params,omitempty"`
+}
+
+// BuildPipelineParam is a parameter passed to a build pipeline
+type BuildPipelineParam struct {
+	// Name is the name of the pipeline parameter
+	Name string `json:"name"`
+
+	// Value is the value of the pipeline parameter
+	Value string `json:"value"`
 }
 
 // ComponentStatus defines the observed state of Component
@@ -118,14 +222,109 @@ type ComponentStatus struct {
 	// Webhook URL generated by Builds
 	Webhook string `json:"webhook,omitempty"`
 
+	// WebhookSecret references the Secret holding the secret to configure on the git repository webhook,
+	// so that the build webhook, or Pipelines as Code, accepts its events
+	WebhookSecret *SecretKeyReference `json:"webhookSecret,omitempty"`
+
 	// ContainerImage stores the associated built container image for the component
 	ContainerImage string `json:"containerImage,omitempty"`
 
+	// ImageRepository is the image repository provisioned for the Component in the configured image registry,
+	// when the Component does not specify its container image
+	ImageRepository *ImageRepositoryStatus `json:"imageRepository,omitempty"`
+
 	// The devfile model for the Component CR
 	Devfile string `json:"devfile,omitempty"`
 
 	// GitOps specific status for the Component CR
 	GitOps GitOpsStatus `json:"gitops,omitempty"`
+
+	// BuildPipeline is the build pipeline selected for the Component
+	BuildPipeline BuildPipelineStatus `json:"buildPipeline,omitempty"`
+
+	// Build is the status of the builds submitted for the Component
+	Build BuildStatus `json:"build,omitempty"`
+}
+
+// ImageRepositoryStatus describes the image repository provisioned for a Component
+type ImageRepositoryStatus struct {
+	// URL is the URL of the image repository the Component's images are pushed to
+	URL string `json:"url"`
+
+	// PushSecret is the name of the Secret holding the credentials only allowed to push to the image repository
+	PushSecret string `json:"pushSecret,omitempty"`
+}
+
+// BuildStatus describes the builds submitted for a Component
+type BuildStatus struct {
+	// PipelineRun is the name of the last build PipelineRun submitted for the Component,
+	// once its GitOps resources were generated or on a rebuild request
+	PipelineRun string `json:"pipelineRun,omitempty"`
+
+	// LastBuild is the last build PipelineRun of the Component, submitted by HAS or triggered by the Component's source
+	LastBuild *BuildRunStatus `json:"lastBuild,omitempty"`
+
+	// OnboardingPullRequest is the pull request adding the Pipelines as Code PipelineRuns to the Component's source repository.
+	// It is only set when the Component is built with Pipelines as Code.
+	OnboardingPullRequest GitOpsPullRequestStatus `json:"onboardingPullRequest,omitempty"`
+}
+
+// BuildRunStatus describes a build PipelineRun of a Component
+type BuildRunStatus struct {
+	// Name is the name of the PipelineRun
+	Name string `json:"name"`
+
+	// Status is the status of the PipelineRun: Pending, Running, Succeeded or Failed
+	Status string `json:"status,omitempty"`
+
+	// StartTime is the time the PipelineRun started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the PipelineRun completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Revision is the git revision built by the PipelineRun
+	Revision string `json:"revision,omitempty"`
+
+	// ImageDigest is the digest of the image built by the PipelineRun
+	ImageDigest string `json:"imageDigest,omitempty"`
+}
+
+// BuildPipelineStatus describes the build pipeline selected for a Component
+type BuildPipelineStatus struct {
+	// Name is the name of the pipeline in the build bundle
+	Name string `json:"name,omitempty"`
+
+	// Reason explains why the pipeline was selected
+	Reason string `json:"reason,omitempty"`
+
+	// Bundle is the Tekton bundle holding the pipeline, pinned by the Component or resolved from the
+	// build-pipelines-defaults ConfigMap
+	Bundle string `json:"bundle,omitempty"`
+
+	// BundleDigest is the digest of the bundle when the build resources were generated, if it could be resolved
+	BundleDigest string `json:"bundleDigest,omitempty"`
+
+	// ImageTagging is the tagging of the images the build resources were generated with: the Component's own image
+	// tagging, or the default one of its Application
+	ImageTagging *ImageTagging `json:"imageTagging,omitempty"`
+
+	// Workflow is the build workflow applying to the Component: HACBS, built with Pipelines as Code,
+	// or AppStudio
+	Workflow string `json:"workflow,omitempty"`
+
+	// WorkflowReason explains how the workflow was detected: the workspace binds or exports the HACBS APIs,
+	// the namespace holds the hacbs ConfigMap, or the default workflow applies
+	WorkflowReason string `json:"workflowReason,omitempty"`
+}
+
+// SecretKeyReference references a key of a Secret in the namespace of the Component
+type SecretKeyReference struct {
+	// Name is the name of the Secret
+	Name string `json:"name"`
+
+	// Key is the key of the Secret data
+	Key string `json:"key"`
 }
 
 // GitOpsStatus contains GitOps repository-specific status for the component
@@ -144,6 +343,29 @@ type GitOpsStatus struct {
 
 	// CommitID is the most recent commit ID in the GitOps repository for this component
 	CommitID string `json:"commitID,omitempty"`
+
+	// PreviewConfigMap is the name of the ConfigMap holding the rendered GitOps resources and their diff against the GitOps repository.
+	// It is only set while the component is in GitOps dry-run mode.
+	PreviewConfigMap string `json:"previewConfigMap,omitempty"`
+
+	// PullRequest is the pull request the GitOps changes were delivered through.
+	// It is only set when the Application delivers its GitOps changes through pull requests.
+	PullRequest GitOpsPullRequestStatus `json:"pullRequest,omitempty"`
+}
+
+// GitOpsPullRequestStatus describes a pull request opened by HAS, against the GitOps repository or the source repository
+type GitOpsPullRequestStatus struct {
+	// URL is the web URL of the pull request
+	URL string `json:"url,omitempty"`
+
+	// Number is the number of the pull request in the repository
+	Number int `json:"number,omitempty"`
+
+	// Branch is the branch the changes are pushed to before being merged
+	Branch string `json:"branch,omitempty"`
+
+	// State is the state of the pull request: open, closed or merged
+	State string `json:"state,omitempty"`
 }
 
 //+kubebuilder:object:root=true
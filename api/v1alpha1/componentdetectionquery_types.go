@@ -31,6 +31,22 @@ type ComponentDetectionQuerySpec struct {
 
 	// Secret describes the name of a Kubernetes secret containing a Personal Access Token to access the git repostiory
 	Secret string `json:"secret,omitempty"`
+
+	// ScanDepth is the maximum depth of the directories of a multi-component repository scanned for components,
+	// the first-level directories being at depth 1. Defaults to 3.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ScanDepth int `json:"scanDepth,omitempty"`
+
+	// IncludePaths are globs of the contexts scanned for components, e.g. services/*. All the contexts are scanned if empty.
+	// +optional
+	IncludePaths []string `json:"includePaths,omitempty"`
+
+	// ExcludePaths are globs of the directories skipped by the scan with their subdirectories, e.g. docs or examples/**.
+	// The hidden, vendor, node_modules, test, tests and testdata directories, and the paths listed in the .cdqignore file
+	// at the root of the repository, are always skipped.
+	// +optional
+	ExcludePaths []string `json:"excludePaths,omitempty"`
 }
 
 // ComponentDetectionDescription holds all the information about the component being detected
@@ -42,11 +58,65 @@ type ComponentDetectionDescription struct {
 	// Language specifies the language of the component detected
 	Language string `json:"language,omitempty"`
 
-	// ProjectType specifies the type of project for the component detected
+	// ProjectType specifies the type of project for the component detected.
+	// Compose, Kubernetes and Helm for the components detected from a compose service, Kubernetes manifests and a Helm chart
 	ProjectType string `json:"projectType,omitempty"`
 
 	// ComponentStub is a stub of the component detected with all the info gathered from the devfile or service detection
 	ComponentStub ComponentSpec `json:"componentStub,omitempty"`
+
+	// DockerfileCandidates lists the Dockerfiles found in the context of the component, relative to the context, in order of preference.
+	// The first one is set in the component stub
+	DockerfileCandidates []string `json:"dockerfileCandidates,omitempty"`
+
+	// Languages are the languages of the component analyzed in its source, by decreasing usage
+	Languages []DetectedLanguage `json:"languages,omitempty"`
+
+	// DevfileCandidates are the devfile stacks of the devfile registry matching the languages of the component, by decreasing confidence.
+	// Another stack than the one of the component stub can be selected by setting its devfile URL in the git source of the Component
+	DevfileCandidates []DevfileCandidate `json:"devfileCandidates,omitempty"`
+}
+
+// DetectedLanguage is a language of a detected component
+type DetectedLanguage struct {
+	// Name is the name of the language
+	Name string `json:"name"`
+
+	// UsagePercentage is the rounded percentage of the source of the component in the language
+	UsagePercentage int `json:"usagePercentage,omitempty"`
+
+	// Frameworks are the frameworks of the component detected for the language
+	Frameworks []string `json:"frameworks,omitempty"`
+
+	// Tools are the tools of the component detected for the language
+	Tools []string `json:"tools,omitempty"`
+}
+
+// DevfileCandidate is a devfile stack of the devfile registry matching the languages of a detected component
+type DevfileCandidate struct {
+	// Name is the name of the devfile stack in the registry
+	Name string `json:"name"`
+
+	// DevfileURL is the URL of the devfile of the stack in the registry
+	DevfileURL string `json:"devfileUrl,omitempty"`
+
+	// Language is the language of the stack
+	Language string `json:"language,omitempty"`
+
+	// ProjectType is the project type of the stack
+	ProjectType string `json:"projectType,omitempty"`
+
+	// Confidence is the share, in percent, of the match score of the stack among the devfile candidates of the component
+	Confidence int `json:"confidence"`
+
+	// Selected is whether the devfile of the stack is the devfile of the component stub
+	Selected bool `json:"selected,omitempty"`
+
+	// Frameworks are the frameworks of the component matched by the project type or the tags of the stack
+	Frameworks []string `json:"frameworks,omitempty"`
+
+	// Tools are the tools of the component matched by the tags of the stack
+	Tools []string `json:"tools,omitempty"`
 }
 
 // ComponentDetectionMap is a map containing all the components and their detected information
@@ -32,7 +32,7 @@ func (in *Application) DeepCopyInto(out *Application) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -106,6 +106,17 @@ func (in *ApplicationSpec) DeepCopyInto(out *ApplicationSpec) {
 	*out = *in
 	out.AppModelRepository = in.AppModelRepository
 	out.GitOpsRepository = in.GitOpsRepository
+	out.GitOpsCommit = in.GitOpsCommit
+	if in.ImageTagging != nil {
+		in, out := &in.ImageTagging, &out.ImageTagging
+		*out = new(ImageTagging)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BuildCredentials != nil {
+		in, out := &in.BuildCredentials, &out.BuildCredentials
+		*out = new(BuildCredentials)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationSpec.
@@ -140,6 +151,125 @@ func (in *ApplicationStatus) DeepCopy() *ApplicationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildCredentials) DeepCopyInto(out *BuildCredentials) {
+	*out = *in
+	if in.RegistrySecrets != nil {
+		in, out := &in.RegistrySecrets, &out.RegistrySecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildCredentials.
+func (in *BuildCredentials) DeepCopy() *BuildCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildPipelineOverride) DeepCopyInto(out *BuildPipelineOverride) {
+	*out = *in
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make([]BuildPipelineParam, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildPipelineOverride.
+func (in *BuildPipelineOverride) DeepCopy() *BuildPipelineOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildPipelineOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildPipelineParam) DeepCopyInto(out *BuildPipelineParam) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildPipelineParam.
+func (in *BuildPipelineParam) DeepCopy() *BuildPipelineParam {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildPipelineParam)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildPipelineStatus) DeepCopyInto(out *BuildPipelineStatus) {
+	*out = *in
+	if in.ImageTagging != nil {
+		in, out := &in.ImageTagging, &out.ImageTagging
+		*out = new(ImageTagging)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildPipelineStatus.
+func (in *BuildPipelineStatus) DeepCopy() *BuildPipelineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildPipelineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildRunStatus) DeepCopyInto(out *BuildRunStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildRunStatus.
+func (in *BuildRunStatus) DeepCopy() *BuildRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildStatus) DeepCopyInto(out *BuildStatus) {
+	*out = *in
+	if in.LastBuild != nil {
+		in, out := &in.LastBuild, &out.LastBuild
+		*out = new(BuildRunStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	out.OnboardingPullRequest = in.OnboardingPullRequest
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildStatus.
+func (in *BuildStatus) DeepCopy() *BuildStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Component) DeepCopyInto(out *Component) {
 	*out = *in
@@ -171,6 +301,25 @@ func (in *Component) DeepCopyObject() runtime.Object {
 func (in *ComponentDetectionDescription) DeepCopyInto(out *ComponentDetectionDescription) {
 	*out = *in
 	in.ComponentStub.DeepCopyInto(&out.ComponentStub)
+	if in.DockerfileCandidates != nil {
+		in, out := &in.DockerfileCandidates, &out.DockerfileCandidates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Languages != nil {
+		in, out := &in.Languages, &out.Languages
+		*out = make([]DetectedLanguage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DevfileCandidates != nil {
+		in, out := &in.DevfileCandidates, &out.DevfileCandidates
+		*out = make([]DevfileCandidate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentDetectionDescription.
@@ -209,7 +358,7 @@ func (in *ComponentDetectionQuery) DeepCopyInto(out *ComponentDetectionQuery) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -266,7 +415,17 @@ func (in *ComponentDetectionQueryList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComponentDetectionQuerySpec) DeepCopyInto(out *ComponentDetectionQuerySpec) {
 	*out = *in
-	out.GitSource = in.GitSource
+	in.GitSource.DeepCopyInto(&out.GitSource)
+	if in.IncludePaths != nil {
+		in, out := &in.IncludePaths, &out.IncludePaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludePaths != nil {
+		in, out := &in.ExcludePaths, &out.ExcludePaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentDetectionQuerySpec.
@@ -362,7 +521,7 @@ func (in *ComponentSourceUnion) DeepCopyInto(out *ComponentSourceUnion) {
 	if in.GitSource != nil {
 		in, out := &in.GitSource, &out.GitSource
 		*out = new(GitSource)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -388,6 +547,21 @@ func (in *ComponentSpec) DeepCopyInto(out *ComponentSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.BuildPipeline != nil {
+		in, out := &in.BuildPipeline, &out.BuildPipeline
+		*out = new(BuildPipelineOverride)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageTagging != nil {
+		in, out := &in.ImageTagging, &out.ImageTagging
+		*out = new(ImageTagging)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BuildCredentials != nil {
+		in, out := &in.BuildCredentials, &out.BuildCredentials
+		*out = new(BuildCredentials)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentSpec.
@@ -410,7 +584,19 @@ func (in *ComponentStatus) DeepCopyInto(out *ComponentStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.WebhookSecret != nil {
+		in, out := &in.WebhookSecret, &out.WebhookSecret
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.ImageRepository != nil {
+		in, out := &in.ImageRepository, &out.ImageRepository
+		*out = new(ImageRepositoryStatus)
+		**out = **in
+	}
 	out.GitOps = in.GitOps
+	in.BuildPipeline.DeepCopyInto(&out.BuildPipeline)
+	in.Build.DeepCopyInto(&out.Build)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentStatus.
@@ -423,9 +609,90 @@ func (in *ComponentStatus) DeepCopy() *ComponentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DetectedLanguage) DeepCopyInto(out *DetectedLanguage) {
+	*out = *in
+	if in.Frameworks != nil {
+		in, out := &in.Frameworks, &out.Frameworks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tools != nil {
+		in, out := &in.Tools, &out.Tools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DetectedLanguage.
+func (in *DetectedLanguage) DeepCopy() *DetectedLanguage {
+	if in == nil {
+		return nil
+	}
+	out := new(DetectedLanguage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevfileCandidate) DeepCopyInto(out *DevfileCandidate) {
+	*out = *in
+	if in.Frameworks != nil {
+		in, out := &in.Frameworks, &out.Frameworks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tools != nil {
+		in, out := &in.Tools, &out.Tools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevfileCandidate.
+func (in *DevfileCandidate) DeepCopy() *DevfileCandidate {
+	if in == nil {
+		return nil
+	}
+	out := new(DevfileCandidate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsCommitConfiguration) DeepCopyInto(out *GitOpsCommitConfiguration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsCommitConfiguration.
+func (in *GitOpsCommitConfiguration) DeepCopy() *GitOpsCommitConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsCommitConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsPullRequestStatus) DeepCopyInto(out *GitOpsPullRequestStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsPullRequestStatus.
+func (in *GitOpsPullRequestStatus) DeepCopy() *GitOpsPullRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsPullRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitOpsStatus) DeepCopyInto(out *GitOpsStatus) {
 	*out = *in
+	out.PullRequest = in.PullRequest
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsStatus.
@@ -441,6 +708,11 @@ func (in *GitOpsStatus) DeepCopy() *GitOpsStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitSource) DeepCopyInto(out *GitSource) {
 	*out = *in
+	if in.BuildTriggerPaths != nil {
+		in, out := &in.BuildTriggerPaths, &out.BuildTriggerPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitSource.
@@ -452,3 +724,53 @@ func (in *GitSource) DeepCopy() *GitSource {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageRepositoryStatus) DeepCopyInto(out *ImageRepositoryStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageRepositoryStatus.
+func (in *ImageRepositoryStatus) DeepCopy() *ImageRepositoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageRepositoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageTagging) DeepCopyInto(out *ImageTagging) {
+	*out = *in
+	if in.AdditionalTags != nil {
+		in, out := &in.AdditionalTags, &out.AdditionalTags
+		*out = make([]ImageTag, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageTagging.
+func (in *ImageTagging) DeepCopy() *ImageTagging {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageTagging)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 
@@ -26,9 +27,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/go-logr/logr"
+	gh "github.com/google/go-github/v41/github"
 	kcpclient "github.com/kcp-dev/apimachinery/pkg/client"
 	"github.com/kcp-dev/logicalcluster"
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
 	"github.com/redhat-appstudio/application-service/pkg/util"
 	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
 	appstudioshared "github.com/redhat-appstudio/managed-gitops/appstudio-shared/apis/appstudio.redhat.com/v1alpha1"
@@ -48,11 +51,12 @@ import (
 // ApplicationSnapshotEnvironmentBindingReconciler reconciles a ApplicationSnapshotEnvironmentBinding object
 type ApplicationSnapshotEnvironmentBindingReconciler struct {
 	client.Client
-	Scheme   *runtime.Scheme
-	Log      logr.Logger
-	AppFS    afero.Afero
-	Executor gitopsgen.Executor
-	GitToken string
+	Scheme       *runtime.Scheme
+	Log          logr.Logger
+	AppFS        afero.Afero
+	Executor     gitopsgen.Executor
+	GitToken     string
+	GitHubClient *gh.Client
 }
 
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applicationsnapshotenvironmentbindings,verbs=get;list;watch;create;update;patch;delete
@@ -60,6 +64,8 @@ type ApplicationSnapshotEnvironmentBindingReconciler struct {
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applicationsnapshotenvironmentbindings/finalizers,verbs=update
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applicationsnapshots,verbs=get;list;watch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=environments,verbs=get;list;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -133,8 +139,20 @@ func (r *ApplicationSnapshotEnvironmentBindingReconciler) Reconcile(ctx context.
 		return ctrl.Result{}, err
 	}
 
+	// The GitOps changes are delivered through a pull request if either the Environment or the Application asks for it
+	application := appstudiov1alpha1.Application{}
+	err = r.Get(ctx, types.NamespacedName{Name: applicationName, Namespace: appSnapshotEnvBinding.Namespace}, &application)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, fmt.Sprintf("unable to get the Application %s %v", applicationName, req.NamespacedName))
+		r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+		return ctrl.Result{}, err
+	}
+	pullRequestDelivery := appservicegitops.IsPullRequestDelivery(&environment) || appservicegitops.IsPullRequestDelivery(&application)
+
 	componentGeneratedResources := make(map[string][]string)
 	var tempDir string
+	var gitOpsRemoteURL, gitOpsBranch, gitOpsRepositoryURL string
+	var executor *appservicegitops.CommitExecutor
 	clone := true
 
 	for _, component := range components {
@@ -177,7 +195,9 @@ func (r *ApplicationSnapshotEnvironmentBindingReconciler) Reconcile(ctx context.
 			return ctrl.Result{}, err
 		}
 
-		gitOpsRemoteURL, gitOpsBranch, gitOpsContext, err := util.ProcessGitOpsStatus(hasComponent.Status.GitOps, r.GitToken)
+		var gitOpsContext string
+		gitOpsRemoteURL, gitOpsBranch, gitOpsContext, err = util.ProcessGitOpsStatus(hasComponent.Status.GitOps, r.GitToken)
+		gitOpsRepositoryURL = hasComponent.Status.GitOps.RepositoryURL
 		if err != nil {
 			r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
 			return ctrl.Result{}, err
@@ -199,7 +219,24 @@ func (r *ApplicationSnapshotEnvironmentBindingReconciler) Reconcile(ctx context.
 				r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
 				return ctrl.Result{}, fmt.Errorf("unable to create temp directory for gitops resources due to error: %v", err)
 			}
+
+			// Apply the application's commit configuration to the commits pushed to the gitops repository
+			keyDir := filepath.Join(tempDir, signingKeyDirName)
+			// The signing key must not outlive the commits, even if the temp folder does
+			defer func() { _ = r.AppFS.RemoveAll(keyDir) }()
+			executor, err = newGitOpsCommitExecutor(ctx, r.Client, r.Executor, r.AppFS, &application, keyDir, appservicegitops.CommitMessageData{
+				Application: applicationName,
+				Namespace:   appSnapshotEnvBinding.Namespace,
+				Resource:    "ApplicationSnapshotEnvironmentBinding/" + appSnapshotEnvBinding.Name,
+			})
+			if err != nil {
+				log.Error(err, "unable to configure the gitops commits due to error")
+				_ = r.AppFS.RemoveAll(tempDir)
+				r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, err)
+				return ctrl.Result{}, err
+			}
 		}
+		executor.MessageData.Component = componentName
 
 		envVars := make([]corev1.EnvVar, 0)
 		for _, env := range component.Configuration.Env {
@@ -230,7 +267,7 @@ func (r *ApplicationSnapshotEnvironmentBindingReconciler) Reconcile(ctx context.
 				},
 			},
 		}
-		err = gitopsgen.GenerateOverlaysAndPush(tempDir, clone, gitOpsRemoteURL, gitopsgenBinding, gitopsgenEnv, applicationName, environmentName, imageName, appSnapshotEnvBinding.Namespace, r.Executor, r.AppFS, gitOpsBranch, gitOpsContext, true, componentGeneratedResources)
+		err = gitopsgen.GenerateOverlaysAndPush(tempDir, clone, gitOpsRemoteURL, gitopsgenBinding, gitopsgenEnv, applicationName, environmentName, imageName, appSnapshotEnvBinding.Namespace, executor, r.AppFS, gitOpsBranch, gitOpsContext, !pullRequestDelivery, componentGeneratedResources)
 		if err != nil {
 			gitOpsErr := util.SanitizeErrorMessage(err)
 			log.Error(gitOpsErr, fmt.Sprintf("unable to get generate gitops resources for %s %v", componentName, req.NamespacedName))
@@ -239,14 +276,17 @@ func (r *ApplicationSnapshotEnvironmentBindingReconciler) Reconcile(ctx context.
 			return ctrl.Result{}, gitOpsErr
 		}
 
-		// Retrieve the commit ID
+		// Retrieve the commit ID. In pull request mode, the overlays are only committed to the gitops branch once the pull
+		// request is merged, so the commit ID is recorded when the merge is observed.
 		var commitID string
-		repoPath := filepath.Join(tempDir, applicationName)
-		if commitID, err = gitopsgen.GetCommitIDFromRepo(r.AppFS, r.Executor, repoPath); err != nil {
-			gitOpsErr := util.SanitizeErrorMessage(err)
-			log.Error(gitOpsErr, "unable to retrieve gitops repository commit id due to error")
-			r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, gitOpsErr)
-			return ctrl.Result{}, gitOpsErr
+		if !pullRequestDelivery {
+			repoPath := filepath.Join(tempDir, applicationName)
+			if commitID, err = gitopsgen.GetCommitIDFromRepo(r.AppFS, executor, repoPath); err != nil {
+				gitOpsErr := util.SanitizeErrorMessage(err)
+				log.Error(gitOpsErr, "unable to retrieve gitops repository commit id due to error")
+				r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, gitOpsErr)
+				return ctrl.Result{}, gitOpsErr
+			}
 		}
 
 		if !isStatusUpdated {
@@ -271,6 +311,42 @@ func (r *ApplicationSnapshotEnvironmentBindingReconciler) Reconcile(ctx context.
 		clone = false
 	}
 
+	// In pull request mode, the overlays of all the components are pushed together to the pull request branch
+	pullRequest := getBindingPullRequest(appSnapshotEnvBinding)
+	if pullRequestDelivery && !clone {
+		// Record the merge of the pull request delivered by an earlier reconcile as the commit of the components
+		if pullRequest.State == "open" {
+			state, mergeCommitID, err := getGitOpsPullRequestState(ctx, r.GitHubClient, gitOpsRepositoryURL, pullRequest.Number)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Unable to refresh the state of the gitops pull request %s", pullRequest.URL))
+			} else {
+				pullRequest.State = state
+				if mergeCommitID != "" {
+					for i := range appSnapshotEnvBinding.Status.Components {
+						appSnapshotEnvBinding.Status.Components[i].GitOpsRepository.CommitID = mergeCommitID
+					}
+				}
+			}
+		}
+
+		// The pull request gathers the changes of all the components
+		executor.MessageData.Component = ""
+		deliveredPullRequest, err := r.deliverGitopsPullRequest(ctx, executor, tempDir, gitOpsRemoteURL, gitOpsRepositoryURL, gitOpsBranch, applicationName, environmentName)
+		if err != nil {
+			gitOpsErr := util.SanitizeErrorMessage(err)
+			log.Error(gitOpsErr, fmt.Sprintf("unable to deliver the gitops resources through a pull request %v", req.NamespacedName))
+			_ = r.AppFS.RemoveAll(tempDir)
+			r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, gitOpsErr)
+			return ctrl.Result{}, gitOpsErr
+		}
+		if deliveredPullRequest.URL != "" {
+			pullRequest = deliveredPullRequest
+		}
+		if pullRequest.URL != "" {
+			r.SetPullRequestCondition(&appSnapshotEnvBinding, pullRequest)
+		}
+	}
+
 	// Remove the cloned path
 	err = r.AppFS.RemoveAll(tempDir)
 	if err != nil {
@@ -285,12 +361,64 @@ func (r *ApplicationSnapshotEnvironmentBindingReconciler) Reconcile(ctx context.
 		return ctrl.Result{}, err
 	}
 
+	// The binding's status is shared with the GitOps service, so the pull request is kept in an annotation.
+	// The annotation is patched after the status update, as the patch reads back the stored status.
+	if pullRequest != getBindingPullRequest(appSnapshotEnvBinding) {
+		if err := r.setBindingPullRequest(ctx, &appSnapshotEnvBinding, pullRequest); err != nil {
+			log.Error(err, "Unable to record the gitops pull request of the App Snapshot Env Binding")
+			return ctrl.Result{}, err
+		}
+	}
+
 	r.SetConditionAndUpdateCR(ctx, req, &appSnapshotEnvBinding, nil)
 
 	log.Info(fmt.Sprintf("Finished reconcile loop for %v", req.NamespacedName))
+	if pullRequest.State == "open" {
+		// Keep track of the gitops pull request until it is merged or closed
+		return ctrl.Result{RequeueAfter: pullRequestResyncPeriod}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// getBindingPullRequest returns the gitops pull request recorded in the binding's annotations, empty if there is none
+func getBindingPullRequest(appSnapshotEnvBinding appstudioshared.ApplicationSnapshotEnvironmentBinding) appstudiov1alpha1.GitOpsPullRequestStatus {
+	var pullRequest appstudiov1alpha1.GitOpsPullRequestStatus
+	if value := appSnapshotEnvBinding.GetAnnotations()[bindingPullRequestAnnotation]; value != "" {
+		_ = json.Unmarshal([]byte(value), &pullRequest) // a malformed annotation is replaced with the next pull request
+	}
+	return pullRequest
+}
+
+// setBindingPullRequest records the gitops pull request in the binding's annotations
+func (r *ApplicationSnapshotEnvironmentBindingReconciler) setBindingPullRequest(ctx context.Context, appSnapshotEnvBinding *appstudioshared.ApplicationSnapshotEnvironmentBinding, pullRequest appstudiov1alpha1.GitOpsPullRequestStatus) error {
+	value, err := json.Marshal(pullRequest)
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(appSnapshotEnvBinding.DeepCopy())
+	annotations := appSnapshotEnvBinding.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[bindingPullRequestAnnotation] = string(value)
+	appSnapshotEnvBinding.SetAnnotations(annotations)
+	return r.Client.Patch(ctx, appSnapshotEnvBinding, patch)
+}
+
+// deliverGitopsPullRequest pushes the environment overlays cloned in tempDir to the binding's pull request branch and opens,
+// or updates, the pull request against the gitops branch. An empty status is returned if there were no changes to deliver.
+func (r *ApplicationSnapshotEnvironmentBindingReconciler) deliverGitopsPullRequest(ctx context.Context, executor gitopsgen.Executor, tempDir string, gitOpsRemoteURL string, gitOpsRepositoryURL string, gitOpsBranch string, applicationName string, environmentName string) (appstudiov1alpha1.GitOpsPullRequestStatus, error) {
+	prBranch := appservicegitops.GetPullRequestBranch(applicationName + "-" + environmentName)
+	commitMessage := fmt.Sprintf("Generate %s environment overlays for application %s", environmentName, applicationName)
+	pushed, err := appservicegitops.CommitAndPushToPullRequestBranch(tempDir, applicationName, gitOpsRemoteURL, applicationName, executor, prBranch, commitMessage)
+	if err != nil || !pushed {
+		return appstudiov1alpha1.GitOpsPullRequestStatus{}, err
+	}
+
+	title := fmt.Sprintf("Update %s environment overlays for application %s", environmentName, applicationName)
+	return openGitOpsPullRequest(ctx, r.GitHubClient, gitOpsRepositoryURL, prBranch, gitOpsBranch, title)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ApplicationSnapshotEnvironmentBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
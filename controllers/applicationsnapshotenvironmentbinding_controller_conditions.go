@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
 	appstudioshared "github.com/redhat-appstudio/managed-gitops/appstudio-shared/apis/appstudio.redhat.com/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -49,3 +50,14 @@ func (r *ApplicationSnapshotEnvironmentBindingReconciler) SetConditionAndUpdateC
 		log.Error(err, "Unable to update application snapshot environment binding")
 	}
 }
+
+// SetPullRequestCondition records the pull request the GitOps changes of the binding were delivered through.
+// The condition is persisted with the next status update of the binding.
+func (r *ApplicationSnapshotEnvironmentBindingReconciler) SetPullRequestCondition(appSnapshotEnvBinding *appstudioshared.ApplicationSnapshotEnvironmentBinding, pullRequest appstudiov1alpha1.GitOpsPullRequestStatus) {
+	meta.SetStatusCondition(&appSnapshotEnvBinding.Status.GitOpsRepoConditions, metav1.Condition{
+		Type:    "GitOpsPullRequestOpened",
+		Status:  metav1.ConditionTrue,
+		Reason:  "OK",
+		Message: fmt.Sprintf("GitOps changes delivered through pull request %s from branch %s, pull request state: %s", pullRequest.URL, pullRequest.Branch, pullRequest.State),
+	})
+}
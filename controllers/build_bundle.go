@@ -0,0 +1,110 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+)
+
+// isBuildBundleChanged returns true if the build resources of the component were generated with another build bundle
+// than the one it now resolves. Components whose build resources were not generated yet are not considered changed.
+func (r *ComponentReconciler) isBuildBundleChanged(ctx context.Context, component appstudiov1alpha1.Component) bool {
+	if component.Spec.Source.GitSource == nil || component.Spec.Source.GitSource.URL == "" || component.Status.BuildPipeline.Bundle == "" {
+		return false
+	}
+	return prepare.ResolveComponentBuildBundle(ctx, r.Client, r.HACBSDetector, component) != component.Status.BuildPipeline.Bundle
+}
+
+const (
+	// bundleDigestTimeout bounds the resolution of the digest of a build bundle, so that an unresponsive registry does
+	// not hold the generation of the build resources
+	bundleDigestTimeout = 10 * time.Second
+	// bundleDigestCacheTTL is how long the resolved digest of a build bundle is reused, as its tag can be moved
+	bundleDigestCacheTTL = 5 * time.Minute
+)
+
+// bundleDigestCache holds the digests of the build bundles resolved in the last bundleDigestCacheTTL
+type bundleDigestCache struct {
+	mutex   sync.Mutex
+	digests map[string]resolvedBundleDigest
+}
+
+type resolvedBundleDigest struct {
+	digest     string
+	resolvedAt time.Time
+}
+
+// get returns the digest of the bundle if it was resolved in the last bundleDigestCacheTTL
+func (c *bundleDigestCache) get(bundle string, now time.Time) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	resolved, ok := c.digests[bundle]
+	if !ok || now.Sub(resolved.resolvedAt) > bundleDigestCacheTTL {
+		return "", false
+	}
+	return resolved.digest, true
+}
+
+// set records the digest of the bundle resolved at the given time
+func (c *bundleDigestCache) set(bundle string, digest string, now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.digests == nil {
+		c.digests = make(map[string]resolvedBundleDigest)
+	}
+	c.digests[bundle] = resolvedBundleDigest{digest: digest, resolvedAt: now}
+}
+
+// getBuildBundleDigest returns the digest of the build bundle, or an empty string if it cannot be resolved.
+// The resolved digests are cached for bundleDigestCacheTTL, the failed resolutions are retried on the next call.
+func (r *ComponentReconciler) getBuildBundleDigest(ctx context.Context, bundle string) string {
+	if r.DigestResolver == nil {
+		if index := strings.Index(bundle, "@"); index >= 0 {
+			return bundle[index+1:]
+		}
+		return ""
+	}
+	if digest, ok := r.bundleDigests.get(bundle, time.Now()); ok {
+		return digest
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bundleDigestTimeout)
+	defer cancel()
+	digest, err := r.DigestResolver.ResolveDigest(ctx, bundle)
+	if err != nil {
+		// The digest is informative, the build resources are generated anyway
+		r.Log.Info(fmt.Sprintf("Unable to resolve the digest of the build bundle %s: %v", bundle, err))
+		return ""
+	}
+	r.bundleDigests.set(bundle, digest, time.Now())
+	return digest
+}
+
+// getBuildWorkflow returns the build workflow applying to the component and the reason it was detected
+func getBuildWorkflow(gitopsConfig prepare.GitopsConfig) (string, string) {
+	if gitopsConfig.IsHACBS {
+		return prepare.HACBSWorkflow, gitopsConfig.HACBSReason
+	}
+	return prepare.AppStudioWorkflow, prepare.DefaultWorkflowReason
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// builtConditionType is the type of the Component condition reflecting its last build
+	builtConditionType = "Built"
+
+	// pipelineRunSucceededCondition is the type of the PipelineRun condition reflecting its completion
+	pipelineRunSucceededCondition = "Succeeded"
+	// imageDigestResult is the result of the build pipelines holding the digest of the built image
+	imageDigestResult = "IMAGE_DIGEST"
+	// gitCommitResult is the result of the build pipelines holding the built git commit
+	gitCommitResult = "CHAINS-GIT_COMMIT"
+	// pacEventTypeLabel is the label of the PipelineRuns created by Pipelines as Code holding the type of the triggering event
+	pacEventTypeLabel = "pipelinesascode.tekton.dev/event-type"
+
+	buildPending   = "Pending"
+	buildRunning   = "Running"
+	buildSucceeded = "Succeeded"
+	buildFailed    = "Failed"
+)
+
+// updateBuildStatus records the last build PipelineRun of the component in its status, and reflects it in the Built condition.
+// The status is only updated if the last build changed.
+func (r *ComponentReconciler) updateBuildStatus(ctx context.Context, component *appstudiov1alpha1.Component) error {
+	pipelineRuns := &tektonapi.PipelineRunList{}
+	err := r.Client.List(ctx, pipelineRuns,
+		client.InNamespace(component.Namespace),
+		client.MatchingLabels{appservicegitops.BuildComponentLabel: component.Name})
+	if err != nil {
+		return fmt.Errorf("unable to list the build pipeline runs: %v", err)
+	}
+	lastBuild := getLastBuildPipelineRun(pipelineRuns.Items)
+	if lastBuild == nil {
+		return nil
+	}
+
+	buildRunStatus := getBuildRunStatus(*lastBuild)
+	if equality.Semantic.DeepEqual(component.Status.Build.LastBuild, &buildRunStatus) {
+		return nil
+	}
+	component.Status.Build.LastBuild = &buildRunStatus
+
+	// The condition is only set once the build started
+	if condition := lastBuild.Status.GetCondition(pipelineRunSucceededCondition); condition != nil {
+		builtCondition := metav1.Condition{
+			Type:   builtConditionType,
+			Reason: buildRunStatus.Status,
+		}
+		switch condition.Status {
+		case corev1.ConditionTrue:
+			builtCondition.Status = metav1.ConditionTrue
+			builtCondition.Message = fmt.Sprintf("Build PipelineRun %s succeeded", lastBuild.Name)
+		case corev1.ConditionFalse:
+			builtCondition.Status = metav1.ConditionFalse
+			builtCondition.Message = fmt.Sprintf("Build PipelineRun %s failed: %s", lastBuild.Name, condition.Message)
+		default:
+			builtCondition.Status = metav1.ConditionUnknown
+			builtCondition.Message = fmt.Sprintf("Build PipelineRun %s is running", lastBuild.Name)
+		}
+		meta.SetStatusCondition(&component.Status.Conditions, builtCondition)
+	}
+
+	return r.Client.Status().Update(ctx, component)
+}
+
+// getLastBuildPipelineRun returns the most recently created of the build pipeline runs, or nil if there are none.
+// The pipeline runs building pull requests are ignored, as they do not build the component's revision.
+func getLastBuildPipelineRun(pipelineRuns []tektonapi.PipelineRun) *tektonapi.PipelineRun {
+	var lastBuild *tektonapi.PipelineRun
+	for i, pipelineRun := range pipelineRuns {
+		if pipelineRun.Labels[appservicegitops.BuildEventTypeLabel] == appservicegitops.BuildPullRequestEventType ||
+			pipelineRun.Labels[pacEventTypeLabel] == appservicegitops.BuildPullRequestEventType {
+			continue
+		}
+		if lastBuild == nil || lastBuild.CreationTimestamp.Before(&pipelineRun.CreationTimestamp) ||
+			(lastBuild.CreationTimestamp.Equal(&pipelineRun.CreationTimestamp) && lastBuild.Name < pipelineRun.Name) {
+			lastBuild = &pipelineRuns[i]
+		}
+	}
+	return lastBuild
+}
+
+// getBuildRunStatus returns the status of the build pipeline run to record in the component's status
+func getBuildRunStatus(pipelineRun tektonapi.PipelineRun) appstudiov1alpha1.BuildRunStatus {
+	buildRunStatus := appstudiov1alpha1.BuildRunStatus{
+		Name:           pipelineRun.Name,
+		Status:         buildPending,
+		StartTime:      pipelineRun.Status.StartTime,
+		CompletionTime: pipelineRun.Status.CompletionTime,
+	}
+	if condition := pipelineRun.Status.GetCondition(pipelineRunSucceededCondition); condition != nil {
+		switch condition.Status {
+		case corev1.ConditionTrue:
+			buildRunStatus.Status = buildSucceeded
+		case corev1.ConditionFalse:
+			buildRunStatus.Status = buildFailed
+		default:
+			buildRunStatus.Status = buildRunning
+		}
+	}
+
+	// The built commit is preferred to the revision the build was requested for, which can be a branch
+	for _, param := range pipelineRun.Spec.Params {
+		if param.Name == "revision" {
+			buildRunStatus.Revision = param.Value.StringVal
+		}
+	}
+	for _, result := range pipelineRun.Status.PipelineResults {
+		switch result.Name {
+		case imageDigestResult:
+			buildRunStatus.ImageDigest = result.Value
+		case gitCommitResult:
+			buildRunStatus.Revision = result.Value
+		}
+	}
+	return buildRunStatus
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ensureBuildWebhookSecret creates the Secret holding the secret that authenticates the events sent to the build webhook
+// of the component, and references it in the component's status. An existing secret is kept, as it is already configured
+// on the git repository webhook.
+func (r *ComponentReconciler) ensureBuildWebhookSecret(ctx context.Context, component *appstudiov1alpha1.Component) error {
+	secretName := appservicegitops.GetBuildWebhookSecretName(*component)
+	webhookSecret := &corev1.Secret{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: component.Namespace}, webhookSecret)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("unable to retrieve the build webhook secret %s: %v", secretName, err)
+		}
+
+		value := make([]byte, 20)
+		if _, err := rand.Read(value); err != nil {
+			return fmt.Errorf("unable to generate the build webhook secret: %v", err)
+		}
+		webhookSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: component.Namespace,
+			},
+			Type: corev1.SecretTypeOpaque,
+			StringData: map[string]string{
+				appservicegitops.BuildWebhookSecretKey: hex.EncodeToString(value),
+			},
+		}
+		// The secret is garbage collected together with the component
+		if err := controllerutil.SetOwnerReference(component, webhookSecret, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Client.Create(ctx, webhookSecret); err != nil {
+			return fmt.Errorf("unable to create the build webhook secret %s: %v", secretName, err)
+		}
+	}
+
+	component.Status.WebhookSecret = &appstudiov1alpha1.SecretKeyReference{
+		Name: secretName,
+		Key:  appservicegitops.BuildWebhookSecretKey,
+	}
+	return nil
+}
+
+// ensurePaCWebhookSecret adds a generated secret for the git repository of the component to the Secret holding the
+// webhook secrets of Pipelines as Code, and references it in the component's status. The Secret is shared by the
+// components of the namespace, so it is created without owner. An existing secret is kept, as it is already configured
+// on the git repository webhook.
+func (r *ComponentReconciler) ensurePaCWebhookSecret(ctx context.Context, component *appstudiov1alpha1.Component) error {
+	secretName := appservicegitops.PipelinesAsCodeWebhooksSecretName
+	secretKey := appservicegitops.GetWebhookSecretKeyForComponent(*component)
+
+	webhookSecret := &corev1.Secret{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: component.Namespace}, webhookSecret)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("unable to retrieve the Pipelines as Code webhook secret %s: %v", secretName, err)
+	}
+	secretFound := err == nil
+
+	if len(webhookSecret.Data[secretKey]) == 0 {
+		value := make([]byte, 20)
+		if _, err := rand.Read(value); err != nil {
+			return fmt.Errorf("unable to generate the Pipelines as Code webhook secret: %v", err)
+		}
+
+		if !secretFound {
+			webhookSecret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      secretName,
+					Namespace: component.Namespace,
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					secretKey: []byte(hex.EncodeToString(value)),
+				},
+			}
+			if err := r.Client.Create(ctx, webhookSecret); err != nil {
+				return fmt.Errorf("unable to create the Pipelines as Code webhook secret %s: %v", secretName, err)
+			}
+		} else {
+			if webhookSecret.Data == nil {
+				webhookSecret.Data = make(map[string][]byte)
+			}
+			webhookSecret.Data[secretKey] = []byte(hex.EncodeToString(value))
+			if err := r.Client.Update(ctx, webhookSecret); err != nil {
+				return fmt.Errorf("unable to update the Pipelines as Code webhook secret %s: %v", secretName, err)
+			}
+		}
+	}
+
+	component.Status.WebhookSecret = &appstudiov1alpha1.SecretKeyReference{
+		Name: secretName,
+		Key:  secretKey,
+	}
+	return nil
+}
+
+// removePaCWebhookSecret removes the secret of the git repository of the component from the Secret holding the webhook
+// secrets of Pipelines as Code, unless another component of the namespace is built from the same git repository.
+func (r *ComponentReconciler) removePaCWebhookSecret(ctx context.Context, component *appstudiov1alpha1.Component) error {
+	if component.Spec.Source.GitSource == nil || component.Spec.Source.GitSource.URL == "" {
+		return nil
+	}
+	secretName := appservicegitops.PipelinesAsCodeWebhooksSecretName
+	secretKey := appservicegitops.GetWebhookSecretKeyForComponent(*component)
+
+	webhookSecret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: component.Namespace}, webhookSecret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to retrieve the Pipelines as Code webhook secret %s: %v", secretName, err)
+	}
+	if _, ok := webhookSecret.Data[secretKey]; !ok {
+		return nil
+	}
+
+	components := &appstudiov1alpha1.ComponentList{}
+	if err := r.Client.List(ctx, components, client.InNamespace(component.Namespace)); err != nil {
+		return fmt.Errorf("unable to list the components of the namespace %s: %v", component.Namespace, err)
+	}
+	for _, otherComponent := range components.Items {
+		if otherComponent.Name == component.Name || otherComponent.Spec.Source.GitSource == nil {
+			continue
+		}
+		if appservicegitops.GetWebhookSecretKeyForComponent(otherComponent) == secretKey {
+			// The secret is still configured on the webhook of the git repository used by the other component
+			return nil
+		}
+	}
+
+	delete(webhookSecret.Data, secretKey)
+	if err := r.Client.Update(ctx, webhookSecret); err != nil {
+		return fmt.Errorf("unable to update the Pipelines as Code webhook secret %s: %v", secretName, err)
+	}
+	return nil
+}
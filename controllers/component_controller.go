@@ -24,6 +24,7 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -34,12 +35,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	"sigs.k8s.io/yaml"
 
 	"github.com/devfile/api/v2/pkg/attributes"
 	data "github.com/devfile/library/pkg/devfile/parser/data"
 	"github.com/go-logr/logr"
+	gh "github.com/google/go-github/v41/github"
 	kcpclient "github.com/kcp-dev/apimachinery/pkg/client"
 	"github.com/kcp-dev/logicalcluster"
 	routev1 "github.com/openshift/api/route/v1"
@@ -48,12 +52,14 @@ import (
 	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
 	"github.com/redhat-appstudio/application-service/gitops/prepare"
 	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
+	"github.com/redhat-appstudio/application-service/pkg/imageregistry"
 	"github.com/redhat-appstudio/application-service/pkg/spi"
 	"github.com/redhat-appstudio/application-service/pkg/util"
 	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
 	gitopsgen "github.com/redhat-developer/gitops-generator/pkg"
 
 	"github.com/spf13/afero"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 )
 
 // ComponentReconciler reconciles a Component object
@@ -67,13 +73,41 @@ type ComponentReconciler struct {
 	Executor        gitopsgen.Executor
 	AppFS           afero.Afero
 	SPIClient       spi.SPI
+	GitHubClient    *gh.Client
+
+	// ImageRegistry provisions the image repositories of the Components that do not specify their container image.
+	// The Components are built into the shared ImageRepository if it is not set.
+	ImageRegistry imageregistry.ImageRegistry
+
+	// ImageRepositoryScope provisions an image repository per Component, or per Application
+	ImageRepositoryScope string
+
+	// DigestResolver resolves the digest of the build bundles recorded in the status of the Components.
+	// Only the digests of the bundles pinned by digest are recorded if it is not set.
+	DigestResolver *imageregistry.DigestResolver
+	// bundleDigests caches the digests resolved by the DigestResolver
+	bundleDigests bundleDigestCache
+
+	// Ingress is set when the cluster has no OpenShift Routes, so that Ingresses are generated instead
+	Ingress *prepare.IngressConfig
+
+	// BuildWorkspace configures the storage of the workspace of the build PipelineRuns
+	BuildWorkspace prepare.BuildWorkspaceConfig
+
+	// HACBSDetector detects the HACBS workflow from the APIBindings and APIExports of the kcp workspaces.
+	// Only the hacbs ConfigMap is looked up if it is not set.
+	HACBSDetector *prepare.HACBSDetector
 }
 
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=components,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=components/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=components/finalizers,verbs=update
-//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
-//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=triggers.tekton.dev,resources=clustertriggerbindings,verbs=get
+//+kubebuilder:rbac:groups=tekton.dev,resources=pipelineruns,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=apis.kcp.dev,resources=apibindings;apiexports,verbs=get;list
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -140,7 +174,7 @@ func (r *ComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			log.Info(fmt.Sprintf("added the finalizer %v", req.NamespacedName))
 		}
 	} else {
-		if hasApplication.Status.Devfile != "" && len(component.Status.Conditions) > 0 && component.Status.Conditions[len(component.Status.Conditions)-1].Status == metav1.ConditionTrue && containsString(component.GetFinalizers(), compFinalizerName) {
+		if hasApplication.Status.Devfile != "" && isComponentReconciled(component) && containsString(component.GetFinalizers(), compFinalizerName) {
 			// only attempt to finalize and update the gitops repo if an Application is present & the previous Component status is good
 			// A finalizer is present for the Component CR, so make sure we do the necessary cleanup steps
 			if err := r.Finalize(ctx, &component, &hasApplication); err != nil {
@@ -152,22 +186,52 @@ func (r *ComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 		// Remove the finalizer if no Application is present or an Application is present at this stage
 		if containsString(component.GetFinalizers(), compFinalizerName) {
+			// The webhook secret and the image repository may have been added before the component failed to reconcile,
+			// or the GitOps repository failed to be updated, so they are always cleaned up
+			if err := r.removePaCWebhookSecret(ctx, &component); err != nil {
+				return ctrl.Result{}, err
+			}
+			if err := r.removeImageRepository(ctx, &component); err != nil {
+				log.Error(err, fmt.Sprintf("Unable to remove the image repository of the component %v", req.NamespacedName))
+				return ctrl.Result{}, err
+			}
 			// remove the finalizer from the list and update it.
 			controllerutil.RemoveFinalizer(&component, compFinalizerName)
 			if err := r.Update(ctx, &component); err != nil {
 				return ctrl.Result{}, err
 			}
 		}
+
+		// The component is being deleted, there is nothing left to reconcile
+		return ctrl.Result{}, nil
 	}
 
 	log.Info(fmt.Sprintf("Starting reconcile loop for %v", req.NamespacedName))
 
 	if component.Spec.ContainerImage == "" {
-		component.Spec.ContainerImage = r.ImageRepository + ":" + component.Namespace + "-" + component.Name
+		var imageRepository *appstudiov1alpha1.ImageRepositoryStatus
+		if r.ImageRegistry != nil {
+			imageRepository, err = r.ensureImageRepository(ctx, &component)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Unable to provision the image repository of the component %v", req.NamespacedName))
+				r.SetCreateConditionAndUpdateCR(ctx, req, &component, err)
+				return ctrl.Result{}, err
+			}
+			component.Spec.ContainerImage = imageRepository.URL
+		} else {
+			component.Spec.ContainerImage = r.ImageRepository + ":" + component.Namespace + "-" + component.Name
+		}
 		if err := r.Client.Update(ctx, &component); err != nil {
 			log.Error(err, fmt.Sprintf("Failed to set default component image: %s", component.Spec.ContainerImage))
 			return ctrl.Result{}, err
 		}
+		if imageRepository != nil {
+			component.Status.ImageRepository = imageRepository
+			if err := r.Client.Status().Update(ctx, &component); err != nil {
+				log.Error(err, fmt.Sprintf("Unable to record the image repository of the component %v", req.NamespacedName))
+				return ctrl.Result{}, err
+			}
+		}
 		log.Info(fmt.Sprintf("Set component image to default value: %s", component.Spec.ContainerImage))
 		return ctrl.Result{Requeue: true}, nil
 	}
@@ -339,9 +403,19 @@ func (r *ComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 				return ctrl.Result{}, err
 			}
 
-			// Generate and push the gitops resources
-			if !component.Spec.SkipGitOpsResourceGeneration {
-				if err := r.generateGitops(ctx, req, &component); err != nil {
+			// Generate and push the gitops resources, or only render them if the component is in dry-run mode
+			if !component.Spec.SkipGitOpsResourceGeneration && appservicegitops.IsGitOpsDryRun(component) {
+				if err := r.previewGitops(ctx, req, &component); err != nil {
+					errMsg := fmt.Sprintf("Unable to preview gitops resources for component %v", req.NamespacedName)
+					log.Error(err, errMsg)
+					r.SetGitOpsPreviewedConditionAndUpdateCR(ctx, &component, fmt.Errorf("%v: %v", errMsg, err))
+					r.SetCreateConditionAndUpdateCR(ctx, req, &component, fmt.Errorf("%v: %v", errMsg, err))
+					return ctrl.Result{}, err
+				} else {
+					r.SetGitOpsPreviewedConditionAndUpdateCR(ctx, &component, nil)
+				}
+			} else if !component.Spec.SkipGitOpsResourceGeneration {
+				if err := r.generateGitops(ctx, req, &component, &hasApplication); err != nil {
 					errMsg := fmt.Sprintf("Unable to generate gitops resources for component %v", req.NamespacedName)
 					log.Error(err, errMsg)
 					r.SetGitOpsGeneratedConditionAndUpdateCR(ctx, &component, fmt.Errorf("%v: %v", errMsg, err))
@@ -385,7 +459,13 @@ func (r *ComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 		containerImage := component.Spec.ContainerImage
 		skipGitOpsGeneration := component.Spec.SkipGitOpsResourceGeneration
-		isUpdated := !reflect.DeepEqual(oldCompDevfileData, hasCompDevfileData) || containerImage != component.Status.ContainerImage || skipGitOpsGeneration != component.Status.GitOps.ResourceGenerationSkipped
+		// Switching the dry-run mode on or off also requires the gitops resources to be rendered or pushed again
+		isDryRunToggled := appservicegitops.IsGitOpsDryRun(component) != (component.Status.GitOps.PreviewConfigMap != "")
+		// A new build bundle, rolled out in the build-pipelines-defaults ConfigMap or pinned by the Component, also requires the build resources to be generated again
+		isBuildBundleChanged := r.isBuildBundleChanged(ctx, component)
+		// So does a new image tagging of the Component, or of its Application
+		isImageTaggingChanged := isImageTaggingChanged(component, hasApplication)
+		isUpdated := !reflect.DeepEqual(oldCompDevfileData, hasCompDevfileData) || containerImage != component.Status.ContainerImage || skipGitOpsGeneration != component.Status.GitOps.ResourceGenerationSkipped || isDryRunToggled || isBuildBundleChanged || isImageTaggingChanged
 		if isUpdated {
 			log.Info(fmt.Sprintf("The Component was updated %v", req.NamespacedName))
 			component.Status.GitOps.ResourceGenerationSkipped = skipGitOpsGeneration
@@ -398,8 +478,18 @@ func (r *ComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 			// Generate and push the gitops resources, if necessary.
 			component.Status.ContainerImage = component.Spec.ContainerImage
-			if !component.Spec.SkipGitOpsResourceGeneration {
-				if err := r.generateGitops(ctx, req, &component); err != nil {
+			if !component.Spec.SkipGitOpsResourceGeneration && appservicegitops.IsGitOpsDryRun(component) {
+				if err := r.previewGitops(ctx, req, &component); err != nil {
+					errMsg := fmt.Sprintf("Unable to preview gitops resources for component %v", req.NamespacedName)
+					log.Error(err, errMsg)
+					r.SetGitOpsPreviewedConditionAndUpdateCR(ctx, &component, fmt.Errorf("%v: %v", errMsg, err))
+					r.SetUpdateConditionAndUpdateCR(ctx, req, &component, fmt.Errorf("%v: %v", errMsg, err))
+					return ctrl.Result{}, err
+				} else {
+					r.SetGitOpsPreviewedConditionAndUpdateCR(ctx, &component, nil)
+				}
+			} else if !component.Spec.SkipGitOpsResourceGeneration {
+				if err := r.generateGitops(ctx, req, &component, &hasApplication); err != nil {
 					errMsg := fmt.Sprintf("Unable to generate gitops resources for component %v", req.NamespacedName)
 					log.Error(err, errMsg)
 					r.SetGitOpsGeneratedConditionAndUpdateCR(ctx, &component, fmt.Errorf("%v: %v", errMsg, err))
@@ -415,16 +505,64 @@ func (r *ComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 		} else {
 			log.Info(fmt.Sprintf("The Component devfile data was not updated %v", req.NamespacedName))
+
+			if component.Status.GitOps.PullRequest.State == "open" {
+				if err := r.refreshPullRequestState(ctx, &component); err != nil {
+					log.Error(err, fmt.Sprintf("Unable to refresh the state of the gitops pull request %s", component.Status.GitOps.PullRequest.URL))
+				}
+			}
+
+			// Open the Pipelines as Code onboarding pull request again if it failed
+			if !component.Spec.SkipGitOpsResourceGeneration && !appservicegitops.IsGitOpsDryRun(component) && component.Spec.Source.GitSource != nil && component.Spec.Source.GitSource.URL != "" &&
+				isPaCOnboardingFailed(component) {
+				if err := r.retryPaCOnboardingPullRequest(ctx, &component); err != nil {
+					log.Error(err, fmt.Sprintf("Unable to update the onboarding pull request of the component %v", req.NamespacedName))
+					return ctrl.Result{}, err
+				}
+			}
+
+			// Merge the registry secrets of the builds again once one of them is rotated
+			if !component.Spec.SkipGitOpsResourceGeneration && component.Spec.Source.GitSource != nil && component.Spec.Source.GitSource.URL != "" &&
+				r.isRegistryAuthSecretStale(ctx, component) {
+				if err := r.ensureRegistryAuthSecret(ctx, &component, r.getGitopsConfig(ctx, component)); err != nil {
+					log.Error(err, fmt.Sprintf("Unable to refresh the registry auth secret of the component %v", req.NamespacedName))
+					return ctrl.Result{}, err
+				}
+			}
 		}
 	}
 
-	// Get the Webhook from the event listener route and update it
-	// Only attempt to get it if the build generation succeeded, otherwise the route won't exist
-	if len(component.Status.Conditions) > 0 && component.Status.Conditions[len(component.Status.Conditions)-1].Status == metav1.ConditionTrue &&
+	// Build the image of the component once its gitops resources are generated, or when a rebuild is requested
+	if isComponentReconciled(component) &&
 		component.Spec.Source.GitSource != nil && component.Spec.Source.GitSource.URL != "" &&
+		!component.Spec.SkipGitOpsResourceGeneration && !appservicegitops.IsGitOpsDryRun(component) {
+		if err := r.submitInitialBuild(ctx, &component); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to submit the build of the component %v", req.NamespacedName))
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Reflect the last build of the component in its status
+	if component.Spec.Source.GitSource != nil && component.Spec.Source.GitSource.URL != "" {
+		if err := r.updateBuildStatus(ctx, &component); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to update the build status of the component %v", req.NamespacedName))
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Reflect the merge of the Pipelines as Code onboarding pull request in the component's status
+	if component.Status.Build.OnboardingPullRequest.State == "open" {
+		if err := r.refreshOnboardingPullRequestState(ctx, &component); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to refresh the state of the onboarding pull request %s", component.Status.Build.OnboardingPullRequest.URL))
+		}
+	}
+
+	// Get the Webhook from the event listener route, or ingress, and update it
+	// Only attempt to get it if the build generation succeeded, otherwise the route won't exist
+	if isComponentReconciled(component) &&
+		component.Spec.Source.GitSource != nil && component.Spec.Source.GitSource.URL != "" && !appservicegitops.IsGitOpsDryRun(component) &&
 		(component.ObjectMeta.Annotations == nil || component.ObjectMeta.Annotations[appservicegitops.PaCAnnotation] != "1") {
-		createdWebhook := &routev1.Route{}
-		err = r.Client.Get(ctx, types.NamespacedName{Name: "el" + component.Name, Namespace: component.Namespace}, createdWebhook)
+		webhook, err := r.getBuildWebhookHost(ctx, component)
 		if err != nil {
 			if errors.IsNotFound(err) {
 				log.Error(err, fmt.Sprintf("Unable to fetch the created webhook %v, retrying", "el-"+component.Name))
@@ -434,20 +572,52 @@ func (r *ComponentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			}
 		}
 
-		// Get the ingress url from the status of the route, if it exists
-		if len(createdWebhook.Status.Ingress) != 0 {
-			component.Status.Webhook = createdWebhook.Status.Ingress[0].Host
+		if webhook != "" {
+			component.Status.Webhook = webhook
 			r.Client.Status().Update(ctx, &component)
 		}
 	}
 
 	log.Info(fmt.Sprintf("Finished reconcile loop for %v", req.NamespacedName))
+	if isPaCOnboardingFailed(component) && !component.Spec.SkipGitOpsResourceGeneration && !appservicegitops.IsGitOpsDryRun(component) {
+		// Retry opening the onboarding pull request with the backoff of the controller
+		return ctrl.Result{Requeue: true}, nil
+	}
+	if component.Status.GitOps.PullRequest.State == "open" || component.Status.Build.OnboardingPullRequest.State == "open" {
+		// Keep track of the gitops and onboarding pull requests until they are merged or closed
+		return ctrl.Result{RequeueAfter: pullRequestResyncPeriod}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// getBuildWebhookHost returns the host exposing the build webhook of the component, read from the status of its Route,
+// or from its Ingress on clusters without OpenShift Routes. An empty string is returned if the host is not known yet.
+func (r *ComponentReconciler) getBuildWebhookHost(ctx context.Context, component appstudiov1alpha1.Component) (string, error) {
+	webhookName := types.NamespacedName{Name: "el" + component.Name, Namespace: component.Namespace}
+	if r.Ingress != nil {
+		createdWebhook := &networkingv1.Ingress{}
+		if err := r.Client.Get(ctx, webhookName, createdWebhook); err != nil {
+			return "", err
+		}
+		return appservicegitops.GetIngressHost(*createdWebhook), nil
+	}
+
+	createdWebhook := &routev1.Route{}
+	if err := r.Client.Get(ctx, webhookName, createdWebhook); err != nil {
+		return "", err
+	}
+	// Get the ingress url from the status of the route, if it exists
+	if len(createdWebhook.Status.Ingress) != 0 {
+		return createdWebhook.Status.Ingress[0].Host, nil
+	}
+	return "", nil
+}
+
 // generateGitops retrieves the necessary information about a Component's gitops repository (URL, branch, context)
-// and attempts to use the GitOps package to generate gitops resources based on that component
-func (r *ComponentReconciler) generateGitops(ctx context.Context, req ctrl.Request, component *appstudiov1alpha1.Component) error {
+// and attempts to use the GitOps package to generate gitops resources based on that component.
+// If the application delivers its gitops changes through pull requests, the resources are pushed to a separate branch
+// and a pull request is opened against the gitops branch.
+func (r *ComponentReconciler) generateGitops(ctx context.Context, req ctrl.Request, component *appstudiov1alpha1.Component, application *appstudiov1alpha1.Application) error {
 	log := r.Log.WithValues("Component", req.NamespacedName).WithValues("clusterName", req.ClusterName)
 
 	gitOpsURL, gitOpsBranch, gitOpsContext, err := util.ProcessGitOpsStatus(component.Status.GitOps, r.GitToken)
@@ -462,43 +632,220 @@ func (r *ComponentReconciler) generateGitops(ctx context.Context, req ctrl.Reque
 		return fmt.Errorf("unable to create temp directory for gitops resources due to error: %v", err)
 	}
 
+	// Apply the application's commit configuration to the commits pushed to the gitops repository
+	keyDir := filepath.Join(tempDir, signingKeyDirName)
+	// The signing key must not outlive the commit, even if the temp folder does
+	defer func() { _ = r.AppFS.RemoveAll(keyDir) }()
+	executor, err := newGitOpsCommitExecutor(ctx, r.Client, r.Executor, r.AppFS, application, keyDir, appservicegitops.CommitMessageData{
+		Component:   component.Name,
+		Application: component.Spec.Application,
+		Namespace:   component.Namespace,
+		Resource:    "Component/" + component.Name,
+	})
+	if err != nil {
+		log.Error(err, "unable to configure the gitops commits due to error")
+		return err
+	}
+
 	// Generate and push the gitops resources
-	gitopsConfig := prepare.PrepareGitopsConfig(ctx, r.Client, *component)
+	gitopsConfig := r.getGitopsConfig(ctx, *component)
+	if component.Spec.Source.GitSource != nil && component.Spec.Source.GitSource.URL != "" {
+		buildPipeline := appservicegitops.SelectBuildPipeline(*component, gitopsConfig)
+		imageTagging := appservicegitops.GetImageTagging(*component, gitopsConfig)
+		component.Status.BuildPipeline = appstudiov1alpha1.BuildPipelineStatus{
+			Name:         buildPipeline.Name,
+			Reason:       buildPipeline.Reason,
+			Bundle:       gitopsConfig.BuildBundle,
+			BundleDigest: r.getBuildBundleDigest(ctx, gitopsConfig.BuildBundle),
+			ImageTagging: &imageTagging,
+		}
+		component.Status.BuildPipeline.Workflow, component.Status.BuildPipeline.WorkflowReason = getBuildWorkflow(gitopsConfig)
+
+		// The builds only mount a single Secret holding the credentials of the image registries
+		if err := r.ensureRegistryAuthSecret(ctx, component, gitopsConfig); err != nil {
+			log.Error(err, "unable to create the registry auth secret due to error")
+			return err
+		}
+
+		if !appservicegitops.IsPaCBuild(*component, gitopsConfig) {
+			// The build webhook only accepts the events signed with the component's webhook secret
+			if err := r.ensureBuildWebhookSecret(ctx, component); err != nil {
+				log.Error(err, "unable to create the build webhook secret due to error")
+				return err
+			}
+		} else if gitProvider, err := appservicegitops.GetGitProvider(*component); err == nil && !appservicegitops.IsPaCApplicationConfigured(gitProvider, gitopsConfig.PipelinesAsCodeCredentials) {
+			// Pipelines as Code only accepts the webhook events signed with the secret of the git repository
+			if err := r.ensurePaCWebhookSecret(ctx, component); err != nil {
+				log.Error(err, "unable to create the Pipelines as Code webhook secret due to error")
+				return err
+			}
+			if gitProvider == "bitbucket" && appservicegitops.GetGitProviderUser(*component, gitopsConfig.PipelinesAsCodeCredentials) == "" {
+				log.Info(fmt.Sprintf("The user of the Bitbucket token is not configured for component %s, Pipelines as Code cannot access its repository until it is set in the gitProviderUser field, the %s annotation or the Pipelines as Code secret", component.Name, appservicegitops.GitProviderUserAnnotationName))
+			}
+		}
+	}
 	mappedGitOpsComponent := util.GetMappedGitOpsComponent(*component)
-	err = gitopsgen.CloneGenerateAndPush(tempDir, gitOpsURL, mappedGitOpsComponent, r.Executor, r.AppFS, gitOpsBranch, gitOpsContext, false)
+	err = gitopsgen.CloneGenerateAndPush(tempDir, gitOpsURL, mappedGitOpsComponent, executor, r.AppFS, gitOpsBranch, gitOpsContext, false)
 	if err != nil {
 		gitOpsErr := util.SanitizeErrorMessage(err)
 		log.Error(gitOpsErr, "unable to generate gitops resources due to error")
 		return gitOpsErr
 	}
 
+	err = appservicegitops.GenerateComponentIngress(tempDir, *component, r.AppFS, gitOpsContext, gitopsConfig)
+	if err != nil {
+		gitOpsErr := util.SanitizeErrorMessage(err)
+		log.Error(gitOpsErr, "unable to generate gitops ingress resources due to error")
+		return gitOpsErr
+	}
+
 	err = appservicegitops.GenerateTektonBuild(tempDir, *component, r.AppFS, gitOpsContext, gitopsConfig)
 	if err != nil {
 		gitOpsErr := util.SanitizeErrorMessage(err)
 		log.Error(gitOpsErr, "unable to generate gitops build resources due to error")
 		return gitOpsErr
 	}
-	err = gitopsgen.CommitAndPush(tempDir, "", gitOpsURL, mappedGitOpsComponent.Name, r.Executor, gitOpsBranch, "Generating Tekton resources")
+	if appservicegitops.IsPullRequestDelivery(application) {
+		err = r.deliverGitopsPullRequest(ctx, executor, tempDir, gitOpsURL, gitOpsBranch, component, "Generating Tekton resources")
+	} else {
+		err = gitopsgen.CommitAndPush(tempDir, "", gitOpsURL, mappedGitOpsComponent.Name, executor, gitOpsBranch, "Generating Tekton resources")
+	}
 	if err != nil {
 		gitOpsErr := util.SanitizeErrorMessage(err)
 		log.Error(gitOpsErr, "unable to commit and push gitops resources due to error")
 		return gitOpsErr
 	}
 
-	// Get the commit ID for the gitops repository
-	var commitID string
-	repoPath := filepath.Join(tempDir, component.Name)
-	if commitID, err = gitopsgen.GetCommitIDFromRepo(r.AppFS, r.Executor, repoPath); err != nil {
-		gitOpsErr := util.SanitizeErrorMessage(err)
-		log.Error(gitOpsErr, "unable to retrieve gitops repository commit id due to error")
-		return gitOpsErr
+	// Get the commit ID for the gitops repository. The changes delivered through a pull request are only committed to the
+	// gitops branch once the pull request is merged, so its commit ID is recorded when the merge is observed.
+	if !appservicegitops.IsPullRequestDelivery(application) {
+		var commitID string
+		repoPath := filepath.Join(tempDir, component.Name)
+		if commitID, err = gitopsgen.GetCommitIDFromRepo(r.AppFS, r.Executor, repoPath); err != nil {
+			gitOpsErr := util.SanitizeErrorMessage(err)
+			log.Error(gitOpsErr, "unable to retrieve gitops repository commit id due to error")
+			return gitOpsErr
+		}
+		component.Status.GitOps.CommitID = commitID
+	}
+
+	// The source repository needs the PipelineRuns Pipelines as Code builds the component with
+	if component.Spec.Source.GitSource != nil && component.Spec.Source.GitSource.URL != "" && appservicegitops.IsPaCBuild(*component, gitopsConfig) {
+		r.ensurePaCOnboardingPullRequest(ctx, component, gitopsConfig)
+	}
+
+	// The resources have been pushed, so a preview from an earlier dry-run is no longer relevant
+	if component.Status.GitOps.PreviewConfigMap != "" {
+		previewConfigMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      component.Status.GitOps.PreviewConfigMap,
+				Namespace: component.Namespace,
+			},
+		}
+		if err := r.Client.Delete(ctx, previewConfigMap); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "unable to delete the gitops preview configmap")
+		}
+		component.Status.GitOps.PreviewConfigMap = ""
 	}
-	component.Status.GitOps.CommitID = commitID
 
 	// Remove the temp folder that was created
 	return r.AppFS.RemoveAll(tempDir)
 }
 
+// deliverGitopsPullRequest pushes the gitops changes cloned in tempDir to the component's pull request branch and opens,
+// or updates, the pull request against the gitops branch. The pull request is recorded in the component's status.
+func (r *ComponentReconciler) deliverGitopsPullRequest(ctx context.Context, executor gitopsgen.Executor, tempDir string, gitOpsURL string, gitOpsBranch string, component *appstudiov1alpha1.Component, commitMessage string) error {
+	prBranch := appservicegitops.GetPullRequestBranch(component.Name)
+	pushed, err := appservicegitops.CommitAndPushToPullRequestBranch(tempDir, "", gitOpsURL, component.Name, executor, prBranch, commitMessage)
+	if err != nil || !pushed {
+		return err
+	}
+
+	title := fmt.Sprintf("Update GitOps resources for component %s", component.Name)
+	pullRequest, err := openGitOpsPullRequest(ctx, r.GitHubClient, component.Status.GitOps.RepositoryURL, prBranch, gitOpsBranch, title)
+	if err != nil {
+		return err
+	}
+	component.Status.GitOps.PullRequest = pullRequest
+	return nil
+}
+
+// refreshPullRequestState updates the state of the component's gitops pull request in its status,
+// and records its merge commit as the component's gitops commit ID once it is merged
+func (r *ComponentReconciler) refreshPullRequestState(ctx context.Context, component *appstudiov1alpha1.Component) error {
+	state, mergeCommitID, err := getGitOpsPullRequestState(ctx, r.GitHubClient, component.Status.GitOps.RepositoryURL, component.Status.GitOps.PullRequest.Number)
+	if err != nil {
+		return err
+	}
+	if state == component.Status.GitOps.PullRequest.State {
+		return nil
+	}
+	component.Status.GitOps.PullRequest.State = state
+	if mergeCommitID != "" {
+		component.Status.GitOps.CommitID = mergeCommitID
+	}
+	return r.Client.Status().Update(ctx, component)
+}
+
+// previewGitops renders the gitops and build resources of a Component in memory and publishes them, together with a diff
+// against the current content of the gitops repository, in a ConfigMap. Nothing is committed or pushed to the repository.
+func (r *ComponentReconciler) previewGitops(ctx context.Context, req ctrl.Request, component *appstudiov1alpha1.Component) error {
+	log := r.Log.WithValues("Component", req.NamespacedName).WithValues("clusterName", req.ClusterName)
+
+	gitOpsURL, gitOpsBranch, gitOpsContext, err := util.ProcessGitOpsStatus(component.Status.GitOps, r.GitToken)
+	if err != nil {
+		return err
+	}
+
+	gitopsConfig := r.getGitopsConfig(ctx, *component)
+	rendered, err := appservicegitops.GeneratePreview(*component, gitOpsContext, gitopsConfig)
+	if err != nil {
+		gitOpsErr := util.SanitizeErrorMessage(err)
+		log.Error(gitOpsErr, "unable to render gitops resources due to error")
+		return gitOpsErr
+	}
+
+	// Read the current resources of the component from the gitops repository to diff against
+	tempDir, err := ioutils.CreateTempPath(component.Name, r.AppFS)
+	if err != nil {
+		log.Error(err, "unable to create temp directory for gitops resources due to error")
+		return fmt.Errorf("unable to create temp directory for gitops resources due to error: %v", err)
+	}
+	current, err := appservicegitops.CloneAndReadResources(tempDir, gitOpsURL, component.Name, r.Executor, r.AppFS, gitOpsBranch, gitOpsContext)
+	_ = r.AppFS.RemoveAll(tempDir) // best effort, the clone is only used for the diff
+	if err != nil {
+		gitOpsErr := util.SanitizeErrorMessage(err)
+		log.Error(gitOpsErr, "unable to read the current gitops resources due to error")
+		return gitOpsErr
+	}
+
+	diff, err := appservicegitops.DiffResources(current, rendered)
+	if err != nil {
+		return err
+	}
+
+	previewConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      appservicegitops.GetPreviewConfigMapName(*component),
+			Namespace: component.Namespace,
+		},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, previewConfigMap, func() error {
+		previewConfigMap.Data = map[string]string{
+			appservicegitops.PreviewManifestsKey: appservicegitops.FormatPreviewManifests(rendered),
+			appservicegitops.PreviewDiffKey:      diff,
+		}
+		return controllerutil.SetOwnerReference(component, previewConfigMap, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "unable to create or update the gitops preview configmap")
+		return err
+	}
+
+	component.Status.GitOps.PreviewConfigMap = previewConfigMap.Name
+	return nil
+}
+
 // setGitopsStatus adds the necessary gitops info (url, branch, context) to the component CR status
 func setGitopsStatus(component *appstudiov1alpha1.Component, devfileData data.DevfileData) error {
 	var err error
@@ -540,7 +887,23 @@ func setGitopsStatus(component *appstudiov1alpha1.Component, devfileData data.De
 // SetupWithManager sets up the controller with the Manager.
 func (r *ComponentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&appstudiov1alpha1.Component{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		For(&appstudiov1alpha1.Component{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		// Watch the build PipelineRuns and reconcile the Components they build
+		Watches(&source.Kind{Type: &tektonapi.PipelineRun{}}, handler.EnqueueRequestsFromMapFunc(MapToComponentByBuildLabel)).
+		// Watch the build bundle ConfigMaps and regenerate the build resources of the Components whose bundle changed
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(MapToComponentsByBuildBundleConfigMap(mgr.GetClient(), r.HACBSDetector)),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+				return object.GetName() == prepare.BuildBundleConfigMapName
+			}))).
+		// Watch the registry secrets and merge them again into the registry auth secrets of the Components they were merged into
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(MapToComponentsByRegistrySecret(mgr.GetClient())),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+				secret, ok := object.(*corev1.Secret)
+				return ok && (secret.Type == corev1.SecretTypeDockerConfigJson || secret.Type == corev1.SecretTypeDockercfg)
+			}))).
+		// Watch the Applications and regenerate the build resources of the Components whose image tagging changed
+		Watches(&source.Kind{Type: &appstudiov1alpha1.Application{}}, handler.EnqueueRequestsFromMapFunc(MapToComponentsByApplicationImageTagging(mgr.GetClient())),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		WithOptions(controller.Options{
 			RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(time.Duration(500*time.Millisecond), time.Duration(60*time.Second)),
 		}).
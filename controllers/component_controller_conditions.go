@@ -101,3 +101,40 @@ func (r *ComponentReconciler) SetGitOpsGeneratedConditionAndUpdateCR(ctx context
 		log.Error(err, "Unable to update Component")
 	}
 }
+
+func (r *ComponentReconciler) SetGitOpsPreviewedConditionAndUpdateCR(ctx context.Context, component *appstudiov1alpha1.Component, previewError error) {
+	log := r.Log.WithValues("Component", component.Name)
+
+	if previewError == nil {
+		meta.SetStatusCondition(&component.Status.Conditions, metav1.Condition{
+			Type:    "GitOpsResourcesPreviewed",
+			Status:  metav1.ConditionTrue,
+			Reason:  "OK",
+			Message: fmt.Sprintf("GitOps resources rendered successfully into ConfigMap %s", component.Status.GitOps.PreviewConfigMap),
+		})
+	} else {
+		meta.SetStatusCondition(&component.Status.Conditions, metav1.Condition{
+			Type:    "GitOpsResourcesPreviewed",
+			Status:  metav1.ConditionFalse,
+			Reason:  "PreviewError",
+			Message: fmt.Sprintf("GitOps resources failed to render: %v", previewError),
+		})
+	}
+
+	err := r.Client.Status().Update(ctx, component)
+	if err != nil {
+		log.Error(err, "Unable to update Component")
+	}
+}
+
+// isComponentReconciled returns whether the last creation or update of the component succeeded.
+// The Created and Updated conditions are set last by each reconcile, but the Built condition can follow them.
+func isComponentReconciled(component appstudiov1alpha1.Component) bool {
+	for i := len(component.Status.Conditions) - 1; i >= 0; i-- {
+		condition := component.Status.Conditions[i]
+		if condition.Type == "Created" || condition.Type == "Updated" {
+			return condition.Status == metav1.ConditionTrue
+		}
+	}
+	return false
+}
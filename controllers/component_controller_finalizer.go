@@ -19,10 +19,12 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 
 	gitopsgen "github.com/redhat-developer/gitops-generator/pkg"
 
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
 	devfile "github.com/redhat-appstudio/application-service/pkg/devfile"
 	"github.com/redhat-appstudio/application-service/pkg/util"
 	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
@@ -81,7 +83,25 @@ func (r *ComponentReconciler) Finalize(ctx context.Context, component *appstudio
 		return fmt.Errorf("unable to create temp directory for gitops resources due to error: %v", err)
 	}
 
-	err = gitopsgen.RemoveAndPush(tempDir, gitOpsURL, component.Name, r.Executor, r.AppFS, gitOpsBranch, gitOpsContext, true)
+	// Apply the application's commit configuration to the commits pushed to the gitops repository
+	keyDir := filepath.Join(tempDir, signingKeyDirName)
+	// The signing key must not outlive the commit, even if the temp folder does
+	defer func() { _ = r.AppFS.RemoveAll(keyDir) }()
+	executor, err := newGitOpsCommitExecutor(ctx, r.Client, r.Executor, r.AppFS, application, keyDir, appservicegitops.CommitMessageData{
+		Component:   component.Name,
+		Application: component.Spec.Application,
+		Namespace:   component.Namespace,
+		Resource:    "Component/" + component.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	pullRequestDelivery := appservicegitops.IsPullRequestDelivery(application)
+	err = gitopsgen.RemoveAndPush(tempDir, gitOpsURL, component.Name, executor, r.AppFS, gitOpsBranch, gitOpsContext, !pullRequestDelivery)
+	if err == nil && pullRequestDelivery {
+		err = r.deliverGitopsPullRequest(ctx, executor, tempDir, gitOpsURL, gitOpsBranch, component, fmt.Sprintf("Removed component %s", component.Name))
+	}
 	if err != nil {
 		gitOpsErr := util.SanitizeErrorMessage(err)
 		return gitOpsErr
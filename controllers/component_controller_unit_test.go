@@ -17,24 +17,45 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
 	"github.com/devfile/api/v2/pkg/attributes"
 	data "github.com/devfile/library/pkg/devfile/parser/data"
 	v2 "github.com/devfile/library/pkg/devfile/parser/data/v2"
+	gh "github.com/google/go-github/v41/github"
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
 	"github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/redhat-appstudio/application-service/pkg/imageregistry"
 	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
 	"github.com/redhat-developer/gitops-generator/pkg/testutils"
 	"github.com/spf13/afero"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"golang.org/x/oauth2"
+	"k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	devfileApi "github.com/devfile/api/v2/pkg/devfile"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -148,14 +169,20 @@ func TestSetGitOpsStatus(t *testing.T) {
 }
 
 func TestGenerateGitops(t *testing.T) {
+	ctx := context.Background()
 	executor := testutils.NewMockExecutor()
 	appFS := ioutils.NewMemoryFilesystem()
 	readOnlyFs := ioutils.NewReadOnlyFs()
 
+	// The build webhook secret is owned by the component, so the component type must be registered
+	if err := appstudiov1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
 	fakeClient := fake.NewClientBuilder().Build()
 
 	r := &ComponentReconciler{
 		Log:       ctrl.Log.WithName("controllers").WithName("Component"),
+		Scheme:    scheme.Scheme,
 		GitHubOrg: github.AppStudioAppDataOrg,
 		GitToken:  "fake-token",
 		Executor:  executor,
@@ -167,12 +194,69 @@ func TestGenerateGitops(t *testing.T) {
 	errExec.Errors.Push(errors.New("Fatal error"))
 	errReconciler := &ComponentReconciler{
 		Log:       ctrl.Log.WithName("controllers").WithName("Component"),
+		Scheme:    scheme.Scheme,
 		GitHubOrg: github.AppStudioAppDataOrg,
 		GitToken:  "fake-token",
 		Executor:  errExec,
 		Client:    fakeClient,
 	}
 
+	// Create reconcilers for testing pull request delivery, where the git diff of the pull request branch is not empty
+	newPullRequestReconciler := func(githubClient *gh.Client) *ComponentReconciler {
+		// The diff is the sixth command executed: clone, switch, rm, checkout, add and diff
+		prExec := testutils.NewMockExecutor([]byte("diff"), nil, nil, nil, nil, nil)
+		return &ComponentReconciler{
+			Log:          ctrl.Log.WithName("controllers").WithName("Component"),
+			Scheme:       scheme.Scheme,
+			GitHubOrg:    github.AppStudioAppDataOrg,
+			GitToken:     "fake-token",
+			Executor:     prExec,
+			Client:       fakeClient,
+			GitHubClient: githubClient,
+		}
+	}
+
+	pullRequestApplication := appstudiov1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				appservicegitops.GitOpsDeliveryModeAnnotation: appservicegitops.PullRequestDeliveryMode,
+			},
+		},
+	}
+
+	// Create a reconciler for testing signed commits, where the git diff of the gitops repository is not empty
+	signingSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "signing-secret",
+			Namespace: "test-namespace",
+		},
+		Data: map[string][]byte{
+			appservicegitops.SSHSigningKey: []byte("ssh-key"),
+		},
+	}
+	signingReconciler := &ComponentReconciler{
+		Log:       ctrl.Log.WithName("controllers").WithName("Component"),
+		Scheme:    scheme.Scheme,
+		GitHubOrg: github.AppStudioAppDataOrg,
+		GitToken:  "fake-token",
+		// The diff is the sixth command executed: git version, clone, switch, rm, add and diff
+		Executor: testutils.NewMockExecutor([]byte("diff"), nil, nil, nil, nil, []byte("git version 2.34.1")),
+		Client:   fake.NewClientBuilder().WithRuntimeObjects(signingSecret).Build(),
+	}
+	signingApplication := appstudiov1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-namespace",
+		},
+		Spec: appstudiov1alpha1.ApplicationSpec{
+			GitOpsCommit: appstudiov1alpha1.GitOpsCommitConfiguration{
+				AuthorName:      "GitOps Bot",
+				AuthorEmail:     "gitops-bot@example.com",
+				SigningSecret:   "signing-secret",
+				MessageTemplate: "[{{ .Application }}/{{ .Component }}] {{ .Message }} for {{ .Resource }}",
+			},
+		},
+	}
+
 	componentSpec := appstudiov1alpha1.ComponentSpec{
 		ComponentName: "test-component",
 		Application:   "test-app",
@@ -186,11 +270,14 @@ func TestGenerateGitops(t *testing.T) {
 	}
 
 	tests := []struct {
-		name       string
-		reconciler *ComponentReconciler
-		fs         afero.Afero
-		component  *appstudiov1alpha1.Component
-		wantErr    bool
+		name            string
+		reconciler      *ComponentReconciler
+		fs              afero.Afero
+		component       *appstudiov1alpha1.Component
+		application     appstudiov1alpha1.Application
+		wantPullRequest appstudiov1alpha1.GitOpsPullRequestStatus
+		wantCommitArgs  []string
+		wantErr         bool
 	}{
 		{
 			name:       "Simple application component, no errors",
@@ -340,6 +427,135 @@ func TestGenerateGitops(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:       "Pull request delivery, no changes to deliver",
+			reconciler: r,
+			fs:         appFS,
+			component: &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "test-namespace",
+				},
+				Spec: componentSpec,
+				Status: appstudiov1alpha1.ComponentStatus{
+					GitOps: appstudiov1alpha1.GitOpsStatus{
+						RepositoryURL: "https://github.com/test/repo",
+					},
+				},
+			},
+			application: pullRequestApplication,
+			wantErr:     false,
+		},
+		{
+			name:       "Pull request delivery, pull request opened",
+			reconciler: newPullRequestReconciler(github.GetMockedClient()),
+			fs:         appFS,
+			component: &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "test-namespace",
+				},
+				Spec: componentSpec,
+				Status: appstudiov1alpha1.ComponentStatus{
+					GitOps: appstudiov1alpha1.GitOpsStatus{
+						RepositoryURL: "https://github.com/test/repo",
+					},
+				},
+			},
+			application: pullRequestApplication,
+			wantPullRequest: appstudiov1alpha1.GitOpsPullRequestStatus{
+				URL:    "https://github.com/redhat-appstudio-appdata/test-repo-1/pull/2",
+				Number: 2,
+				Branch: "appstudio-gitops-test-component",
+				State:  "open",
+			},
+			wantErr: false,
+		},
+		{
+			name:       "Pull request delivery fails without a GitHub client",
+			reconciler: newPullRequestReconciler(nil),
+			fs:         appFS,
+			component: &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "test-namespace",
+				},
+				Spec: componentSpec,
+				Status: appstudiov1alpha1.ComponentStatus{
+					GitOps: appstudiov1alpha1.GitOpsStatus{
+						RepositoryURL: "https://github.com/test/repo",
+					},
+				},
+			},
+			application: pullRequestApplication,
+			wantErr:     true,
+		},
+		{
+			name:       "Signed commit with a configured identity and message",
+			reconciler: signingReconciler,
+			fs:         appFS,
+			component: &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "test-namespace",
+				},
+				Spec: componentSpec,
+				Status: appstudiov1alpha1.ComponentStatus{
+					GitOps: appstudiov1alpha1.GitOpsStatus{
+						RepositoryURL: "https://github.com/test/repo",
+					},
+				},
+			},
+			application: signingApplication,
+			wantCommitArgs: []string{
+				"-c", "user.name=GitOps Bot",
+				"-c", "user.email=gitops-bot@example.com",
+				"-c", "gpg.format=ssh",
+				"-c", "user.signingkey=" + filepath.Join(signingKeyDirName, "signing-key"),
+				"-c", "commit.gpgsign=true",
+				"commit", "-m", "[test-app/test-component] Generating Tekton resources for Component/test-component",
+			},
+			wantErr: false,
+		},
+		{
+			name:       "Signing secret not found",
+			reconciler: r,
+			fs:         appFS,
+			component: &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "test-namespace",
+				},
+				Spec: componentSpec,
+				Status: appstudiov1alpha1.ComponentStatus{
+					GitOps: appstudiov1alpha1.GitOpsStatus{
+						RepositoryURL: "https://github.com/test/repo",
+					},
+				},
+			},
+			application: signingApplication,
+			wantErr:     true,
+		},
 		{
 			name:       "Fail to retrieve commit ID for GitOps repository [Mock]",
 			reconciler: r,
@@ -367,11 +583,1438 @@ func TestGenerateGitops(t *testing.T) {
 	for _, tt := range tests {
 		tt.reconciler.AppFS = tt.fs
 		t.Run(tt.name, func(t *testing.T) {
-			err := tt.reconciler.generateGitops(ctx, ctrl.Request{}, tt.component)
+			err := tt.reconciler.generateGitops(ctx, ctrl.Request{}, tt.component, &tt.application)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("TestGenerateGitops() unexpected error: %v", err)
 			}
+			if tt.wantCommitArgs != nil {
+				var commitArgs []string
+				for _, execution := range tt.reconciler.Executor.(*testutils.MockExecutor).Executed {
+					if execution.Command == "git" && len(execution.Args) > 0 && execution.Args[0] == "-c" {
+						commitArgs = execution.Args
+					}
+				}
+				// The signing key is loaded into a randomly named temp folder, so only compare its path within the temp folder
+				for i, arg := range commitArgs {
+					if keyPath := strings.TrimPrefix(arg, "user.signingkey="); keyPath != arg {
+						commitArgs[i] = "user.signingkey=" + filepath.Join(filepath.Base(filepath.Dir(keyPath)), filepath.Base(keyPath))
+					}
+				}
+				if !reflect.DeepEqual(commitArgs, tt.wantCommitArgs) {
+					t.Errorf("TestGenerateGitops() error: expected commit %v, got %v", tt.wantCommitArgs, commitArgs)
+				}
+			}
+			if !tt.wantErr && !reflect.DeepEqual(tt.component.Status.GitOps.PullRequest, tt.wantPullRequest) {
+				t.Errorf("TestGenerateGitops() error: expected pull request %v, got %v", tt.wantPullRequest, tt.component.Status.GitOps.PullRequest)
+			}
+			if !tt.wantErr && tt.wantPullRequest.URL != "" && tt.component.Status.GitOps.CommitID != "" {
+				t.Errorf("TestGenerateGitops() error: expected no commit ID before the pull request is merged, got %v", tt.component.Status.GitOps.CommitID)
+			}
+			wantWebhookSecret := &appstudiov1alpha1.SecretKeyReference{Name: tt.component.Name + "-webhook-secret", Key: appservicegitops.BuildWebhookSecretKey}
+			if !tt.wantErr && !reflect.DeepEqual(tt.component.Status.WebhookSecret, wantWebhookSecret) {
+				t.Errorf("TestGenerateGitops() error: expected webhook secret %v, got %v", wantWebhookSecret, tt.component.Status.WebhookSecret)
+			}
+			if !tt.wantErr && (tt.component.Status.BuildPipeline.Name == "" || tt.component.Status.BuildPipeline.Reason == "") {
+				t.Errorf("TestGenerateGitops() error: expected the selected build pipeline in the status, got %v", tt.component.Status.BuildPipeline)
+			}
+		})
+	}
+
+}
+
+func TestSubmitInitialBuild(t *testing.T) {
+	ctx := context.Background()
+
+	// The build pipeline run is owned by the component, so both types must be registered
+	if err := appstudiov1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
+	if err := tektonapi.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the tekton types to the scheme: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		pipelineRun string
+		annotations map[string]string
+		// existingBuild creates the next build pipeline run before the reconcile, as a failed reconcile would
+		existingBuild   bool
+		wantBuild       bool
+		wantAnnotations map[string]string
+	}{
+		{
+			name:      "Build is submitted for a new component",
+			wantBuild: true,
+		},
+		{
+			name:        "Build is not submitted again",
+			pipelineRun: "test-component-abcde",
+		},
+		{
+			name:            "Rebuild is submitted on request",
+			pipelineRun:     "test-component-abcde",
+			annotations:     map[string]string{appservicegitops.RebuildAnnotation: "1", "other": "value"},
+			wantBuild:       true,
+			wantAnnotations: map[string]string{"other": "value"},
+		},
+		{
+			name:          "Build created by a failed reconcile is recorded",
+			existingBuild: true,
+			wantBuild:     true,
+		},
+		{
+			name:          "Rebuild created by a failed reconcile is recorded",
+			pipelineRun:   "test-component-abcde",
+			existingBuild: true,
+			wantBuild:     true,
+		},
+		{
+			name:          "Rebuild created by a failed reconcile is not submitted twice",
+			pipelineRun:   "test-component-abcde",
+			annotations:   map[string]string{appservicegitops.RebuildAnnotation: "1"},
+			existingBuild: true,
+			wantBuild:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-component",
+					Namespace:   "test-namespace",
+					UID:         "6f1d7c3e-2b4a-4f8e-9d0c-5a7b3e1f2c4d",
+					Annotations: tt.annotations,
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "test-component",
+					Application:    "test-application",
+					ContainerImage: "quay.io/test/test-image:latest",
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "https://github.com/test/test-repo",
+							},
+						},
+					},
+				},
+				Status: appstudiov1alpha1.ComponentStatus{
+					Build: appstudiov1alpha1.BuildStatus{PipelineRun: tt.pipelineRun},
+				},
+			}
+			objects := []runtime.Object{component.DeepCopy()}
+			if tt.existingBuild {
+				objects = append(objects, &tektonapi.PipelineRun{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            getBuildPipelineRunName(*component, tt.pipelineRun),
+						Namespace:       component.Namespace,
+						OwnerReferences: []metav1.OwnerReference{{Name: component.Name, UID: component.UID}},
+					},
+				})
+			}
+			fakeClient := fake.NewClientBuilder().WithRuntimeObjects(objects...).Build()
+			r := &ComponentReconciler{
+				Log:    ctrl.Log.WithName("controllers").WithName("Component"),
+				Scheme: scheme.Scheme,
+				Client: fakeClient,
+			}
+
+			// Reconcile the component as stored by the client
+			testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: component.Name, Namespace: component.Namespace}, component))
+			err := r.submitInitialBuild(ctx, component)
+			testutils.AssertNoError(t, err)
+
+			pipelineRuns := &tektonapi.PipelineRunList{}
+			testutils.AssertNoError(t, fakeClient.List(ctx, pipelineRuns))
+			if !tt.wantBuild {
+				if len(pipelineRuns.Items) != 0 || component.Status.Build.PipelineRun != tt.pipelineRun {
+					t.Fatalf("TestSubmitInitialBuild() error: expected no new build, got %d pipeline runs and %q in the status", len(pipelineRuns.Items), component.Status.Build.PipelineRun)
+				}
+				return
+			}
+			if len(pipelineRuns.Items) != 1 {
+				t.Fatalf("TestSubmitInitialBuild() error: expected a build pipeline run, got %d", len(pipelineRuns.Items))
+			}
+			pipelineRun := pipelineRuns.Items[0]
+			if component.Status.Build.PipelineRun != pipelineRun.Name || pipelineRun.Name == tt.pipelineRun {
+				t.Errorf("TestSubmitInitialBuild() error: expected the new pipeline run %q in the status, got %q", pipelineRun.Name, component.Status.Build.PipelineRun)
+			}
+			if len(pipelineRun.OwnerReferences) != 1 || pipelineRun.OwnerReferences[0].Name != component.Name {
+				t.Errorf("TestSubmitInitialBuild() error: expected the pipeline run to be owned by the component, got %v", pipelineRun.OwnerReferences)
+			}
+
+			updatedComponent := &appstudiov1alpha1.Component{}
+			testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: component.Name, Namespace: component.Namespace}, updatedComponent))
+			if updatedComponent.Status.Build.PipelineRun != pipelineRun.Name {
+				t.Errorf("TestSubmitInitialBuild() error: expected the recorded pipeline run %q, got %q", pipelineRun.Name, updatedComponent.Status.Build.PipelineRun)
+			}
+			if !reflect.DeepEqual(updatedComponent.Annotations, tt.wantAnnotations) {
+				t.Errorf("TestSubmitInitialBuild() error: expected the annotations %v, got %v", tt.wantAnnotations, updatedComponent.Annotations)
+			}
+		})
+	}
+}
+
+func TestEnsurePaCWebhookSecret(t *testing.T) {
+	ctx := context.Background()
+
+	if err := appstudiov1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
+
+	secretKey := "https___github.com_test_test-repo"
+
+	tests := []struct {
+		name          string
+		existingData  map[string][]byte
+		wantSecretKey string
+		wantKeys      []string
+	}{
+		{
+			name:     "Secret is created for the first component",
+			wantKeys: []string{secretKey},
+		},
+		{
+			name:         "Key is added to the existing secret",
+			existingData: map[string][]byte{"https___gitlab.com_test_other-repo": []byte("other")},
+			wantKeys:     []string{secretKey, "https___gitlab.com_test_other-repo"},
+		},
+		{
+			name:          "Existing key is kept",
+			existingData:  map[string][]byte{secretKey: []byte("configured")},
+			wantSecretKey: "configured",
+			wantKeys:      []string{secretKey},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "test-namespace",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: "test-component",
+					Application:   "test-application",
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "https://github.com/test/test-repo",
+							},
+						},
+					},
+				},
+			}
+			clientBuilder := fake.NewClientBuilder()
+			if tt.existingData != nil {
+				clientBuilder = clientBuilder.WithRuntimeObjects(&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      appservicegitops.PipelinesAsCodeWebhooksSecretName,
+						Namespace: "test-namespace",
+					},
+					Data: tt.existingData,
+				})
+			}
+			fakeClient := clientBuilder.Build()
+			r := &ComponentReconciler{
+				Log:    ctrl.Log.WithName("controllers").WithName("Component"),
+				Scheme: scheme.Scheme,
+				Client: fakeClient,
+			}
+
+			err := r.ensurePaCWebhookSecret(ctx, component)
+			testutils.AssertNoError(t, err)
+
+			wantReference := &appstudiov1alpha1.SecretKeyReference{Name: appservicegitops.PipelinesAsCodeWebhooksSecretName, Key: secretKey}
+			if !reflect.DeepEqual(component.Status.WebhookSecret, wantReference) {
+				t.Errorf("TestEnsurePaCWebhookSecret() error: expected the webhook secret reference %v, got %v", wantReference, component.Status.WebhookSecret)
+			}
+
+			webhookSecret := &corev1.Secret{}
+			testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: appservicegitops.PipelinesAsCodeWebhooksSecretName, Namespace: "test-namespace"}, webhookSecret))
+			if len(webhookSecret.Data) != len(tt.wantKeys) {
+				t.Errorf("TestEnsurePaCWebhookSecret() error: expected the keys %v, got %d keys", tt.wantKeys, len(webhookSecret.Data))
+			}
+			for _, key := range tt.wantKeys {
+				if len(webhookSecret.Data[key]) == 0 {
+					t.Errorf("TestEnsurePaCWebhookSecret() error: expected a value for the key %s", key)
+				}
+			}
+			if len(webhookSecret.OwnerReferences) != 0 {
+				t.Errorf("TestEnsurePaCWebhookSecret() error: expected the shared secret to have no owner, got %v", webhookSecret.OwnerReferences)
+			}
+			if tt.wantSecretKey != "" && string(webhookSecret.Data[secretKey]) != tt.wantSecretKey {
+				t.Errorf("TestEnsurePaCWebhookSecret() error: expected the existing secret %q to be kept, got %q", tt.wantSecretKey, string(webhookSecret.Data[secretKey]))
+			}
+		})
+	}
+}
+
+func TestRemovePaCWebhookSecret(t *testing.T) {
+	ctx := context.Background()
+
+	if err := appstudiov1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
+
+	getComponent := func(name, repoUrl string) *appstudiov1alpha1.Component {
+		return &appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-namespace",
+			},
+			Spec: appstudiov1alpha1.ComponentSpec{
+				ComponentName: name,
+				Application:   "test-application",
+				Source: appstudiov1alpha1.ComponentSource{
+					ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+						GitSource: &appstudiov1alpha1.GitSource{
+							URL: repoUrl,
+						},
+					},
+				},
+			},
+		}
+	}
+	secretKey := "https___github.com_test_test-repo"
+	otherKey := "https___gitlab.com_test_other-repo"
+
+	tests := []struct {
+		name            string
+		otherComponents []runtime.Object
+		existingData    map[string][]byte
+		wantKeys        []string
+	}{
+		{
+			name:         "Key of the component is removed",
+			existingData: map[string][]byte{secretKey: []byte("secret"), otherKey: []byte("other")},
+			wantKeys:     []string{otherKey},
+		},
+		{
+			name:            "Key shared with another component is kept",
+			otherComponents: []runtime.Object{getComponent("other-component", "https://github.com/test/test-repo.git")},
+			existingData:    map[string][]byte{secretKey: []byte("secret"), otherKey: []byte("other")},
+			wantKeys:        []string{secretKey, otherKey},
+		},
+		{
+			name: "Missing secret is ignored",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getComponent("test-component", "https://github.com/test/test-repo")
+			objects := append([]runtime.Object{component.DeepCopy()}, tt.otherComponents...)
+			if tt.existingData != nil {
+				objects = append(objects, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      appservicegitops.PipelinesAsCodeWebhooksSecretName,
+						Namespace: "test-namespace",
+					},
+					Data: tt.existingData,
+				})
+			}
+			fakeClient := fake.NewClientBuilder().WithRuntimeObjects(objects...).Build()
+			r := &ComponentReconciler{
+				Log:    ctrl.Log.WithName("controllers").WithName("Component"),
+				Scheme: scheme.Scheme,
+				Client: fakeClient,
+			}
+
+			err := r.removePaCWebhookSecret(ctx, component)
+			testutils.AssertNoError(t, err)
+
+			if tt.existingData == nil {
+				return
+			}
+			webhookSecret := &corev1.Secret{}
+			testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: appservicegitops.PipelinesAsCodeWebhooksSecretName, Namespace: "test-namespace"}, webhookSecret))
+			if len(webhookSecret.Data) != len(tt.wantKeys) {
+				t.Errorf("TestRemovePaCWebhookSecret() error: expected the keys %v, got %d keys", tt.wantKeys, len(webhookSecret.Data))
+			}
+			for _, key := range tt.wantKeys {
+				if len(webhookSecret.Data[key]) == 0 {
+					t.Errorf("TestRemovePaCWebhookSecret() error: expected a value for the key %s", key)
+				}
+			}
 		})
 	}
+}
+
+func TestOpenPaCOnboardingPullRequest(t *testing.T) {
+	pacCredentials := map[string][]byte{"github.token": []byte("ghp_token")}
 
+	tests := []struct {
+		name            string
+		componentName   string
+		repoURL         string
+		credentials     map[string][]byte
+		wantPullRequest appstudiov1alpha1.GitOpsPullRequestStatus
+		wantCondition   metav1.ConditionStatus
+		wantReason      string
+	}{
+		{
+			name:          "Pull request is opened against the GitHub repository",
+			componentName: "test-component",
+			repoURL:       "https://github.com/redhat-appstudio-appdata/test-repo-1",
+			credentials:   pacCredentials,
+			wantPullRequest: appstudiov1alpha1.GitOpsPullRequestStatus{
+				URL:    "https://github.com/redhat-appstudio-appdata/test-repo-1/pull/2",
+				Number: 2,
+				Branch: "appstudio-test-component",
+				State:  "open",
+			},
+			wantCondition: metav1.ConditionTrue,
+			wantReason:    "OK",
+		},
+		{
+			name:          "Pull request is opened for a branch pushed by an earlier reconcile",
+			componentName: "pushed-component",
+			repoURL:       "https://github.com/redhat-appstudio-appdata/test-repo-1",
+			credentials:   pacCredentials,
+			wantPullRequest: appstudiov1alpha1.GitOpsPullRequestStatus{
+				URL:    "https://github.com/redhat-appstudio-appdata/test-repo-1/pull/2",
+				Number: 2,
+				Branch: "appstudio-pushed-component",
+				State:  "open",
+			},
+			wantCondition: metav1.ConditionTrue,
+			wantReason:    "OK",
+		},
+		{
+			name:          "Pull request is skipped for other git providers",
+			componentName: "test-component",
+			repoURL:       "https://gitlab.com/redhat-appstudio-appdata/test-repo-1",
+			credentials:   pacCredentials,
+			wantCondition: metav1.ConditionFalse,
+			wantReason:    "UnsupportedGitProvider",
+		},
+		{
+			name:          "Failure is reported without a GitHub token in the Pipelines as Code secret",
+			componentName: "test-component",
+			repoURL:       "https://github.com/redhat-appstudio-appdata/test-repo-1",
+			wantCondition: metav1.ConditionFalse,
+			wantReason:    pacOnboardingFailedReason,
+		},
+		{
+			name:          "Failure is reported when the pull request cannot be opened",
+			componentName: "test-error-response",
+			repoURL:       "https://github.com/redhat-appstudio-appdata/test-repo-1",
+			credentials:   pacCredentials,
+			wantCondition: metav1.ConditionFalse,
+			wantReason:    pacOnboardingFailedReason,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        tt.componentName,
+					Namespace:   "test-namespace",
+					Annotations: map[string]string{appservicegitops.PaCAnnotation: "1"},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  tt.componentName,
+					Application:    "test-application",
+					ContainerImage: "quay.io/test/test-image",
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: tt.repoURL,
+							},
+						},
+					},
+				},
+			}
+			r := &ComponentReconciler{
+				Log:    ctrl.Log.WithName("controllers").WithName("Component"),
+				Client: fake.NewClientBuilder().Build(),
+			}
+			// The clients authenticated with the token of the Pipelines as Code secret send their requests to the mocked GitHub API
+			ctx := context.WithValue(context.Background(), oauth2.HTTPClient, github.GetMockedClient().Client())
+
+			r.ensurePaCOnboardingPullRequest(ctx, component, prepare.GitopsConfig{PipelinesAsCodeCredentials: tt.credentials})
+			if !reflect.DeepEqual(component.Status.Build.OnboardingPullRequest, tt.wantPullRequest) {
+				t.Errorf("TestOpenPaCOnboardingPullRequest() error: expected the pull request %v, got %v", tt.wantPullRequest, component.Status.Build.OnboardingPullRequest)
+			}
+			if condition := meta.FindStatusCondition(component.Status.Conditions, pacOnboardingConditionType); condition == nil || condition.Status != tt.wantCondition || condition.Reason != tt.wantReason {
+				t.Errorf("TestOpenPaCOnboardingPullRequest() error: expected the %s condition %s with the reason %s, got %v", pacOnboardingConditionType, tt.wantCondition, tt.wantReason, condition)
+			}
+			if isPaCOnboardingFailed(*component) != (tt.wantReason == pacOnboardingFailedReason) {
+				t.Errorf("TestOpenPaCOnboardingPullRequest() error: expected the failure to be retried: %v", tt.wantReason == pacOnboardingFailedReason)
+			}
+		})
+	}
+}
+
+func TestRetryPaCOnboardingPullRequest(t *testing.T) {
+	if err := appstudiov1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
+
+	failedCondition := metav1.Condition{
+		Type:    pacOnboardingConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  pacOnboardingFailedReason,
+		Message: "Unable to open the Pipelines as Code onboarding pull request",
+	}
+	tests := []struct {
+		name          string
+		annotations   map[string]string
+		wantCondition *metav1.Condition
+	}{
+		{
+			name:        "Pull request is opened once the token is configured",
+			annotations: map[string]string{appservicegitops.PaCAnnotation: "1"},
+			wantCondition: &metav1.Condition{
+				Type:   pacOnboardingConditionType,
+				Status: metav1.ConditionTrue,
+				Reason: "OK",
+			},
+		},
+		{
+			name: "Failure is forgotten once the component is no longer built with Pipelines as Code",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-component",
+					Namespace:   "test-namespace",
+					Annotations: tt.annotations,
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "test-component",
+					Application:    "test-application",
+					ContainerImage: "quay.io/test/test-image",
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "https://github.com/redhat-appstudio-appdata/test-repo-1",
+							},
+						},
+					},
+				},
+				Status: appstudiov1alpha1.ComponentStatus{
+					Conditions: []metav1.Condition{failedCondition},
+				},
+			}
+			pacSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      prepare.PipelinesAsCodeSecretName,
+					Namespace: "test-namespace",
+				},
+				Data: map[string][]byte{"github.token": []byte("ghp_token")},
+			}
+			fakeClient := fake.NewClientBuilder().WithRuntimeObjects(component.DeepCopy(), pacSecret).Build()
+			r := &ComponentReconciler{
+				Log:    ctrl.Log.WithName("controllers").WithName("Component"),
+				Scheme: scheme.Scheme,
+				Client: fakeClient,
+			}
+			ctx := context.WithValue(context.Background(), oauth2.HTTPClient, github.GetMockedClient().Client())
+
+			testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: component.Name, Namespace: component.Namespace}, component))
+			err := r.retryPaCOnboardingPullRequest(ctx, component)
+			testutils.AssertNoError(t, err)
+
+			updatedComponent := &appstudiov1alpha1.Component{}
+			testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: component.Name, Namespace: component.Namespace}, updatedComponent))
+			condition := meta.FindStatusCondition(updatedComponent.Status.Conditions, pacOnboardingConditionType)
+			if tt.wantCondition == nil {
+				if condition != nil {
+					t.Errorf("TestRetryPaCOnboardingPullRequest() error: expected no %s condition, got %v", pacOnboardingConditionType, condition)
+				}
+				return
+			}
+			if condition == nil || condition.Status != tt.wantCondition.Status || condition.Reason != tt.wantCondition.Reason {
+				t.Errorf("TestRetryPaCOnboardingPullRequest() error: expected the condition %v, got %v", tt.wantCondition, condition)
+			}
+			if updatedComponent.Status.Build.OnboardingPullRequest.Number != 2 {
+				t.Errorf("TestRetryPaCOnboardingPullRequest() error: expected the pull request 2, got %v", updatedComponent.Status.Build.OnboardingPullRequest)
+			}
+		})
+	}
+}
+
+func TestRefreshOnboardingPullRequestState(t *testing.T) {
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, github.GetMockedClient().Client())
+
+	if err := appstudiov1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
+
+	component := &appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-component",
+			Namespace: "test-namespace",
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			ComponentName: "test-component",
+			Application:   "test-application",
+			Source: appstudiov1alpha1.ComponentSource{
+				ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+					GitSource: &appstudiov1alpha1.GitSource{
+						URL: "https://github.com/redhat-appstudio-appdata/test-repo-1",
+					},
+				},
+			},
+		},
+		Status: appstudiov1alpha1.ComponentStatus{
+			Build: appstudiov1alpha1.BuildStatus{
+				OnboardingPullRequest: appstudiov1alpha1.GitOpsPullRequestStatus{Number: 3, State: "open"},
+			},
+		},
+	}
+	pacSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      prepare.PipelinesAsCodeSecretName,
+			Namespace: "test-namespace",
+		},
+		Data: map[string][]byte{"github.token": []byte("ghp_token")},
+	}
+	fakeClient := fake.NewClientBuilder().WithRuntimeObjects(component.DeepCopy(), pacSecret).Build()
+	r := &ComponentReconciler{
+		Log:    ctrl.Log.WithName("controllers").WithName("Component"),
+		Scheme: scheme.Scheme,
+		Client: fakeClient,
+	}
+
+	testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: component.Name, Namespace: component.Namespace}, component))
+	err := r.refreshOnboardingPullRequestState(ctx, component)
+	testutils.AssertNoError(t, err)
+
+	updatedComponent := &appstudiov1alpha1.Component{}
+	testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: component.Name, Namespace: component.Namespace}, updatedComponent))
+	if updatedComponent.Status.Build.OnboardingPullRequest.State != "merged" {
+		t.Errorf("TestRefreshOnboardingPullRequestState() error: expected the merged state, got %q", updatedComponent.Status.Build.OnboardingPullRequest.State)
+	}
+}
+
+func TestRefreshPullRequestState(t *testing.T) {
+	ctx := context.Background()
+
+	if err := appstudiov1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
+
+	component := &appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-component",
+			Namespace: "test-namespace",
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			ComponentName: "test-component",
+			Application:   "test-application",
+		},
+		Status: appstudiov1alpha1.ComponentStatus{
+			GitOps: appstudiov1alpha1.GitOpsStatus{
+				RepositoryURL: "https://github.com/redhat-appstudio-appdata/test-repo-1",
+				CommitID:      "ca82a6dff817ec66f44342007202690a93763949",
+				PullRequest:   appstudiov1alpha1.GitOpsPullRequestStatus{Number: 3, State: "open"},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithRuntimeObjects(component.DeepCopy()).Build()
+	r := &ComponentReconciler{
+		Log:          ctrl.Log.WithName("controllers").WithName("Component"),
+		Scheme:       scheme.Scheme,
+		Client:       fakeClient,
+		GitHubClient: github.GetMockedClient(),
+	}
+
+	testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: component.Name, Namespace: component.Namespace}, component))
+	err := r.refreshPullRequestState(ctx, component)
+	testutils.AssertNoError(t, err)
+
+	updatedComponent := &appstudiov1alpha1.Component{}
+	testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: component.Name, Namespace: component.Namespace}, updatedComponent))
+	if updatedComponent.Status.GitOps.PullRequest.State != "merged" {
+		t.Errorf("TestRefreshPullRequestState() error: expected the merged state, got %q", updatedComponent.Status.GitOps.PullRequest.State)
+	}
+	if updatedComponent.Status.GitOps.CommitID != "a4c2d1e7b3f5968a0d2c4e6f8b1a3c5d7e9f0b2d" {
+		t.Errorf("TestRefreshPullRequestState() error: expected the merge commit as the commit ID, got %q", updatedComponent.Status.GitOps.CommitID)
+	}
+}
+
+func TestUpdateBuildStatus(t *testing.T) {
+	ctx := context.Background()
+
+	if err := appstudiov1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
+	if err := tektonapi.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the tekton types to the scheme: %v", err)
+	}
+
+	startTime := metav1.NewTime(time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC))
+	completionTime := metav1.NewTime(time.Date(2022, 8, 1, 10, 5, 0, 0, time.UTC))
+	newPipelineRun := func(name string, created time.Time, component string, succeeded *apis.Condition) *tektonapi.PipelineRun {
+		pipelineRun := &tektonapi.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "test-namespace",
+				CreationTimestamp: metav1.NewTime(created),
+				Labels:            map[string]string{appservicegitops.BuildComponentLabel: component},
+			},
+			Spec: tektonapi.PipelineRunSpec{
+				Params: []tektonapi.Param{
+					{Name: "revision", Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: "main"}},
+				},
+			},
+		}
+		if succeeded != nil {
+			pipelineRun.Status.StartTime = &startTime
+			pipelineRun.Status.SetCondition(succeeded)
+		}
+		if succeeded != nil && succeeded.Status != corev1.ConditionUnknown {
+			pipelineRun.Status.CompletionTime = &completionTime
+		}
+		if succeeded != nil && succeeded.Status == corev1.ConditionTrue {
+			pipelineRun.Status.PipelineResults = []tektonapi.PipelineRunResult{
+				{Name: "IMAGE_DIGEST", Value: "sha256:abcdef"},
+				{Name: "CHAINS-GIT_COMMIT", Value: "0123456789abcdef"},
+			}
+		}
+		return pipelineRun
+	}
+	withLabel := func(pipelineRun *tektonapi.PipelineRun, key string, value string) *tektonapi.PipelineRun {
+		pipelineRun.Labels[key] = value
+		return pipelineRun
+	}
+	earlier := time.Date(2022, 8, 1, 9, 0, 0, 0, time.UTC)
+	later := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		pipelineRuns  []runtime.Object
+		wantLastBuild *appstudiov1alpha1.BuildRunStatus
+		wantCondition *metav1.Condition
+	}{
+		{
+			name: "No builds",
+		},
+		{
+			name: "Pending build",
+			pipelineRuns: []runtime.Object{
+				newPipelineRun("test-component-abcde", later, "test-component", nil),
+			},
+			wantLastBuild: &appstudiov1alpha1.BuildRunStatus{Name: "test-component-abcde", Status: "Pending", Revision: "main"},
+		},
+		{
+			name: "Running build",
+			pipelineRuns: []runtime.Object{
+				newPipelineRun("test-component-abcde", later, "test-component", &apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionUnknown, Reason: "Running"}),
+			},
+			wantLastBuild: &appstudiov1alpha1.BuildRunStatus{Name: "test-component-abcde", Status: "Running", StartTime: &startTime, Revision: "main"},
+			wantCondition: &metav1.Condition{Type: "Built", Status: metav1.ConditionUnknown, Reason: "Running"},
+		},
+		{
+			name: "Last build succeeded",
+			pipelineRuns: []runtime.Object{
+				newPipelineRun("test-component-fghij", earlier, "test-component", &apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Reason: "Failed"}),
+				newPipelineRun("test-component-abcde", later, "test-component", &apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue, Reason: "Succeeded"}),
+				newPipelineRun("other-component-abcde", later.Add(time.Hour), "other-component", nil),
+			},
+			wantLastBuild: &appstudiov1alpha1.BuildRunStatus{
+				Name:           "test-component-abcde",
+				Status:         "Succeeded",
+				StartTime:      &startTime,
+				CompletionTime: &completionTime,
+				Revision:       "0123456789abcdef",
+				ImageDigest:    "sha256:abcdef",
+			},
+			wantCondition: &metav1.Condition{Type: "Built", Status: metav1.ConditionTrue, Reason: "Succeeded"},
+		},
+		{
+			name: "Last build failed",
+			pipelineRuns: []runtime.Object{
+				newPipelineRun("test-component-fghij", later, "test-component", &apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Reason: "Failed", Message: "task build failed"}),
+				newPipelineRun("test-component-abcde", earlier, "test-component", &apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue, Reason: "Succeeded"}),
+			},
+			wantLastBuild: &appstudiov1alpha1.BuildRunStatus{
+				Name:           "test-component-fghij",
+				Status:         "Failed",
+				StartTime:      &startTime,
+				CompletionTime: &completionTime,
+				Revision:       "main",
+			},
+			wantCondition: &metav1.Condition{Type: "Built", Status: metav1.ConditionFalse, Reason: "Failed", Message: "Build PipelineRun test-component-fghij failed: task build failed"},
+		},
+		{
+			name: "Pull request builds are ignored",
+			pipelineRuns: []runtime.Object{
+				newPipelineRun("test-component-abcde", earlier, "test-component", &apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue, Reason: "Succeeded"}),
+				withLabel(newPipelineRun("test-component-fghij", later, "test-component", nil), appservicegitops.BuildEventTypeLabel, appservicegitops.BuildPullRequestEventType),
+				withLabel(newPipelineRun("test-component-on-pull-request-klmno", later, "test-component", nil), "pipelinesascode.tekton.dev/event-type", "pull_request"),
+			},
+			wantLastBuild: &appstudiov1alpha1.BuildRunStatus{
+				Name:           "test-component-abcde",
+				Status:         "Succeeded",
+				StartTime:      &startTime,
+				CompletionTime: &completionTime,
+				Revision:       "0123456789abcdef",
+				ImageDigest:    "sha256:abcdef",
+			},
+			wantCondition: &metav1.Condition{Type: "Built", Status: metav1.ConditionTrue, Reason: "Succeeded"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "test-namespace",
+				},
+				Status: appstudiov1alpha1.ComponentStatus{
+					Conditions: []metav1.Condition{{Type: "Created", Status: metav1.ConditionTrue, Reason: "OK"}},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithRuntimeObjects(append(tt.pipelineRuns, component)...).Build()
+			r := &ComponentReconciler{
+				Log:    ctrl.Log.WithName("controllers").WithName("Component"),
+				Scheme: scheme.Scheme,
+				Client: fakeClient,
+			}
+
+			testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: component.Name, Namespace: component.Namespace}, component))
+			err := r.updateBuildStatus(ctx, component)
+			testutils.AssertNoError(t, err)
+
+			updatedComponent := &appstudiov1alpha1.Component{}
+			testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: component.Name, Namespace: component.Namespace}, updatedComponent))
+			if !equality.Semantic.DeepEqual(updatedComponent.Status.Build.LastBuild, tt.wantLastBuild) {
+				t.Errorf("TestUpdateBuildStatus() error: expected the last build %v, got %v", tt.wantLastBuild, updatedComponent.Status.Build.LastBuild)
+			}
+
+			condition := meta.FindStatusCondition(updatedComponent.Status.Conditions, "Built")
+			if (condition == nil) != (tt.wantCondition == nil) {
+				t.Fatalf("TestUpdateBuildStatus() error: expected the condition %v, got %v", tt.wantCondition, condition)
+			}
+			if condition != nil && (condition.Status != tt.wantCondition.Status || condition.Reason != tt.wantCondition.Reason ||
+				(tt.wantCondition.Message != "" && condition.Message != tt.wantCondition.Message)) {
+				t.Errorf("TestUpdateBuildStatus() error: expected the condition %v, got %v", tt.wantCondition, condition)
+			}
+			// The outcome of the last reconcile is not affected by the build
+			if !isComponentReconciled(*updatedComponent) {
+				t.Errorf("TestUpdateBuildStatus() error: expected the component to remain reconciled")
+			}
+		})
+	}
+}
+
+func TestIsComponentReconciled(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []metav1.Condition
+		want       bool
+	}{
+		{
+			name: "No conditions",
+			want: false,
+		},
+		{
+			name: "Created",
+			conditions: []metav1.Condition{
+				{Type: "GitOpsResourcesGenerated", Status: metav1.ConditionTrue},
+				{Type: "Created", Status: metav1.ConditionTrue},
+			},
+			want: true,
+		},
+		{
+			name: "Update failed after a failed build",
+			conditions: []metav1.Condition{
+				{Type: "Created", Status: metav1.ConditionTrue},
+				{Type: "Built", Status: metav1.ConditionFalse},
+				{Type: "Updated", Status: metav1.ConditionFalse},
+			},
+			want: false,
+		},
+		{
+			name: "Created before a failed build",
+			conditions: []metav1.Condition{
+				{Type: "Created", Status: metav1.ConditionTrue},
+				{Type: "Built", Status: metav1.ConditionFalse},
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := appstudiov1alpha1.Component{Status: appstudiov1alpha1.ComponentStatus{Conditions: tt.conditions}}
+			if got := isComponentReconciled(component); got != tt.want {
+				t.Errorf("isComponentReconciled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsureImageRepository(t *testing.T) {
+	ctx := context.Background()
+
+	if err := appstudiov1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		scope            string
+		existingSecret   *corev1.Secret
+		wantRepository   string
+		wantSecretName   string
+		wantSecretOwners int
+	}{
+		{
+			name:             "Repository is provisioned for the component",
+			scope:            imageregistry.ComponentScope,
+			wantRepository:   "test-namespace-test-component",
+			wantSecretName:   "test-component-image-push",
+			wantSecretOwners: 1,
+		},
+		{
+			name:           "Repository is provisioned for the application",
+			scope:          imageregistry.ApplicationScope,
+			wantRepository: "test-namespace-test-application",
+			wantSecretName: "test-application-image-push",
+		},
+		{
+			name:  "Outdated push secret is updated",
+			scope: imageregistry.ApplicationScope,
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-application-image-push",
+					Namespace: "test-namespace",
+				},
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {}}`)},
+			},
+			wantRepository: "test-namespace-test-application",
+			wantSecretName: "test-application-image-push",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "test-namespace",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: "test-component",
+					Application:   "test-application",
+				},
+			}
+			clientBuilder := fake.NewClientBuilder()
+			if tt.existingSecret != nil {
+				clientBuilder = clientBuilder.WithRuntimeObjects(tt.existingSecret)
+			}
+			fakeClient := clientBuilder.Build()
+			registry := imageregistry.NewMockImageRegistry()
+			r := &ComponentReconciler{
+				Log:                  ctrl.Log.WithName("controllers").WithName("Component"),
+				Scheme:               scheme.Scheme,
+				Client:               fakeClient,
+				ImageRegistry:        registry,
+				ImageRepositoryScope: tt.scope,
+			}
+
+			imageRepository, err := r.ensureImageRepository(ctx, component)
+			testutils.AssertNoError(t, err)
+
+			wantStatus := appstudiov1alpha1.ImageRepositoryStatus{
+				URL:        imageregistry.MockRegistryHost + "/" + tt.wantRepository,
+				PushSecret: tt.wantSecretName,
+			}
+			if imageRepository == nil || *imageRepository != wantStatus {
+				t.Errorf("ensureImageRepository() = %v, want %v", imageRepository, wantStatus)
+			}
+			if !registry.Repositories[tt.wantRepository] {
+				t.Errorf("ensureImageRepository() did not provision the repository %s", tt.wantRepository)
+			}
+
+			pushSecret := &corev1.Secret{}
+			testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: tt.wantSecretName, Namespace: "test-namespace"}, pushSecret))
+			wantDockerConfig, _ := registry.Credentials[tt.wantRepository].DockerConfigJSON()
+			if pushSecret.Type != corev1.SecretTypeDockerConfigJson || string(pushSecret.Data[corev1.DockerConfigJsonKey]) != string(wantDockerConfig) {
+				t.Errorf("ensureImageRepository() stored wrong credentials in the push secret: %s", pushSecret.Data[corev1.DockerConfigJsonKey])
+			}
+			if len(pushSecret.OwnerReferences) != tt.wantSecretOwners {
+				t.Errorf("ensureImageRepository() set %d owners on the push secret, want %d", len(pushSecret.OwnerReferences), tt.wantSecretOwners)
+			}
+		})
+	}
+}
+
+func TestRemoveImageRepository(t *testing.T) {
+	ctx := context.Background()
+
+	if err := appstudiov1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
+
+	repositoryURL := imageregistry.MockRegistryHost + "/test-namespace-test-application"
+	getComponent := func(name string, imageRepository *appstudiov1alpha1.ImageRepositoryStatus) *appstudiov1alpha1.Component {
+		return &appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-namespace",
+			},
+			Spec: appstudiov1alpha1.ComponentSpec{
+				ComponentName: name,
+				Application:   "test-application",
+			},
+			Status: appstudiov1alpha1.ComponentStatus{
+				ImageRepository: imageRepository,
+			},
+		}
+	}
+	imageRepository := &appstudiov1alpha1.ImageRepositoryStatus{URL: repositoryURL, PushSecret: "test-application-image-push"}
+
+	tests := []struct {
+		name           string
+		component      *appstudiov1alpha1.Component
+		otherComponent *appstudiov1alpha1.Component
+		wantRemoved    bool
+	}{
+		{
+			name:        "Repository of the last component is removed",
+			component:   getComponent("test-component", imageRepository),
+			wantRemoved: true,
+		},
+		{
+			name:           "Repository still used by another component is kept",
+			component:      getComponent("test-component", imageRepository),
+			otherComponent: getComponent("other-component", imageRepository),
+		},
+		{
+			name:           "Repository is removed when the other components use other repositories",
+			component:      getComponent("test-component", imageRepository),
+			otherComponent: getComponent("other-component", &appstudiov1alpha1.ImageRepositoryStatus{URL: imageregistry.MockRegistryHost + "/other"}),
+			wantRemoved:    true,
+		},
+		{
+			name:      "Components without provisioned repository are ignored",
+			component: getComponent("test-component", nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []runtime.Object{
+				tt.component,
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-application-image-push",
+						Namespace: "test-namespace",
+					},
+				},
+			}
+			if tt.otherComponent != nil {
+				objects = append(objects, tt.otherComponent)
+			}
+			fakeClient := fake.NewClientBuilder().WithRuntimeObjects(objects...).Build()
+			registry := imageregistry.NewMockImageRegistry()
+			_, err := registry.EnsureRepository(ctx, "test-namespace-test-application")
+			testutils.AssertNoError(t, err)
+			_, err = registry.EnsurePushCredentials(ctx, "test-namespace-test-application")
+			testutils.AssertNoError(t, err)
+			r := &ComponentReconciler{
+				Log:                  ctrl.Log.WithName("controllers").WithName("Component"),
+				Scheme:               scheme.Scheme,
+				Client:               fakeClient,
+				ImageRegistry:        registry,
+				ImageRepositoryScope: imageregistry.ApplicationScope,
+			}
+
+			err = r.removeImageRepository(ctx, tt.component)
+			testutils.AssertNoError(t, err)
+
+			_, credentialsFound := registry.Credentials["test-namespace-test-application"]
+			if registry.Repositories["test-namespace-test-application"] == tt.wantRemoved || credentialsFound == tt.wantRemoved {
+				t.Errorf("removeImageRepository() removed the repository: %v, want %v", !registry.Repositories["test-namespace-test-application"], tt.wantRemoved)
+			}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "test-application-image-push", Namespace: "test-namespace"}, &corev1.Secret{})
+			if k8sErrors.IsNotFound(err) != tt.wantRemoved {
+				t.Errorf("removeImageRepository() removed the push secret: %v, want %v", k8sErrors.IsNotFound(err), tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestReconcileDeletedComponent(t *testing.T) {
+	ctx := context.Background()
+
+	if err := appstudiov1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
+
+	repositoryName := "test-namespace-test-component"
+	getComponent := func(imageRepository *appstudiov1alpha1.ImageRepositoryStatus) *appstudiov1alpha1.Component {
+		component := &appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-component",
+				Namespace:         "test-namespace",
+				Finalizers:        []string{compFinalizerName},
+				DeletionTimestamp: &metav1.Time{Time: time.Now()},
+			},
+			Spec: appstudiov1alpha1.ComponentSpec{
+				ComponentName: "test-component",
+				Application:   "test-application",
+				Source: appstudiov1alpha1.ComponentSource{
+					ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+						GitSource: &appstudiov1alpha1.GitSource{
+							URL: "https://github.com/test/test-repo",
+						},
+					},
+				},
+			},
+			Status: appstudiov1alpha1.ComponentStatus{
+				ImageRepository: imageRepository,
+			},
+		}
+		if imageRepository != nil {
+			component.Spec.ContainerImage = imageRepository.URL
+		}
+		return component
+	}
+
+	tests := []struct {
+		name      string
+		component *appstudiov1alpha1.Component
+	}{
+		{
+			name:      "Repository of a component that failed to reconcile is removed",
+			component: getComponent(&appstudiov1alpha1.ImageRepositoryStatus{URL: imageregistry.MockRegistryHost + "/" + repositoryName, PushSecret: "test-component-image-push"}),
+		},
+		{
+			name:      "No repository is provisioned for a component deleted before its image was set",
+			component: getComponent(nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithRuntimeObjects(tt.component).Build()
+			registry := imageregistry.NewMockImageRegistry()
+			if tt.component.Status.ImageRepository != nil {
+				_, err := registry.EnsureRepository(ctx, repositoryName)
+				testutils.AssertNoError(t, err)
+				_, err = registry.EnsurePushCredentials(ctx, repositoryName)
+				testutils.AssertNoError(t, err)
+			}
+			r := &ComponentReconciler{
+				Log:           ctrl.Log.WithName("controllers").WithName("Component"),
+				Scheme:        scheme.Scheme,
+				Client:        fakeClient,
+				ImageRegistry: registry,
+			}
+
+			result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-component", Namespace: "test-namespace"}})
+			testutils.AssertNoError(t, err)
+			if result.Requeue {
+				t.Errorf("Reconcile() requeued the deleted component")
+			}
+
+			if len(registry.Repositories) != 0 || len(registry.Credentials) != 0 {
+				t.Errorf("Reconcile() left the repositories %v and the credentials %v in the registry", registry.Repositories, registry.Credentials)
+			}
+			// The component is gone once its finalizer is removed
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "test-component", Namespace: "test-namespace"}, &appstudiov1alpha1.Component{})
+			if !k8sErrors.IsNotFound(err) {
+				t.Errorf("Reconcile() did not remove the finalizer of the component: %v", err)
+			}
+		})
+	}
+}
+
+func TestIsBuildBundleChanged(t *testing.T) {
+	ctx := context.Background()
+
+	if err := appstudiov1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      prepare.BuildBundleConfigMapName,
+			Namespace: "test-namespace",
+		},
+		Data: map[string]string{prepare.BuildBundleConfigMapKey: "quay.io/org/bundle:2"},
+	}
+
+	tests := []struct {
+		name          string
+		bundle        string
+		buildPipeline *appstudiov1alpha1.BuildPipelineOverride
+		want          bool
+	}{
+		{
+			name:   "Bundle rolled out in the ConfigMap",
+			bundle: "quay.io/org/bundle:1",
+			want:   true,
+		},
+		{
+			name:   "Bundle unchanged",
+			bundle: "quay.io/org/bundle:2",
+		},
+		{
+			name:          "Bundle pinned by the component",
+			bundle:        "quay.io/org/bundle:2",
+			buildPipeline: &appstudiov1alpha1.BuildPipelineOverride{Bundle: "quay.io/org/bundle@sha256:abc"},
+			want:          true,
+		},
+		{
+			name: "Build resources not generated yet",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "test-namespace",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					BuildPipeline: tt.buildPipeline,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "https://github.com/test/test-repo",
+							},
+						},
+					},
+				},
+				Status: appstudiov1alpha1.ComponentStatus{
+					BuildPipeline: appstudiov1alpha1.BuildPipelineStatus{Bundle: tt.bundle},
+				},
+			}
+			r := &ComponentReconciler{
+				Log:    ctrl.Log.WithName("controllers").WithName("Component"),
+				Scheme: scheme.Scheme,
+				Client: fake.NewClientBuilder().WithRuntimeObjects(configMap).Build(),
+			}
+
+			if got := r.isBuildBundleChanged(ctx, component); got != tt.want {
+				t.Errorf("isBuildBundleChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBuildBundleDigest(t *testing.T) {
+	r := &ComponentReconciler{
+		Log: ctrl.Log.WithName("controllers").WithName("Component"),
+	}
+
+	if got := r.getBuildBundleDigest(context.Background(), "quay.io/org/bundle:1@sha256:abc"); got != "sha256:abc" {
+		t.Errorf("getBuildBundleDigest() = %s, want sha256:abc", got)
+	}
+	if got := r.getBuildBundleDigest(context.Background(), "quay.io/org/bundle:1"); got != "" {
+		t.Errorf("getBuildBundleDigest() = %s, want an empty digest without resolver", got)
+	}
+
+	// The resolved digests are cached
+	requests := 0
+	registry := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.Header().Set("Docker-Content-Digest", "sha256:def")
+	}))
+	defer registry.Close()
+	r.DigestResolver = &imageregistry.DigestResolver{HTTPClient: registry.Client()}
+	bundle := strings.TrimPrefix(registry.URL, "https://") + "/org/bundle:1"
+	for i := 0; i < 2; i++ {
+		if got := r.getBuildBundleDigest(context.Background(), bundle); got != "sha256:def" {
+			t.Errorf("getBuildBundleDigest() = %s, want sha256:def", got)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("getBuildBundleDigest() sent %d requests to the registry, want 1", requests)
+	}
+	if _, ok := r.bundleDigests.get(bundle, time.Now().Add(bundleDigestCacheTTL+time.Second)); ok {
+		t.Errorf("getBuildBundleDigest() cached the digest for longer than %s", bundleDigestCacheTTL)
+	}
+}
+
+func TestGetBuildWorkflow(t *testing.T) {
+	tests := []struct {
+		name         string
+		gitopsConfig prepare.GitopsConfig
+		wantWorkflow string
+		wantReason   string
+	}{
+		{
+			name:         "HACBS workflow detected from an APIBinding",
+			gitopsConfig: prepare.GitopsConfig{IsHACBS: true, HACBSReason: prepare.HACBSAPIBindingReason},
+			wantWorkflow: prepare.HACBSWorkflow,
+			wantReason:   prepare.HACBSAPIBindingReason,
+		},
+		{
+			name:         "default workflow",
+			gitopsConfig: prepare.GitopsConfig{},
+			wantWorkflow: prepare.AppStudioWorkflow,
+			wantReason:   prepare.DefaultWorkflowReason,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow, reason := getBuildWorkflow(tt.gitopsConfig)
+			if workflow != tt.wantWorkflow || reason != tt.wantReason {
+				t.Errorf("getBuildWorkflow() = %s, %s, want %s, %s", workflow, reason, tt.wantWorkflow, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestEnsureRegistryAuthSecret(t *testing.T) {
+	ctx := context.Background()
+
+	if err := appstudiov1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
+
+	pushSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-component-image-push", Namespace: "test-namespace"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"quay.io": {"auth": "cHVzaA=="}}}`)},
+	}
+	dockerCfgSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mirror", Namespace: "test-namespace"},
+		Type:       corev1.SecretTypeDockercfg,
+		Data:       map[string][]byte{corev1.DockerConfigKey: []byte(`{"quay.io": {"auth": "b3RoZXI="}, "mirror.io": {"auth": "bWlycm9y"}}`)},
+	}
+	opaqueSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "opaque", Namespace: "test-namespace"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+
+	tests := []struct {
+		name            string
+		registrySecrets []string
+		existingSecret  *corev1.Secret
+		wantSecret      bool
+		wantDockerCfg   string
+		wantErr         bool
+	}{
+		{
+			name:            "Secrets are merged, the first one winning",
+			registrySecrets: []string{"mirror"},
+			wantSecret:      true,
+			wantDockerCfg:   `{"auths":{"mirror.io":{"auth": "bWlycm9y"},"quay.io":{"auth": "cHVzaA=="}}}`,
+		},
+		{
+			name:            "Outdated merged secret is updated",
+			registrySecrets: []string{"mirror"},
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-component-registry-auth", Namespace: "test-namespace"},
+				Type:       corev1.SecretTypeDockerConfigJson,
+				Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {}}`)},
+			},
+			wantSecret:    true,
+			wantDockerCfg: `{"auths":{"mirror.io":{"auth": "bWlycm9y"},"quay.io":{"auth": "cHVzaA=="}}}`,
+		},
+		{
+			name:            "Single secret is not merged",
+			registrySecrets: []string{"test-component-image-push"},
+		},
+		{
+			name:            "Missing secret",
+			registrySecrets: []string{"missing"},
+			wantErr:         true,
+		},
+		{
+			name:            "Secret without docker config",
+			registrySecrets: []string{"opaque"},
+			wantErr:         true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "test-namespace",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:    "test-component",
+					Application:      "test-application",
+					BuildCredentials: &appstudiov1alpha1.BuildCredentials{RegistrySecrets: tt.registrySecrets},
+				},
+				Status: appstudiov1alpha1.ComponentStatus{
+					ImageRepository: &appstudiov1alpha1.ImageRepositoryStatus{
+						URL:        "quay.io/org/test-namespace-test-component",
+						PushSecret: "test-component-image-push",
+					},
+				},
+			}
+			clientBuilder := fake.NewClientBuilder().WithRuntimeObjects(pushSecret.DeepCopy(), dockerCfgSecret.DeepCopy(), opaqueSecret.DeepCopy())
+			if tt.existingSecret != nil {
+				clientBuilder = clientBuilder.WithRuntimeObjects(tt.existingSecret)
+			}
+			fakeClient := clientBuilder.Build()
+			r := &ComponentReconciler{
+				Log:    ctrl.Log.WithName("controllers").WithName("Component"),
+				Scheme: scheme.Scheme,
+				Client: fakeClient,
+			}
+
+			err := r.ensureRegistryAuthSecret(ctx, component, prepare.GitopsConfig{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ensureRegistryAuthSecret() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			mergedSecret := &corev1.Secret{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "test-component-registry-auth", Namespace: "test-namespace"}, mergedSecret)
+			if !tt.wantSecret {
+				if err == nil {
+					t.Errorf("ensureRegistryAuthSecret() created the merged secret, want none")
+				}
+				return
+			}
+			testutils.AssertNoError(t, err)
+			var got, want interface{}
+			testutils.AssertNoError(t, json.Unmarshal(mergedSecret.Data[corev1.DockerConfigJsonKey], &got))
+			testutils.AssertNoError(t, json.Unmarshal([]byte(tt.wantDockerCfg), &want))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ensureRegistryAuthSecret() merged %s, want %s", mergedSecret.Data[corev1.DockerConfigJsonKey], tt.wantDockerCfg)
+			}
+			if mergedSecret.Type != corev1.SecretTypeDockerConfigJson {
+				t.Errorf("ensureRegistryAuthSecret() created a secret of type %s", mergedSecret.Type)
+			}
+			sources, err := getRegistryAuthSources(mergedSecret)
+			testutils.AssertNoError(t, err)
+			if len(sources) != 1+len(tt.registrySecrets) {
+				t.Errorf("ensureRegistryAuthSecret() recorded the merged secrets %v", sources)
+			}
+			if r.isRegistryAuthSecretStale(ctx, *component) {
+				t.Errorf("isRegistryAuthSecretStale() = true once merged")
+			}
+
+			// Rotating a merged secret makes the merged secret stale, until they are merged again
+			rotatedSecret := &corev1.Secret{}
+			testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "mirror", Namespace: "test-namespace"}, rotatedSecret))
+			rotatedSecret.Data = map[string][]byte{corev1.DockerConfigKey: []byte(`{"mirror.io": {"auth": "cm90YXRlZA=="}}`)}
+			testutils.AssertNoError(t, fakeClient.Update(ctx, rotatedSecret))
+			if !r.isRegistryAuthSecretStale(ctx, *component) {
+				t.Errorf("isRegistryAuthSecretStale() = false once a merged secret is rotated")
+			}
+			testutils.AssertNoError(t, r.ensureRegistryAuthSecret(ctx, component, prepare.GitopsConfig{}))
+			if r.isRegistryAuthSecretStale(ctx, *component) {
+				t.Errorf("isRegistryAuthSecretStale() = true once merged again")
+			}
+			testutils.AssertNoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "test-component-registry-auth", Namespace: "test-namespace"}, mergedSecret))
+			if !strings.Contains(string(mergedSecret.Data[corev1.DockerConfigJsonKey]), "cm90YXRlZA==") {
+				t.Errorf("ensureRegistryAuthSecret() merged %s, want the rotated credentials", mergedSecret.Data[corev1.DockerConfigJsonKey])
+			}
+		})
+	}
 }
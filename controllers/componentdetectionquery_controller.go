@@ -19,7 +19,9 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"os"
 	"path"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -107,10 +109,14 @@ func (r *ComponentDetectionQueryReconciler) Reconcile(ctx context.Context, req c
 
 		source := componentDetectionQuery.Spec.GitSource
 		var devfileBytes, dockerfileBytes []byte
-		var clonePath string
+		var clonePath, detectionPath string
 		devfilesMap := make(map[string][]byte)
 		devfilesURLMap := make(map[string]string)
 		dockerfileContextMap := make(map[string]string)
+		dockerfileCandidatesMap := make(map[string][]string)
+		var deploymentComponents []devfile.DeploymentComponent
+		// The components are only detected under the context, if set, of the repository
+		sourceContext := getSourceContext(source.Context)
 
 		if source.DevfileURL == "" {
 			isMultiComponent := false
@@ -128,11 +134,15 @@ func (r *ComponentDetectionQueryReconciler) Reconcile(ctx context.Context, req c
 					return ctrl.Result{}, nil
 				}
 
+				if sourceContext != "" {
+					gitURL = strings.TrimSuffix(gitURL, "/") + "/" + sourceContext
+				}
 				devfileBytes, dockerfileBytes = devfile.DownloadDevfileAndDockerfile(gitURL)
 			} else {
 				// Use SPI to retrieve the devfile from the private repository
 				// TODO - maysunfaisal also search for Dockerfile
-				devfileBytes, err = spi.DownloadDevfileUsingSPI(r.SPIClient, ctx, componentDetectionQuery.Namespace, source.URL, "main", "")
+				// The default branch of the repository is read if no revision is set
+				devfileBytes, err = spi.DownloadDevfileUsingSPI(r.SPIClient, ctx, componentDetectionQuery.Namespace, source.URL, source.Revision, sourceContext)
 				if err != nil {
 					log.Error(err, fmt.Sprintf("Unable to curl for any known devfile locations from %s %v", source.URL, req.NamespacedName))
 				}
@@ -149,47 +159,82 @@ func (r *ComponentDetectionQueryReconciler) Reconcile(ctx context.Context, req c
 				dockerfileContextMap["./"] = "./Dockerfile"
 			}
 
-			// Clone the repo if no dockerfile present
-			if !isDockerfilePresent {
-				log.Info(fmt.Sprintf("Unable to find devfile or Dockerfile under root directory, run Alizer to detect components... %v", req.NamespacedName))
+			scanOptions := devfile.ScanOptions{
+				MaxDepth:     componentDetectionQuery.Spec.ScanDepth,
+				IncludePaths: componentDetectionQuery.Spec.IncludePaths,
+				ExcludePaths: componentDetectionQuery.Spec.ExcludePaths,
+			}
+
+			// Clone the repo to detect the components, and the deployment descriptors, even if a dockerfile is present
+			log.Info(fmt.Sprintf("Cloning the repository to detect components... %v", req.NamespacedName))
+
+			clonePath, err = ioutils.CreateTempPath(componentDetectionQuery.Name, r.AppFS)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Unable to create a temp path %s for cloning %v", clonePath, req.NamespacedName))
+				r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, err)
+				return ctrl.Result{}, nil
+			}
 
-				clonePath, err = ioutils.CreateTempPath(componentDetectionQuery.Name, r.AppFS)
+			err = util.CloneRepo(clonePath, source.URL, source.Revision, gitToken)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Unable to clone repo %s to path %s, exiting reconcile loop %v", source.URL, clonePath, req.NamespacedName))
+				r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, err)
+				return ctrl.Result{}, nil
+			}
+			log.Info(fmt.Sprintf("cloned from %s to path %s... %v", source.URL, clonePath, req.NamespacedName))
+			detectionPath = path.Join(clonePath, sourceContext)
+			// The repository is cloned on the local disk, whatever the filesystem of the reconciler
+			if fileInfo, err := os.Stat(detectionPath); err != nil || !fileInfo.IsDir() {
+				err = fmt.Errorf("the context %s does not exist in the repository %s", sourceContext, source.URL)
+				log.Error(err, fmt.Sprintf("Unable to detect the components, exiting reconcile loop %v", req.NamespacedName))
+				r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, err)
+				return ctrl.Result{}, nil
+			}
+			var dockerfiles []string
+			if !isDevfilePresent {
+				// Look for a Containerfile or another Dockerfile variant, which are not downloaded from the root dir
+				dockerfiles, err = devfile.FindDockerfiles(detectionPath)
 				if err != nil {
-					log.Error(err, fmt.Sprintf("Unable to create a temp path %s for cloning %v", clonePath, req.NamespacedName))
+					log.Error(err, fmt.Sprintf("Unable to search for Dockerfiles under path %v... %v ", detectionPath, req.NamespacedName))
 					r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, err)
 					return ctrl.Result{}, nil
 				}
-
-				err = util.CloneRepo(clonePath, source.URL, gitToken)
+				// The Dockerfiles of the docker and build subfolders are only used once Alizer did not detect several components
+				if len(dockerfiles) > 0 && path.Dir(dockerfiles[0]) == "." {
+					log.Info(fmt.Sprintf("Determined that this is a Dockerfile only component, found the Dockerfiles %v  %v", dockerfiles, req.NamespacedName))
+					dockerfileContextMap["./"] = dockerfiles[0]
+					dockerfileCandidatesMap["./"] = dockerfiles
+					isDockerfilePresent = true
+				}
+			}
+			if !isDevfilePresent && !isDockerfilePresent {
+				components, err := r.AlizerClient.DetectComponents(detectionPath)
 				if err != nil {
-					log.Error(err, fmt.Sprintf("Unable to clone repo %s to path %s, exiting reconcile loop %v", source.URL, clonePath, req.NamespacedName))
+					log.Error(err, fmt.Sprintf("Unable to detect components using Alizer for repo %v, under path %v... %v ", source.URL, clonePath, req.NamespacedName))
 					r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, err)
 					return ctrl.Result{}, nil
 				}
-				log.Info(fmt.Sprintf("cloned from %s to path %s... %v", source.URL, clonePath, req.NamespacedName))
-				if !isDevfilePresent {
-					components, err := r.AlizerClient.DetectComponents(clonePath)
-					if err != nil {
-						log.Error(err, fmt.Sprintf("Unable to detect components using Alizer for repo %v, under path %v... %v ", source.URL, clonePath, req.NamespacedName))
-						r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, err)
-						return ctrl.Result{}, nil
-					}
-					log.Info(fmt.Sprintf("components detected %v... %v", components, req.NamespacedName))
+				log.Info(fmt.Sprintf("components detected %v... %v", components, req.NamespacedName))
+				if len(dockerfiles) > 0 && len(components) <= 1 {
+					// The Dockerfiles of the docker or build subfolder build the root dir, unless it holds several components
+					log.Info(fmt.Sprintf("Determined that this is a Dockerfile only component, found the Dockerfiles %v  %v", dockerfiles, req.NamespacedName))
+					dockerfileContextMap["./"] = dockerfiles[0]
+					dockerfileCandidatesMap["./"] = dockerfiles
+					isDockerfilePresent = true
+				} else if len(components) != 1 || (len(components) != 0 && path.Clean(components[0].Path) != path.Clean(detectionPath)) {
 					// If no devfile and no dockerfile present in the root
 					// case 1: no components been detected by Alizer, might still has subfolders contains dockerfile. Need to scan repo
 					// case 2: more than 1 components been detected by Alizer, is certain a multi-component project. Need to scan repo
 					// case 3: one or more than 1 compinents been detected by Alizer, and the first one in the list is under sub-folder. Need to scan repo.
-					if len(components) != 1 || (len(components) != 0 && path.Clean(components[0].Path) != path.Clean(clonePath)) {
-						isMultiComponent = true
-					}
+					isMultiComponent = true
 				}
 			}
 
 			// Logic to read multiple components in from git
 			if isMultiComponent {
-				log.Info(fmt.Sprintf("Since this is a multi-component, attempt will be made to read only level 1 dir for devfiles... %v", req.NamespacedName))
+				log.Info(fmt.Sprintf("Since this is a multi-component, attempt will be made to read the sub-directories for devfiles... %v", req.NamespacedName))
 
-				devfilesMap, devfilesURLMap, dockerfileContextMap, err = devfile.ScanRepo(log, r.AlizerClient, clonePath, r.DevfileRegistryURL)
+				devfilesMap, devfilesURLMap, dockerfileContextMap, dockerfileCandidatesMap, err = devfile.ScanRepo(log, r.AlizerClient, detectionPath, r.DevfileRegistryURL, scanOptions)
 				if err != nil {
 					if _, ok := err.(*devfile.NoDevfileFound); !ok {
 						log.Error(err, fmt.Sprintf("Unable to find devfile(s) in repo %s due to an error %s, exiting reconcile loop %v", source.URL, err.Error(), req.NamespacedName))
@@ -200,14 +245,22 @@ func (r *ComponentDetectionQueryReconciler) Reconcile(ctx context.Context, req c
 			} else {
 				log.Info(fmt.Sprintf("Since this is not a multi-component, attempt will be made to read devfile at the root dir... %v", req.NamespacedName))
 				if !isDockerfilePresent {
-					err := devfile.AnalyzePath(r.AlizerClient, clonePath, "./", r.DevfileRegistryURL, devfilesMap, devfilesURLMap, dockerfileContextMap, isDevfilePresent, isDockerfilePresent)
+					err := devfile.AnalyzePath(r.AlizerClient, detectionPath, "./", r.DevfileRegistryURL, devfilesMap, devfilesURLMap, dockerfileContextMap, isDevfilePresent, isDockerfilePresent)
 					if err != nil {
-						log.Error(err, fmt.Sprintf("Unable to analyze path %s for a dockerfile/devfile %v", clonePath, req.NamespacedName))
+						log.Error(err, fmt.Sprintf("Unable to analyze path %s for a dockerfile/devfile %v", detectionPath, req.NamespacedName))
 						r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, err)
 						return ctrl.Result{}, nil
 					}
 				}
 			}
+
+			// Look for the components described by a compose file, Kubernetes manifests or Helm charts
+			deploymentComponents, err = devfile.DetectDeploymentComponents(log, detectionPath, scanOptions)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Unable to detect the deployment descriptors in repo %s, exiting reconcile loop %v", source.URL, req.NamespacedName))
+				r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, err)
+				return ctrl.Result{}, nil
+			}
 		} else {
 			log.Info(fmt.Sprintf("devfile was explicitly specified at %s %v", source.DevfileURL, req.NamespacedName))
 			devfileBytes, err = util.CurlEndpoint(source.DevfileURL)
@@ -221,6 +274,21 @@ func (r *ComponentDetectionQueryReconciler) Reconcile(ctx context.Context, req c
 			devfilesMap["./"] = devfileBytes
 		}
 
+		// The contexts of the detected components are relative to the root of the repository
+		devfilesMap, devfilesURLMap, dockerfileContextMap, dockerfileCandidatesMap = addSourceContext(sourceContext, devfilesMap, devfilesURLMap, dockerfileContextMap, dockerfileCandidatesMap)
+		deploymentComponents = addSourceContextToDeploymentComponents(sourceContext, deploymentComponents)
+
+		// Rank the devfile stacks matching the languages of the detected components
+		var componentAnalyses map[string]devfile.ComponentAnalysis
+		if clonePath != "" {
+			contexts := getDetectedContexts(devfilesMap, dockerfileContextMap, deploymentComponents)
+			componentAnalyses, err = devfile.AnalyzeComponents(r.AlizerClient, clonePath, contexts, r.DevfileRegistryURL, devfilesURLMap)
+			if err != nil {
+				// The devfile candidates are only suggestions, the components are reported without them
+				log.Error(err, fmt.Sprintf("Unable to rank the devfile candidates of the components %v", req.NamespacedName))
+			}
+		}
+
 		// Remove the cloned path if present
 		if isExist, _ := ioutils.IsExisting(r.AppFS, clonePath); isExist {
 			if err := r.AppFS.RemoveAll(clonePath); err != nil {
@@ -240,12 +308,21 @@ func (r *ComponentDetectionQueryReconciler) Reconcile(ctx context.Context, req c
 			dockerfileContextMap[context] = updatedLink
 		}
 
-		err = r.updateComponentStub(req, ctx, &componentDetectionQuery, devfilesMap, devfilesURLMap, dockerfileContextMap)
+		// The deployment descriptors replace the dockerfile only components of their contexts
+		err = r.updateDeploymentComponentStubs(req, ctx, &componentDetectionQuery, deploymentComponents, devfilesMap, dockerfileContextMap, dockerfileCandidatesMap)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Unable to update the component stubs of the deployment descriptors %v", req.NamespacedName))
+			r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, err)
+			return ctrl.Result{}, nil
+		}
+
+		err = r.updateComponentStub(req, ctx, &componentDetectionQuery, devfilesMap, devfilesURLMap, dockerfileContextMap, dockerfileCandidatesMap)
 		if err != nil {
 			log.Error(err, fmt.Sprintf("Unable to update the component stub %v", req.NamespacedName))
 			r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, err)
 			return ctrl.Result{}, nil
 		}
+		addComponentAnalyses(&componentDetectionQuery, componentAnalyses)
 
 		r.SetCompleteConditionAndUpdateCR(ctx, req, &componentDetectionQuery, nil)
 	} else {
@@ -15,6 +15,8 @@
 
 package controllers
 
+import "time"
+
 const (
 	// routeKey is the key to reference route
 	routeKey = "deployment/route"
@@ -45,4 +47,13 @@ const (
 
 	// containerENVKey is the key to reference container environment variables
 	containerENVKey = "deployment/containerENV"
+
+	// pullRequestResyncPeriod is how often the state of an open gitops pull request is refreshed
+	pullRequestResyncPeriod = 5 * time.Minute
+
+	// bindingPullRequestAnnotation is the annotation recording, as JSON, the gitops pull request of a binding
+	bindingPullRequestAnnotation = "appstudio.redhat.com/gitops-pull-request"
+
+	// signingKeyDirName is the folder, within the temp folder of a gitops clone, the commit signing key is loaded into
+	signingKeyDirName = ".gitops-signing"
 )
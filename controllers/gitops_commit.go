@@ -0,0 +1,58 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
+	gitopsgen "github.com/redhat-developer/gitops-generator/pkg"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newGitOpsCommitExecutor wraps the executor so that the commits to the application's GitOps repository follow the
+// application's GitOps commit configuration. If the commits are signed, the signing key is loaded into keyDir,
+// which the caller is responsible for removing.
+func newGitOpsCommitExecutor(ctx context.Context, c client.Client, e gitopsgen.Executor, appFs afero.Afero, application *appstudiov1alpha1.Application, keyDir string, messageData appservicegitops.CommitMessageData) (*appservicegitops.CommitExecutor, error) {
+	commitConfig := application.Spec.GitOpsCommit
+	commitExecutor := &appservicegitops.CommitExecutor{
+		Executor:        e,
+		AuthorName:      commitConfig.AuthorName,
+		AuthorEmail:     commitConfig.AuthorEmail,
+		MessageTemplate: commitConfig.MessageTemplate,
+		MessageData:     messageData,
+	}
+
+	if commitConfig.SigningSecret != "" {
+		signingSecret := corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Name: commitConfig.SigningSecret, Namespace: application.Namespace}, &signingSecret); err != nil {
+			return nil, fmt.Errorf("unable to retrieve the gitops commit signing secret %s: %v", commitConfig.SigningSecret, err)
+		}
+		signingConfig, err := appservicegitops.ConfigureCommitSigning(e, appFs, keyDir, signingSecret.Data)
+		if err != nil {
+			return nil, err
+		}
+		commitExecutor.SigningConfig = signingConfig
+	}
+
+	return commitExecutor, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	gh "github.com/google/go-github/v41/github"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	github "github.com/redhat-appstudio/application-service/pkg/github"
+)
+
+// openGitOpsPullRequest opens a pull request from prBranch to baseBranch in the GitOps repository at repoURL,
+// or updates the pull request that is already open for prBranch
+func openGitOpsPullRequest(ctx context.Context, client *gh.Client, repoURL string, prBranch string, baseBranch string, title string) (appstudiov1alpha1.GitOpsPullRequestStatus, error) {
+	if client == nil {
+		return appstudiov1alpha1.GitOpsPullRequestStatus{}, fmt.Errorf("unable to open a pull request against %s, no GitHub client is configured", repoURL)
+	}
+
+	owner, repoName, err := github.GetOwnerAndRepoFromURL(repoURL)
+	if err != nil {
+		return appstudiov1alpha1.GitOpsPullRequestStatus{}, err
+	}
+
+	body := fmt.Sprintf("This pull request was opened by the application service, as the GitOps changes for branch %s are delivered through pull requests.", baseBranch)
+	pr, err := github.CreateOrUpdatePullRequest(client, ctx, owner, repoName, prBranch, baseBranch, title, body)
+	if err != nil {
+		return appstudiov1alpha1.GitOpsPullRequestStatus{}, fmt.Errorf("unable to open a pull request against %s: %v", repoURL, err)
+	}
+
+	return appstudiov1alpha1.GitOpsPullRequestStatus{
+		URL:    pr.GetHTMLURL(),
+		Number: pr.GetNumber(),
+		Branch: prBranch,
+		State:  github.PullRequestState(pr),
+	}, nil
+}
+
+// getGitOpsPullRequestState returns the state of the pull request in the GitOps repository at repoURL,
+// and the ID of its merge commit once it is merged
+func getGitOpsPullRequestState(ctx context.Context, client *gh.Client, repoURL string, number int) (string, string, error) {
+	if client == nil {
+		return "", "", fmt.Errorf("no GitHub client is configured")
+	}
+	owner, repoName, err := github.GetOwnerAndRepoFromURL(repoURL)
+	if err != nil {
+		return "", "", err
+	}
+	return github.GetPullRequestState(client, ctx, owner, repoName, number)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/pkg/imageregistry"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// getImageRepositoryName returns the name of the image repository provisioned for the component in the image registry
+func (r *ComponentReconciler) getImageRepositoryName(component appstudiov1alpha1.Component) string {
+	return imageregistry.GetRepositoryName(r.ImageRepositoryScope, component.Namespace, component.Spec.Application, component.Name)
+}
+
+// getImagePushSecretName returns the name of the Secret holding the credentials pushing to the image repository of the
+// component, shared by the components of the application with the application scope
+func (r *ComponentReconciler) getImagePushSecretName(component appstudiov1alpha1.Component) string {
+	if r.ImageRepositoryScope == imageregistry.ApplicationScope {
+		return component.Spec.Application + "-image-push"
+	}
+	return component.Name + "-image-push"
+}
+
+// ensureImageRepository provisions the image repository of the component in the image registry, and stores the
+// credentials pushing to it in a dockerconfigjson Secret. The status of the repository is returned, to be recorded once
+// the component's image points to the repository.
+func (r *ComponentReconciler) ensureImageRepository(ctx context.Context, component *appstudiov1alpha1.Component) (*appstudiov1alpha1.ImageRepositoryStatus, error) {
+	repositoryName := r.getImageRepositoryName(*component)
+	repositoryURL, err := r.ImageRegistry.EnsureRepository(ctx, repositoryName)
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := r.ImageRegistry.EnsurePushCredentials(ctx, repositoryName)
+	if err != nil {
+		return nil, err
+	}
+	dockerConfig, err := credentials.DockerConfigJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	secretName := r.getImagePushSecretName(*component)
+	pushSecret := &corev1.Secret{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: component.Namespace}, pushSecret)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("unable to retrieve the image push secret %s: %v", secretName, err)
+		}
+		pushSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: component.Namespace,
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: dockerConfig,
+			},
+		}
+		// The secret of a component's repository is garbage collected together with the component,
+		// the secret shared by the components of an application is removed with the repository
+		if r.ImageRepositoryScope != imageregistry.ApplicationScope {
+			if err := controllerutil.SetOwnerReference(component, pushSecret, r.Scheme); err != nil {
+				return nil, err
+			}
+		}
+		if err := r.Client.Create(ctx, pushSecret); err != nil {
+			return nil, fmt.Errorf("unable to create the image push secret %s: %v", secretName, err)
+		}
+	} else if string(pushSecret.Data[corev1.DockerConfigJsonKey]) != string(dockerConfig) {
+		pushSecret.Data = map[string][]byte{
+			corev1.DockerConfigJsonKey: dockerConfig,
+		}
+		if err := r.Client.Update(ctx, pushSecret); err != nil {
+			return nil, fmt.Errorf("unable to update the image push secret %s: %v", secretName, err)
+		}
+	}
+
+	return &appstudiov1alpha1.ImageRepositoryStatus{
+		URL:        repositoryURL,
+		PushSecret: secretName,
+	}, nil
+}
+
+// removeImageRepository deletes the image repository provisioned for the component and the credentials pushing to it,
+// unless another component of the namespace still uses the repository
+func (r *ComponentReconciler) removeImageRepository(ctx context.Context, component *appstudiov1alpha1.Component) error {
+	if r.ImageRegistry == nil || component.Status.ImageRepository == nil {
+		return nil
+	}
+
+	components := &appstudiov1alpha1.ComponentList{}
+	if err := r.Client.List(ctx, components, client.InNamespace(component.Namespace)); err != nil {
+		return fmt.Errorf("unable to list the components of the namespace %s: %v", component.Namespace, err)
+	}
+	for _, otherComponent := range components.Items {
+		if otherComponent.Name != component.Name && otherComponent.Status.ImageRepository != nil &&
+			otherComponent.Status.ImageRepository.URL == component.Status.ImageRepository.URL {
+			return nil
+		}
+	}
+
+	repositoryName := r.getImageRepositoryName(*component)
+	if err := r.ImageRegistry.DeletePushCredentials(ctx, repositoryName); err != nil {
+		return err
+	}
+	if err := r.ImageRegistry.DeleteRepository(ctx, repositoryName); err != nil {
+		return err
+	}
+
+	if secretName := component.Status.ImageRepository.PushSecret; secretName != "" {
+		pushSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: component.Namespace,
+			},
+		}
+		if err := r.Client.Delete(ctx, pushSecret); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete the image push secret %s: %v", secretName, err)
+		}
+	}
+	return nil
+}
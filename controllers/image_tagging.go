@@ -0,0 +1,35 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// isImageTaggingChanged returns true if the build resources of the component were generated with another image tagging
+// than the one it now resolves from its own and its Application's image tagging. Components whose build resources were
+// not generated with a recorded image tagging are not considered changed.
+func isImageTaggingChanged(component appstudiov1alpha1.Component, application appstudiov1alpha1.Application) bool {
+	if component.Spec.Source.GitSource == nil || component.Spec.Source.GitSource.URL == "" || component.Status.BuildPipeline.ImageTagging == nil {
+		return false
+	}
+	imageTagging := appservicegitops.GetImageTagging(component, prepare.GitopsConfig{ApplicationImageTagging: application.Spec.ImageTagging})
+	return !equality.Semantic.DeepEqual(imageTagging, *component.Status.BuildPipeline.ImageTagging)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// submitInitialBuild creates the PipelineRun building the image of the component and records it in the component's status.
+// The build is only submitted once, unless a new build is requested with the rebuild annotation, which is then removed.
+// The PipelineRun is named after the build it follows, so a reconcile failing after its creation does not submit it twice.
+func (r *ComponentReconciler) submitInitialBuild(ctx context.Context, component *appstudiov1alpha1.Component) error {
+	rebuildRequested := appservicegitops.IsRebuildRequested(*component)
+	pipelineRunName := getBuildPipelineRunName(*component, component.Status.Build.PipelineRun)
+	if component.Status.Build.PipelineRun != "" && !rebuildRequested {
+		// A rebuild may have been submitted and its request cleared without the build being recorded
+		existing := &tektonapi.PipelineRun{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: pipelineRunName, Namespace: component.Namespace}, existing)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("unable to get the build pipeline run %s: %v", pipelineRunName, err)
+		}
+		return r.recordBuildPipelineRun(ctx, component, pipelineRunName)
+	}
+
+	pipelineRun, err := appservicegitops.GenerateInitialBuildPipelineRun(*component, r.getGitopsConfig(ctx, *component))
+	if err != nil {
+		return fmt.Errorf("unable to generate the build pipeline run: %v", err)
+	}
+	pipelineRun.GenerateName = ""
+	pipelineRun.Name = pipelineRunName
+	// The pipeline run is garbage collected together with the component
+	if err := controllerutil.SetOwnerReference(component, &pipelineRun, r.Scheme); err != nil {
+		return err
+	}
+	// The pipeline run already exists if an earlier reconcile failed after creating it
+	if err := r.Client.Create(ctx, &pipelineRun); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("unable to create the build pipeline run: %v", err)
+	}
+
+	if rebuildRequested {
+		// The rebuild was submitted, so the request is cleared
+		delete(component.Annotations, appservicegitops.RebuildAnnotation)
+		if err := r.Client.Update(ctx, component); err != nil {
+			return fmt.Errorf("unable to clear the rebuild request: %v", err)
+		}
+	}
+	return r.recordBuildPipelineRun(ctx, component, pipelineRunName)
+}
+
+// recordBuildPipelineRun records the build PipelineRun in the component's status
+func (r *ComponentReconciler) recordBuildPipelineRun(ctx context.Context, component *appstudiov1alpha1.Component, pipelineRunName string) error {
+	component.Status.Build.PipelineRun = pipelineRunName
+	if err := r.Client.Status().Update(ctx, component); err != nil {
+		return fmt.Errorf("unable to record the build pipeline run %s: %v", pipelineRunName, err)
+	}
+	return nil
+}
+
+// getBuildPipelineRunName returns the name of the build PipelineRun of the component submitted after the previous one,
+// the component's name followed by a hash of its UID and of the previous PipelineRun
+func getBuildPipelineRunName(component appstudiov1alpha1.Component, previousPipelineRun string) string {
+	hash := sha256.Sum256([]byte(string(component.UID) + "/" + previousPipelineRun))
+	prefix := component.Name
+	// The name of the pipeline run is used in label values, which are limited to 63 characters
+	if len(prefix) > 52 {
+		prefix = prefix[:52]
+	}
+	return prefix + "-" + hex.EncodeToString(hash[:])[:10]
+}
+
+// getGitopsConfig returns the configuration of the generation of the component's gitops resources
+func (r *ComponentReconciler) getGitopsConfig(ctx context.Context, component appstudiov1alpha1.Component) prepare.GitopsConfig {
+	gitopsConfig := prepare.PrepareGitopsConfig(ctx, r.Client, r.HACBSDetector, component)
+	gitopsConfig.Ingress = r.Ingress
+	gitopsConfig.BuildWorkspace = r.BuildWorkspace
+	return gitopsConfig
+}
@@ -21,7 +21,11 @@ import (
 
 	kcpclient "github.com/kcp-dev/apimachinery/pkg/client"
 	"github.com/kcp-dev/logicalcluster"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
 	appstudioshared "github.com/redhat-appstudio/managed-gitops/appstudio-shared/apis/appstudio.redhat.com/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -69,3 +73,145 @@ func MapToBindingByBoundObjectName(cl client.Client, objectType, label string) f
 		return req
 	}
 }
+
+// MapToComponentByBuildLabel maps the build PipelineRuns to the Component named by their component label.
+// The PipelineRuns without the label are not related to any Component.
+func MapToComponentByBuildLabel(obj client.Object) []reconcile.Request {
+	componentName := obj.GetLabels()[appservicegitops.BuildComponentLabel]
+	if componentName == "" {
+		return []reconcile.Request{}
+	}
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Namespace: obj.GetNamespace(),
+				Name:      componentName,
+			},
+			ClusterName: logicalcluster.From(obj).String(),
+		},
+	}
+}
+
+// MapToComponentsByBuildBundleConfigMap maps the ConfigMaps configuring the build bundle to the Components whose build
+// resources were generated with another bundle than the one they now resolve. The ConfigMap of the default namespace
+// configures the Components of all the namespaces, unless their namespace has its own ConfigMap.
+func MapToComponentsByBuildBundleConfigMap(cl client.Client, hacbsDetector *prepare.HACBSDetector) func(object client.Object) []reconcile.Request {
+	return func(obj client.Object) []reconcile.Request {
+		if obj.GetName() != prepare.BuildBundleConfigMapName {
+			return []reconcile.Request{}
+		}
+		// Retrieve the cluster name (if applicable)
+		clusterName := logicalcluster.From(obj).String()
+
+		log := ctrl.Log.WithName("MapToComponentsByBuildBundleConfigMap").WithValues("namespace", obj.GetNamespace()).WithValues("clusterName", clusterName)
+		ctx := kcpclient.WithCluster(context.TODO(), logicalcluster.New(clusterName))
+
+		var listOptions []client.ListOption
+		if obj.GetNamespace() != prepare.BuildBundleDefaultNamespace {
+			listOptions = append(listOptions, client.InNamespace(obj.GetNamespace()))
+		}
+		componentList := &appstudiov1alpha1.ComponentList{}
+		if err := cl.List(ctx, componentList, listOptions...); err != nil {
+			log.Error(err, "unable to list the Components configured by the build bundle ConfigMap")
+			return []reconcile.Request{}
+		}
+
+		requests := []reconcile.Request{}
+		for _, component := range componentList.Items {
+			// The Components whose build resources were not generated yet will resolve the bundle when they are
+			if component.Status.BuildPipeline.Bundle == "" ||
+				prepare.ResolveComponentBuildBundle(ctx, cl, hacbsDetector, component) == component.Status.BuildPipeline.Bundle {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: component.Namespace,
+					Name:      component.Name,
+				},
+				ClusterName: clusterName,
+			})
+			log.Info(fmt.Sprintf("The build bundle of the Component %s changed, it will be reconciled", component.Name))
+		}
+		return requests
+	}
+}
+
+// MapToComponentsByApplicationImageTagging maps the Applications to their Components whose build resources were generated
+// with another image tagging than the one they now resolve from the Application's default image tagging
+func MapToComponentsByApplicationImageTagging(cl client.Client) func(object client.Object) []reconcile.Request {
+	return func(obj client.Object) []reconcile.Request {
+		application, ok := obj.(*appstudiov1alpha1.Application)
+		if !ok {
+			return []reconcile.Request{}
+		}
+		// Retrieve the cluster name (if applicable)
+		clusterName := logicalcluster.From(obj).String()
+
+		log := ctrl.Log.WithName("MapToComponentsByApplicationImageTagging").WithValues("application", obj.GetName()).WithValues("clusterName", clusterName)
+		ctx := kcpclient.WithCluster(context.TODO(), logicalcluster.New(clusterName))
+
+		componentList := &appstudiov1alpha1.ComponentList{}
+		if err := cl.List(ctx, componentList, client.InNamespace(obj.GetNamespace())); err != nil {
+			log.Error(err, "unable to list the Components of the Application")
+			return []reconcile.Request{}
+		}
+
+		requests := []reconcile.Request{}
+		for _, component := range componentList.Items {
+			if component.Spec.Application != application.Name || !isImageTaggingChanged(component, *application) {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: component.Namespace,
+					Name:      component.Name,
+				},
+				ClusterName: clusterName,
+			})
+			log.Info(fmt.Sprintf("The image tagging of the Component %s changed, it will be reconciled", component.Name))
+		}
+		return requests
+	}
+}
+
+// MapToComponentsByRegistrySecret maps the registry secrets to the Components of their namespace whose registry auth secret
+// merged another version of them
+func MapToComponentsByRegistrySecret(cl client.Client) func(object client.Object) []reconcile.Request {
+	return func(obj client.Object) []reconcile.Request {
+		// Retrieve the cluster name (if applicable)
+		clusterName := logicalcluster.From(obj).String()
+
+		log := ctrl.Log.WithName("MapToComponentsByRegistrySecret").WithValues("secret", obj.GetName(), "namespace", obj.GetNamespace()).WithValues("clusterName", clusterName)
+		ctx := kcpclient.WithCluster(context.TODO(), logicalcluster.New(clusterName))
+
+		componentList := &appstudiov1alpha1.ComponentList{}
+		if err := cl.List(ctx, componentList, client.InNamespace(obj.GetNamespace())); err != nil {
+			log.Error(err, "unable to list the Components of the namespace of the registry secret")
+			return []reconcile.Request{}
+		}
+
+		requests := []reconcile.Request{}
+		for _, component := range componentList.Items {
+			mergedSecret := &corev1.Secret{}
+			if err := cl.Get(ctx, types.NamespacedName{Name: appservicegitops.GetMergedRegistryAuthSecretName(component), Namespace: component.Namespace}, mergedSecret); err != nil {
+				continue
+			}
+			sources, err := getRegistryAuthSources(mergedSecret)
+			if err != nil {
+				continue
+			}
+			if resourceVersion, isMerged := sources[obj.GetName()]; !isMerged || resourceVersion == obj.GetResourceVersion() {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: component.Namespace,
+					Name:      component.Name,
+				},
+				ClusterName: clusterName,
+			})
+			log.Info(fmt.Sprintf("The registry secret of the Component %s changed, it will be reconciled", component.Name))
+		}
+		return requests
+	}
+}
@@ -20,9 +20,14 @@ import (
 	"fmt"
 	"testing"
 
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
 	appstudioshared "github.com/redhat-appstudio/managed-gitops/appstudio-shared/apis/appstudio.redhat.com/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -250,6 +255,213 @@ func TestMapToBindingByBoundObject(t *testing.T) {
 	})
 }
 
+func TestMapToComponentByBuildLabel(t *testing.T) {
+	t.Run("should return the Component request for a build PipelineRun", func(t *testing.T) {
+		pipelineRun := &tektonapi.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "backend-abcde",
+				Namespace: "default",
+				Labels:    map[string]string{appservicegitops.BuildComponentLabel: "backend"},
+			},
+		}
+
+		// when
+		requests := MapToComponentByBuildLabel(pipelineRun)
+
+		// then
+		require.Len(t, requests, 1)
+		assert.Equal(t, newRequest("backend"), requests[0])
+	})
+
+	t.Run("should return no Component requests for other PipelineRuns", func(t *testing.T) {
+		pipelineRun := &tektonapi.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "release-abcde",
+				Namespace: "default",
+			},
+		}
+
+		// when
+		requests := MapToComponentByBuildLabel(pipelineRun)
+
+		// then
+		require.Empty(t, requests)
+	})
+}
+
+func TestMapToComponentsByBuildBundleConfigMap(t *testing.T) {
+	require.NoError(t, appstudiov1alpha1.AddToScheme(scheme.Scheme))
+
+	bundleConfigMap := func(namespace string, bundle string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      prepare.BuildBundleConfigMapName,
+				Namespace: namespace,
+			},
+			Data: map[string]string{prepare.BuildBundleConfigMapKey: bundle},
+		}
+	}
+	component := func(namespace string, name string, bundle string, pinnedBundle string) *appstudiov1alpha1.Component {
+		component := &appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Status: appstudiov1alpha1.ComponentStatus{
+				BuildPipeline: appstudiov1alpha1.BuildPipelineStatus{Bundle: bundle},
+			},
+		}
+		if pinnedBundle != "" {
+			component.Spec.BuildPipeline = &appstudiov1alpha1.BuildPipelineOverride{Bundle: pinnedBundle}
+		}
+		return component
+	}
+
+	defaultConfigMap := bundleConfigMap(prepare.BuildBundleDefaultNamespace, "quay.io/org/bundle:2")
+	objects := []runtime.Object{
+		defaultConfigMap,
+		bundleConfigMap("custom", "quay.io/org/custom-bundle:1"),
+		// Generated with the previous default bundle
+		component("default", "backend", "quay.io/org/bundle:1", ""),
+		// Generated with the current default bundle
+		component("default", "frontend", "quay.io/org/bundle:2", ""),
+		// Build resources not generated yet
+		component("default", "new", "", ""),
+		// Pinned bundle
+		component("default", "pinned", "quay.io/org/bundle:1", "quay.io/org/bundle:1"),
+		// Bundle configured by the ConfigMap of its namespace
+		component("custom", "backend", "quay.io/org/custom-bundle:0", ""),
+	}
+	cl := fake.NewClientBuilder().WithRuntimeObjects(objects...).Build()
+
+	t.Run("should return the Components of all the namespaces generated with another bundle than the default one", func(t *testing.T) {
+		// when
+		requests := MapToComponentsByBuildBundleConfigMap(cl, nil)(defaultConfigMap)
+
+		// then
+		assert.ElementsMatch(t, []reconcile.Request{
+			newRequest("backend"),
+			{NamespacedName: types.NamespacedName{Namespace: "custom", Name: "backend"}},
+		}, requests)
+	})
+
+	t.Run("should return the Components of the namespace of the ConfigMap", func(t *testing.T) {
+		// when
+		requests := MapToComponentsByBuildBundleConfigMap(cl, nil)(bundleConfigMap("custom", "quay.io/org/custom-bundle:1"))
+
+		// then
+		assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "custom", Name: "backend"}}}, requests)
+	})
+
+	t.Run("should ignore other ConfigMaps", func(t *testing.T) {
+		otherConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+
+		// when
+		requests := MapToComponentsByBuildBundleConfigMap(cl, nil)(otherConfigMap)
+
+		// then
+		require.Empty(t, requests)
+	})
+}
+
+func TestMapToComponentsByApplicationImageTagging(t *testing.T) {
+	require.NoError(t, appstudiov1alpha1.AddToScheme(scheme.Scheme))
+
+	branchTagging := &appstudiov1alpha1.ImageTagging{Strategy: appstudiov1alpha1.BranchImageTagStrategy}
+	revisionTagging := &appstudiov1alpha1.ImageTagging{Strategy: appstudiov1alpha1.RevisionImageTagStrategy}
+	component := func(name string, application string, generatedTagging *appstudiov1alpha1.ImageTagging, ownTagging *appstudiov1alpha1.ImageTagging) *appstudiov1alpha1.Component {
+		return &appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: appstudiov1alpha1.ComponentSpec{
+				Application:  application,
+				ImageTagging: ownTagging,
+				Source: appstudiov1alpha1.ComponentSource{
+					ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+						GitSource: &appstudiov1alpha1.GitSource{URL: "https://github.com/org/" + name},
+					},
+				},
+			},
+			Status: appstudiov1alpha1.ComponentStatus{
+				BuildPipeline: appstudiov1alpha1.BuildPipelineStatus{ImageTagging: generatedTagging},
+			},
+		}
+	}
+	cl := fake.NewClientBuilder().WithRuntimeObjects(
+		// Generated with the previous default image tagging of the Application
+		component("backend", "app", revisionTagging, nil),
+		// Generated with the current default image tagging of the Application
+		component("frontend", "app", branchTagging, nil),
+		// Own image tagging
+		component("tagged", "app", revisionTagging, revisionTagging),
+		// Build resources not generated yet
+		component("new", "app", nil, nil),
+		// Component of another Application
+		component("other", "other-app", revisionTagging, nil),
+	).Build()
+	application := &appstudiov1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app",
+			Namespace: "default",
+		},
+		Spec: appstudiov1alpha1.ApplicationSpec{ImageTagging: branchTagging},
+	}
+
+	// when
+	requests := MapToComponentsByApplicationImageTagging(cl)(application)
+
+	// then
+	assert.Equal(t, []reconcile.Request{newRequest("backend")}, requests)
+}
+
+func TestMapToComponentsByRegistrySecret(t *testing.T) {
+	require.NoError(t, appstudiov1alpha1.AddToScheme(scheme.Scheme))
+
+	component := func(name string) *appstudiov1alpha1.Component {
+		return &appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+		}
+	}
+	mergedSecret := func(componentName string, sources string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        componentName + "-registry-auth",
+				Namespace:   "default",
+				Annotations: map[string]string{registryAuthSourcesAnnotation: sources},
+			},
+		}
+	}
+	cl := fake.NewClientBuilder().WithRuntimeObjects(
+		// Merged a previous version of the registry secret
+		component("backend"), mergedSecret("backend", `{"mirror":"1","other":"1"}`),
+		// Merged the current version of the registry secret
+		component("frontend"), mergedSecret("frontend", `{"mirror":"2"}`),
+		// Merged other registry secrets
+		component("other"), mergedSecret("other", `{"other":"1"}`),
+		// No merged registry secret
+		component("single"),
+	).Build()
+	registrySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "mirror",
+			Namespace:       "default",
+			ResourceVersion: "2",
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+	}
+
+	// when
+	requests := MapToComponentsByRegistrySecret(cl)(registrySecret)
+
+	// then
+	assert.Equal(t, []reconcile.Request{newRequest("backend")}, requests)
+}
+
 func newRequest(name string) reconcile.Request {
 	return reconcile.Request{
 		NamespacedName: types.NamespacedName{
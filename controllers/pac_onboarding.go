@@ -0,0 +1,199 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	gh "github.com/google/go-github/v41/github"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+	github "github.com/redhat-appstudio/application-service/pkg/github"
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	"golang.org/x/oauth2"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// pacOnboardingConditionType is the type of the Component condition reflecting whether its Pipelines as Code onboarding
+// pull request was opened
+const pacOnboardingConditionType = "PaCOnboardingPullRequestOpened"
+
+// pacOnboardingFailedReason is the reason of the PaCOnboardingPullRequestOpened condition of the components whose onboarding
+// pull request could not be opened. Opening it is retried until it succeeds.
+const pacOnboardingFailedReason = "PullRequestFailed"
+
+// ensurePaCOnboardingPullRequest opens the Pipelines as Code onboarding pull request of the component. A failure is reported
+// in the PaCOnboardingPullRequestOpened condition of the component rather than returned, as the gitops resources of the
+// component are already pushed and do not need to be generated again.
+func (r *ComponentReconciler) ensurePaCOnboardingPullRequest(ctx context.Context, component *appstudiov1alpha1.Component, gitopsConfig prepare.GitopsConfig) {
+	if err := r.openPaCOnboardingPullRequest(ctx, component, gitopsConfig); err != nil {
+		r.Log.Error(err, fmt.Sprintf("Unable to open the Pipelines as Code onboarding pull request of component %s", component.Name))
+		meta.SetStatusCondition(&component.Status.Conditions, metav1.Condition{
+			Type:    pacOnboardingConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  pacOnboardingFailedReason,
+			Message: fmt.Sprintf("Unable to open the Pipelines as Code onboarding pull request: %v", util.SanitizeErrorMessage(err)),
+		})
+	}
+}
+
+// retryPaCOnboardingPullRequest opens the Pipelines as Code onboarding pull request of the component again after a failure,
+// and updates the status of the component. The failure is forgotten if the component is no longer built with Pipelines as Code.
+func (r *ComponentReconciler) retryPaCOnboardingPullRequest(ctx context.Context, component *appstudiov1alpha1.Component) error {
+	if gitopsConfig := r.getGitopsConfig(ctx, *component); appservicegitops.IsPaCBuild(*component, gitopsConfig) {
+		r.ensurePaCOnboardingPullRequest(ctx, component, gitopsConfig)
+	} else {
+		meta.RemoveStatusCondition(&component.Status.Conditions, pacOnboardingConditionType)
+	}
+	return r.Client.Status().Update(ctx, component)
+}
+
+// isPaCOnboardingFailed returns true if the Pipelines as Code onboarding pull request of the component could not be opened
+func isPaCOnboardingFailed(component appstudiov1alpha1.Component) bool {
+	condition := meta.FindStatusCondition(component.Status.Conditions, pacOnboardingConditionType)
+	return condition != nil && condition.Status == metav1.ConditionFalse && condition.Reason == pacOnboardingFailedReason
+}
+
+// openPaCOnboardingPullRequest pushes the Pipelines as Code PipelineRuns of the component to its onboarding branch in the
+// source repository and opens, or updates, the pull request against the built branch. The pull request is recorded in the
+// component's status. Only GitHub repositories are supported, the PipelineRuns of other repositories are left to the user,
+// which the PaCOnboardingPullRequestOpened condition of the component reports.
+func (r *ComponentReconciler) openPaCOnboardingPullRequest(ctx context.Context, component *appstudiov1alpha1.Component, gitopsConfig prepare.GitopsConfig) error {
+	gitProvider, err := appservicegitops.GetGitProvider(*component)
+	if err != nil {
+		return err
+	}
+	if gitProvider != "github" {
+		r.Log.Info(fmt.Sprintf("The Pipelines as Code onboarding pull request is not supported for %s repositories, skipping it for component %s", gitProvider, component.Name))
+		meta.SetStatusCondition(&component.Status.Conditions, metav1.Condition{
+			Type:    pacOnboardingConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  "UnsupportedGitProvider",
+			Message: fmt.Sprintf("The Pipelines as Code onboarding pull request is not supported for %s repositories, the PipelineRuns must be added to the repository manually", gitProvider),
+		})
+		return nil
+	}
+
+	client, err := r.getSourceRepositoryClient(ctx, *component, gitopsConfig)
+	if err != nil {
+		return err
+	}
+	sourceURL := component.Spec.Source.GitSource.URL
+	owner, repoName, err := github.GetOwnerAndRepoFromURL(sourceURL)
+	if err != nil {
+		return err
+	}
+
+	baseBranch := component.Spec.Source.GitSource.Revision
+	if baseBranch == "" {
+		if baseBranch, err = github.GetDefaultBranch(client, ctx, owner, repoName); err != nil {
+			return fmt.Errorf("unable to retrieve the default branch of %s: %v", sourceURL, err)
+		}
+	}
+
+	files, err := appservicegitops.GeneratePaCPipelineRunFiles(*component, gitopsConfig, baseBranch)
+	if err != nil {
+		return fmt.Errorf("unable to generate the Pipelines as Code PipelineRuns: %v", err)
+	}
+	prBranch := appservicegitops.GetPaCOnboardingBranch(*component)
+	commitMessage := fmt.Sprintf("Add Pipelines as Code PipelineRuns building component %s", component.Name)
+	hasChanges, err := github.CommitFilesToBranch(client, ctx, owner, repoName, baseBranch, prBranch, files, commitMessage)
+	if err != nil {
+		return err
+	}
+	if !hasChanges {
+		// The built branch already holds the PipelineRuns
+		meta.SetStatusCondition(&component.Status.Conditions, metav1.Condition{
+			Type:    pacOnboardingConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  "OK",
+			Message: fmt.Sprintf("The branch %s already holds the Pipelines as Code PipelineRuns", baseBranch),
+		})
+		return nil
+	}
+
+	title := fmt.Sprintf("Build component %s with Pipelines as Code", component.Name)
+	body := fmt.Sprintf("This pull request was opened by the application service. Merge it to build the component %s on the push and pull request events of branch %s.", component.Name, baseBranch)
+	pr, err := github.CreateOrUpdatePullRequest(client, ctx, owner, repoName, prBranch, baseBranch, title, body)
+	if err != nil {
+		return fmt.Errorf("unable to open a pull request against %s: %v", sourceURL, err)
+	}
+
+	component.Status.Build.OnboardingPullRequest = appstudiov1alpha1.GitOpsPullRequestStatus{
+		URL:    pr.GetHTMLURL(),
+		Number: pr.GetNumber(),
+		Branch: prBranch,
+		State:  github.PullRequestState(pr),
+	}
+	meta.SetStatusCondition(&component.Status.Conditions, metav1.Condition{
+		Type:    pacOnboardingConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "OK",
+		Message: fmt.Sprintf("The Pipelines as Code onboarding pull request %s was opened", pr.GetHTMLURL()),
+	})
+	return nil
+}
+
+// refreshOnboardingPullRequestState updates the state of the component's Pipelines as Code onboarding pull request in its status
+func (r *ComponentReconciler) refreshOnboardingPullRequestState(ctx context.Context, component *appstudiov1alpha1.Component) error {
+	client, err := r.getSourceRepositoryClient(ctx, *component, r.getGitopsConfig(ctx, *component))
+	if err != nil {
+		return err
+	}
+	owner, repoName, err := github.GetOwnerAndRepoFromURL(component.Spec.Source.GitSource.URL)
+	if err != nil {
+		return err
+	}
+	state, _, err := github.GetPullRequestState(client, ctx, owner, repoName, component.Status.Build.OnboardingPullRequest.Number)
+	if err != nil {
+		return err
+	}
+	if state == component.Status.Build.OnboardingPullRequest.State {
+		return nil
+	}
+	component.Status.Build.OnboardingPullRequest.State = state
+	return r.Client.Status().Update(ctx, component)
+}
+
+// getSourceRepositoryClient returns a GitHub client authenticated with the GitHub token of the Pipelines as Code secret,
+// targeting the API of the component's GitHub Enterprise host if needed. The operator's client only has access to the
+// gitops repositories, so the token is required to access the source repository.
+func (r *ComponentReconciler) getSourceRepositoryClient(ctx context.Context, component appstudiov1alpha1.Component, gitopsConfig prepare.GitopsConfig) (*gh.Client, error) {
+	sourceURL, err := url.Parse(component.Spec.Source.GitSource.URL)
+	if err != nil {
+		return nil, err
+	}
+	credentials := gitopsConfig.PipelinesAsCodeCredentials
+	token := credentials[appservicegitops.GetProviderCredentialKey("github", sourceURL.Hostname(), "token", credentials)]
+	if len(token) == 0 {
+		return nil, fmt.Errorf("unable to access %s, the Pipelines as Code secret %s has no GitHub token", component.Spec.Source.GitSource.URL, prepare.PipelinesAsCodeSecretName)
+	}
+
+	httpClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: string(token)}))
+	apiURL, err := appservicegitops.GetGitProviderURL(component, "github")
+	if err != nil {
+		return nil, err
+	}
+	if apiURL == "" {
+		return gh.NewClient(httpClient), nil
+	}
+	return gh.NewEnterpriseClient(apiURL, apiURL, httpClient)
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// registryAuthSourcesAnnotation records the resourceVersions of the registry secrets merged into the registry auth secret,
+// keyed by secret name, so that the merged secret is refreshed when one of them changes
+const registryAuthSourcesAnnotation = "appstudio.redhat.com/registry-auth-sources"
+
+// ensureRegistryAuthSecret merges the registry secrets of the component into the Secret bound to the registry-auth workspace
+// of its builds, when it has several of them. A registry found in several secrets keeps the credentials of the first one.
+func (r *ComponentReconciler) ensureRegistryAuthSecret(ctx context.Context, component *appstudiov1alpha1.Component, gitopsConfig prepare.GitopsConfig) error {
+	secretNames := appservicegitops.GetRegistryAuthSecrets(*component, gitopsConfig)
+	if len(secretNames) < 2 {
+		return nil
+	}
+
+	auths := make(map[string]json.RawMessage)
+	sources := make(map[string]string)
+	for _, secretName := range secretNames {
+		registrySecret := &corev1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: component.Namespace}, registrySecret); err != nil {
+			return fmt.Errorf("unable to retrieve the registry secret %s: %v", secretName, err)
+		}
+		secretAuths, err := getDockerConfigAuths(registrySecret)
+		if err != nil {
+			return fmt.Errorf("unable to read the registry secret %s: %v", secretName, err)
+		}
+		sources[secretName] = registrySecret.ResourceVersion
+		for registry, auth := range secretAuths {
+			if _, isPresent := auths[registry]; !isPresent {
+				auths[registry] = auth
+			}
+		}
+	}
+	dockerConfig, err := json.Marshal(map[string]map[string]json.RawMessage{"auths": auths})
+	if err != nil {
+		return err
+	}
+	sourceVersions, err := json.Marshal(sources)
+	if err != nil {
+		return err
+	}
+
+	mergedSecretName := appservicegitops.GetMergedRegistryAuthSecretName(*component)
+	mergedSecret := &corev1.Secret{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: mergedSecretName, Namespace: component.Namespace}, mergedSecret)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("unable to retrieve the registry auth secret %s: %v", mergedSecretName, err)
+		}
+		mergedSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      mergedSecretName,
+				Namespace: component.Namespace,
+				Annotations: map[string]string{
+					registryAuthSourcesAnnotation: string(sourceVersions),
+				},
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: dockerConfig,
+			},
+		}
+		if err := controllerutil.SetOwnerReference(component, mergedSecret, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Client.Create(ctx, mergedSecret); err != nil {
+			return fmt.Errorf("unable to create the registry auth secret %s: %v", mergedSecretName, err)
+		}
+	} else if string(mergedSecret.Data[corev1.DockerConfigJsonKey]) != string(dockerConfig) || mergedSecret.Annotations[registryAuthSourcesAnnotation] != string(sourceVersions) {
+		mergedSecret.Data = map[string][]byte{
+			corev1.DockerConfigJsonKey: dockerConfig,
+		}
+		if mergedSecret.Annotations == nil {
+			mergedSecret.Annotations = make(map[string]string)
+		}
+		mergedSecret.Annotations[registryAuthSourcesAnnotation] = string(sourceVersions)
+		if err := r.Client.Update(ctx, mergedSecret); err != nil {
+			return fmt.Errorf("unable to update the registry auth secret %s: %v", mergedSecretName, err)
+		}
+	}
+	return nil
+}
+
+// isRegistryAuthSecretStale returns true if one of the registry secrets merged into the registry auth secret of the
+// component changed, or was deleted, since they were merged. Components without a merged secret are never stale.
+func (r *ComponentReconciler) isRegistryAuthSecretStale(ctx context.Context, component appstudiov1alpha1.Component) bool {
+	mergedSecret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: appservicegitops.GetMergedRegistryAuthSecretName(component), Namespace: component.Namespace}, mergedSecret); err != nil {
+		return false
+	}
+	sources, err := getRegistryAuthSources(mergedSecret)
+	if err != nil || len(sources) == 0 {
+		// The secrets merged before their versions were recorded are merged again
+		return true
+	}
+	for secretName, resourceVersion := range sources {
+		registrySecret := &corev1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: component.Namespace}, registrySecret); err != nil || registrySecret.ResourceVersion != resourceVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// getRegistryAuthSources returns the resourceVersions of the registry secrets merged into a registry auth secret, keyed by secret name
+func getRegistryAuthSources(mergedSecret *corev1.Secret) (map[string]string, error) {
+	sources := make(map[string]string)
+	if annotation, isPresent := mergedSecret.Annotations[registryAuthSourcesAnnotation]; isPresent {
+		if err := json.Unmarshal([]byte(annotation), &sources); err != nil {
+			return nil, err
+		}
+	}
+	return sources, nil
+}
+
+// getDockerConfigAuths returns the credentials of the registries held by a kubernetes.io/dockerconfigjson
+// or kubernetes.io/dockercfg Secret, keyed by registry
+func getDockerConfigAuths(secret *corev1.Secret) (map[string]json.RawMessage, error) {
+	if dockerConfigJSON, isPresent := secret.Data[corev1.DockerConfigJsonKey]; isPresent {
+		dockerConfig := struct {
+			Auths map[string]json.RawMessage `json:"auths"`
+		}{}
+		if err := json.Unmarshal(dockerConfigJSON, &dockerConfig); err != nil {
+			return nil, err
+		}
+		return dockerConfig.Auths, nil
+	}
+	if dockerCfg, isPresent := secret.Data[corev1.DockerConfigKey]; isPresent {
+		auths := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(dockerCfg, &auths); err != nil {
+			return nil, err
+		}
+		return auths, nil
+	}
+	return nil, fmt.Errorf("the secret holds neither a %s nor a %s key", corev1.DockerConfigJsonKey, corev1.DockerConfigKey)
+}
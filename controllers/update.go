@@ -19,8 +19,11 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"math"
+	"path"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -265,7 +268,7 @@ func (r *ComponentReconciler) updateApplicationDevfileModel(hasAppDevfileData da
 	return nil
 }
 
-func (r *ComponentDetectionQueryReconciler) updateComponentStub(req ctrl.Request, ctx context.Context, componentDetectionQuery *appstudiov1alpha1.ComponentDetectionQuery, devfilesMap map[string][]byte, devfilesURLMap map[string]string, dockerfileContextMap map[string]string) error {
+func (r *ComponentDetectionQueryReconciler) updateComponentStub(req ctrl.Request, ctx context.Context, componentDetectionQuery *appstudiov1alpha1.ComponentDetectionQuery, devfilesMap map[string][]byte, devfilesURLMap map[string]string, dockerfileContextMap map[string]string, dockerfileCandidatesMap map[string][]string) error {
 
 	if componentDetectionQuery == nil {
 		return fmt.Errorf("componentDetectionQuery is nil")
@@ -302,6 +305,7 @@ func (r *ComponentDetectionQueryReconciler) updateComponentStub(req ctrl.Request
 		gitSource := &appstudiov1alpha1.GitSource{
 			Context:       context,
 			URL:           componentDetectionQuery.Spec.GitSource.URL,
+			Revision:      componentDetectionQuery.Spec.GitSource.Revision,
 			DevfileURL:    devfilesURLMap[context],
 			DockerfileURL: dockerfileContextMap[context],
 		}
@@ -476,6 +480,7 @@ func (r *ComponentDetectionQueryReconciler) updateComponentStub(req ctrl.Request
 		gitSource := &appstudiov1alpha1.GitSource{
 			Context:       context,
 			URL:           componentDetectionQuery.Spec.GitSource.URL,
+			Revision:      componentDetectionQuery.Spec.GitSource.Revision,
 			DockerfileURL: link,
 		}
 		componentName := getComponentName(ctx, gitSource, r.Client, req.Namespace)
@@ -493,12 +498,140 @@ func (r *ComponentDetectionQueryReconciler) updateComponentStub(req ctrl.Request
 					},
 				},
 			},
+			DockerfileCandidates: dockerfileCandidatesMap[context],
 		}
 	}
 
 	return nil
 }
 
+// updateDeploymentComponentStubs adds a component stub per compose service, Kubernetes manifests directory and Helm chart.
+// The descriptors are skipped for the contexts with a devfile, and replace the dockerfile only components of their contexts.
+// The manifests and charts nested in a devfile or dockerfile component, or referenced by a devfile, deploy that component
+// and are skipped too.
+func (r *ComponentDetectionQueryReconciler) updateDeploymentComponentStubs(req ctrl.Request, ctx context.Context, componentDetectionQuery *appstudiov1alpha1.ComponentDetectionQuery, deploymentComponents []devfile.DeploymentComponent, devfilesMap map[string][]byte, dockerfileContextMap map[string]string, dockerfileCandidatesMap map[string][]string) error {
+
+	if componentDetectionQuery == nil {
+		return fmt.Errorf("componentDetectionQuery is nil")
+	}
+
+	log := r.Log.WithValues("ComponentDetectionQuery", req.NamespacedName).WithValues("clusterName", req.ClusterName)
+
+	if len(componentDetectionQuery.Status.ComponentDetected) == 0 {
+		componentDetectionQuery.Status.ComponentDetected = make(appstudiov1alpha1.ComponentDetectionMap)
+	}
+
+	log.Info(fmt.Sprintf("Deployment descriptors detected: %v", len(deploymentComponents)))
+
+	// The directories of the manifests referenced by the devfiles
+	referencedDirs := make(map[string]bool)
+	for context, devfileBytes := range devfilesMap {
+		uris, err := devfile.SearchForDeploymentManifests(devfileBytes)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Unable to read the deployment manifests of the devfile of %s", context))
+			continue
+		}
+		for _, uri := range uris {
+			if !strings.HasPrefix(uri, "http") {
+				referencedDirs[path.Dir(path.Join(context, uri))] = true
+			}
+		}
+	}
+
+	var replacedContexts []string
+	for _, deploymentComponent := range deploymentComponents {
+		context := deploymentComponent.Context
+		if _, isDevfilePresent := devfilesMap[context]; context != "" && isDevfilePresent {
+			log.Info(fmt.Sprintf("Skipping the %s component %s, the context %s has a devfile", deploymentComponent.ProjectType, deploymentComponent.Name, context))
+			continue
+		}
+		if deploymentComponent.ProjectType != devfile.ComposeProjectType {
+			if referencedDirs[path.Clean(context)] {
+				log.Info(fmt.Sprintf("Skipping the %s component %s, the context %s is referenced by a devfile", deploymentComponent.ProjectType, deploymentComponent.Name, context))
+				continue
+			}
+			if parentContext := getParentComponentContext(context, devfilesMap, dockerfileContextMap); parentContext != "" {
+				log.Info(fmt.Sprintf("Skipping the %s component %s, the context %s is nested in the component %s", deploymentComponent.ProjectType, deploymentComponent.Name, context, parentContext))
+				continue
+			}
+		}
+		if context != "" {
+			replacedContexts = append(replacedContexts, context)
+		}
+
+		componentName := sanitizeComponentName(ctx, deploymentComponent.Name, r.Client, req.Namespace)
+		componentStub := appstudiov1alpha1.ComponentSpec{
+			ComponentName:  componentName,
+			Application:    "insert-application-name",
+			ContainerImage: deploymentComponent.Image,
+			TargetPort:     deploymentComponent.TargetPort,
+			Env:            deploymentComponent.Env,
+		}
+		var dockerfileCandidates []string
+		// The components deployed from an image have no source
+		if context != "" {
+			dockerfileURL := dockerfileContextMap[context]
+			dockerfileCandidates = dockerfileCandidatesMap[context]
+			if deploymentComponent.DockerfileURI != "" {
+				link, err := devfile.UpdateDockerfileLink(componentDetectionQuery.Spec.GitSource.URL, componentDetectionQuery.Spec.GitSource.Revision, deploymentComponent.DockerfileURI)
+				if err != nil {
+					return err
+				}
+				dockerfileURL = link
+				dockerfileCandidates = nil
+			}
+			componentStub.Source = appstudiov1alpha1.ComponentSource{
+				ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+					GitSource: &appstudiov1alpha1.GitSource{
+						Context:       context,
+						URL:           componentDetectionQuery.Spec.GitSource.URL,
+						Revision:      componentDetectionQuery.Spec.GitSource.Revision,
+						DockerfileURL: dockerfileURL,
+					},
+				},
+			}
+		}
+
+		componentDetectionQuery.Status.ComponentDetected[componentName] = appstudiov1alpha1.ComponentDetectionDescription{
+			DevfileFound:         false, // always false since the descriptors are only used without devfile
+			ProjectType:          deploymentComponent.ProjectType,
+			ComponentStub:        componentStub,
+			DockerfileCandidates: dockerfileCandidates,
+		}
+	}
+
+	// Once all the descriptors have been processed, remove the dockerfile only components they replace
+	for _, context := range replacedContexts {
+		delete(dockerfileContextMap, context)
+	}
+
+	return nil
+}
+
+// getParentComponentContext returns the context of the devfile or dockerfile component the context is nested in, or an
+// empty string if it is not nested in any. Every other context is nested in a component at the root of the repository.
+func getParentComponentContext(context string, devfilesMap map[string][]byte, dockerfileContextMap map[string]string) string {
+	context = path.Clean(context)
+	if context == "." {
+		return ""
+	}
+	isParent := func(componentContext string) bool {
+		componentContext = path.Clean(componentContext)
+		return componentContext == "." || strings.HasPrefix(context, componentContext+"/")
+	}
+	for componentContext := range devfilesMap {
+		if isParent(componentContext) {
+			return componentContext
+		}
+	}
+	for componentContext := range dockerfileContextMap {
+		if isParent(componentContext) {
+			return componentContext
+		}
+	}
+	return ""
+}
+
 func getComponentName(ctx context.Context, gitSource *appstudiov1alpha1.GitSource, client client.Client, namespace string) string {
 	repoUrl := gitSource.URL
 	lastElement := repoUrl[strings.LastIndex(repoUrl, "/")+1:]
@@ -506,11 +639,139 @@ func getComponentName(ctx context.Context, gitSource *appstudiov1alpha1.GitSourc
 	componentName := repoName
 	context := gitSource.Context
 	if context != "" && context != "./" {
+		// The contexts of nested components, e.g. services/api, are flattened into the name
+		context = strings.ReplaceAll(strings.Trim(path.Clean(context), "/"), "/", "-")
 		componentName = fmt.Sprintf("%s-%s", context, repoName)
 	}
 	return sanitizeComponentName(ctx, componentName, client, namespace)
 }
 
+// getSourceContext returns the context of the git source relative to the root of the repository, empty for the root itself
+func getSourceContext(context string) string {
+	context = strings.Trim(path.Clean(context), "/")
+	if context == "." {
+		return ""
+	}
+	return context
+}
+
+// addSourceContext prefixes the contexts detected under the source context, and the relative Dockerfile links,
+// with the source context so that they are relative to the root of the repository
+func addSourceContext(sourceContext string, devfilesMap map[string][]byte, devfilesURLMap map[string]string, dockerfileContextMap map[string]string, dockerfileCandidatesMap map[string][]string) (map[string][]byte, map[string]string, map[string]string, map[string][]string) {
+	if sourceContext == "" {
+		return devfilesMap, devfilesURLMap, dockerfileContextMap, dockerfileCandidatesMap
+	}
+	addContext := func(context string) string {
+		return path.Join(sourceContext, context)
+	}
+
+	updatedDevfilesMap := make(map[string][]byte)
+	for context, devfileBytes := range devfilesMap {
+		updatedDevfilesMap[addContext(context)] = devfileBytes
+	}
+	updatedDevfilesURLMap := make(map[string]string)
+	for context, devfileURL := range devfilesURLMap {
+		updatedDevfilesURLMap[addContext(context)] = devfileURL
+	}
+	updatedDockerfileContextMap := make(map[string]string)
+	for context, link := range dockerfileContextMap {
+		if !strings.HasPrefix(link, "http") {
+			link = addContext(link)
+		}
+		updatedDockerfileContextMap[addContext(context)] = link
+	}
+	// The dockerfile candidates remain relative to the context of their component
+	updatedDockerfileCandidatesMap := make(map[string][]string)
+	for context, dockerfiles := range dockerfileCandidatesMap {
+		updatedDockerfileCandidatesMap[addContext(context)] = dockerfiles
+	}
+	return updatedDevfilesMap, updatedDevfilesURLMap, updatedDockerfileContextMap, updatedDockerfileCandidatesMap
+}
+
+// addSourceContextToDeploymentComponents prefixes the contexts and the Dockerfiles of the components detected from the
+// deployment descriptors under the source context with the source context
+func addSourceContextToDeploymentComponents(sourceContext string, deploymentComponents []devfile.DeploymentComponent) []devfile.DeploymentComponent {
+	if sourceContext == "" {
+		return deploymentComponents
+	}
+	var updatedDeploymentComponents []devfile.DeploymentComponent
+	for _, deploymentComponent := range deploymentComponents {
+		if deploymentComponent.Context != "" {
+			deploymentComponent.Context = path.Join(sourceContext, deploymentComponent.Context)
+		}
+		if deploymentComponent.DockerfileURI != "" {
+			deploymentComponent.DockerfileURI = path.Join(sourceContext, deploymentComponent.DockerfileURI)
+		}
+		updatedDeploymentComponents = append(updatedDeploymentComponents, deploymentComponent)
+	}
+	return updatedDeploymentComponents
+}
+
+// getDetectedContexts returns the sorted contexts of the components detected from devfiles, dockerfiles and deployment descriptors
+func getDetectedContexts(devfilesMap map[string][]byte, dockerfileContextMap map[string]string, deploymentComponents []devfile.DeploymentComponent) []string {
+	contextSet := make(map[string]bool)
+	for context := range devfilesMap {
+		contextSet[context] = true
+	}
+	for context := range dockerfileContextMap {
+		contextSet[context] = true
+	}
+	for _, deploymentComponent := range deploymentComponents {
+		if deploymentComponent.Context != "" {
+			contextSet[deploymentComponent.Context] = true
+		}
+	}
+
+	var contexts []string
+	for context := range contextSet {
+		contexts = append(contexts, context)
+	}
+	sort.Strings(contexts)
+	return contexts
+}
+
+// addComponentAnalyses reports the languages and the devfile candidates of the contexts of the detected components
+func addComponentAnalyses(componentDetectionQuery *appstudiov1alpha1.ComponentDetectionQuery, componentAnalyses map[string]devfile.ComponentAnalysis) {
+	for componentName, detectionDescription := range componentDetectionQuery.Status.ComponentDetected {
+		gitSource := detectionDescription.ComponentStub.Source.GitSource
+		if gitSource == nil {
+			continue
+		}
+		componentAnalysis, ok := componentAnalyses[gitSource.Context]
+		if !ok {
+			continue
+		}
+
+		detectionDescription.Languages = nil
+		for _, language := range componentAnalysis.Languages {
+			detectionDescription.Languages = append(detectionDescription.Languages, appstudiov1alpha1.DetectedLanguage{
+				Name:            language.Name,
+				UsagePercentage: int(math.Round(language.UsageInPercentage)),
+				Frameworks:      language.Frameworks,
+				Tools:           language.Tools,
+			})
+		}
+		sort.SliceStable(detectionDescription.Languages, func(i, j int) bool {
+			return detectionDescription.Languages[i].UsagePercentage > detectionDescription.Languages[j].UsagePercentage
+		})
+
+		detectionDescription.DevfileCandidates = nil
+		for _, candidate := range componentAnalysis.DevfileCandidates {
+			detectionDescription.DevfileCandidates = append(detectionDescription.DevfileCandidates, appstudiov1alpha1.DevfileCandidate{
+				Name:        candidate.Name,
+				DevfileURL:  candidate.DevfileURL,
+				Language:    candidate.Language,
+				ProjectType: candidate.ProjectType,
+				Confidence:  candidate.Confidence,
+				Selected:    candidate.Selected,
+				Frameworks:  candidate.Frameworks,
+				Tools:       candidate.Tools,
+			})
+		}
+		componentDetectionQuery.Status.ComponentDetected[componentName] = detectionDescription
+	}
+}
+
 // sanitizeComponentName sanitizes component name with the following requirements:
 // - Contain at most 63 characters
 // - Contain only lowercase alphanumeric characters or ‘-’
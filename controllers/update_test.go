@@ -27,6 +27,8 @@ import (
 	v2 "github.com/devfile/library/pkg/devfile/parser/data/v2"
 	"github.com/devfile/library/pkg/devfile/parser/data/v2/common"
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	devfilePkg "github.com/redhat-appstudio/application-service/pkg/devfile"
+	"github.com/redhat-developer/alizer/go/pkg/apis/language"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -662,12 +664,13 @@ func TestUpdateComponentStub(t *testing.T) {
 	}
 
 	tests := []struct {
-		name             string
-		devfilesDataMap  map[string]*v2.DevfileV2
-		devfilesURLMap   map[string]string
-		dockerfileURLMap map[string]string
-		isNil            bool
-		wantErr          bool
+		name                    string
+		devfilesDataMap         map[string]*v2.DevfileV2
+		devfilesURLMap          map[string]string
+		dockerfileURLMap        map[string]string
+		dockerfileCandidatesMap map[string][]string
+		isNil                   bool
+		wantErr                 bool
 	}{
 		{
 			name: "Kubernetes Components present",
@@ -750,6 +753,17 @@ func TestUpdateComponentStub(t *testing.T) {
 				"./": "http://someotherlink",
 			},
 		},
+		{
+			name: "dockerfile URL with several candidates",
+			dockerfileURLMap: map[string]string{
+				"./":       "http://someotherlink/Containerfile",
+				"services": "http://someotherlink/services/Dockerfile",
+			},
+			dockerfileCandidatesMap: map[string][]string{
+				"./":       {"Containerfile", "Dockerfile.jvm", "docker/Dockerfile"},
+				"services": {"Dockerfile"},
+			},
+		},
 		{
 			name: "No Kubernetes Components present",
 			devfilesDataMap: map[string]*v2.DevfileV2{
@@ -1172,9 +1186,9 @@ func TestUpdateComponentStub(t *testing.T) {
 			}
 			var err error
 			if tt.isNil {
-				err = r.updateComponentStub(ctrl.Request{}, nil, nil, devfilesMap, nil, nil)
+				err = r.updateComponentStub(ctrl.Request{}, nil, nil, devfilesMap, nil, nil, nil)
 			} else {
-				err = r.updateComponentStub(ctrl.Request{}, nil, &componentDetectionQuery, devfilesMap, tt.devfilesURLMap, tt.dockerfileURLMap)
+				err = r.updateComponentStub(ctrl.Request{}, nil, &componentDetectionQuery, devfilesMap, tt.devfilesURLMap, tt.dockerfileURLMap, tt.dockerfileCandidatesMap)
 			}
 
 			if tt.wantErr && (err == nil) {
@@ -1186,6 +1200,9 @@ func TestUpdateComponentStub(t *testing.T) {
 					// Application Name
 					assert.Equal(t, hasCompDetection.ComponentStub.Application, "insert-application-name", "The application name should match the generic name")
 
+					// Dockerfile candidates
+					assert.Equal(t, tt.dockerfileCandidatesMap[hasCompDetection.ComponentStub.Source.GitSource.Context], hasCompDetection.DockerfileCandidates, "The dockerfile candidates should match")
+
 					if len(tt.devfilesDataMap) != 0 {
 						// Language
 						assert.Equal(t, hasCompDetection.Language, tt.devfilesDataMap[hasCompDetection.ComponentStub.Source.GitSource.Context].Metadata.Language, "The language should be the same")
@@ -1381,6 +1398,15 @@ func TestGetComponentName(t *testing.T) {
 			client:       fakeClientNoError,
 			expectedName: "nodejs-devfile-multi-component",
 		},
+		{
+			name: "valid repo name with nested context",
+			gitSource: &appstudiov1alpha1.GitSource{
+				URL:     "https://github.com/devfile-samples/devfile-multi-component",
+				Context: "services/api/",
+			},
+			client:       fakeClientNoError,
+			expectedName: "services-api-devfile-multi-component",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1396,3 +1422,301 @@ func TestGetComponentName(t *testing.T) {
 	}
 
 }
+
+func TestAddSourceContext(t *testing.T) {
+	tests := []struct {
+		name                        string
+		sourceContext               string
+		devfilesMap                 map[string][]byte
+		devfilesURLMap              map[string]string
+		dockerfileContextMap        map[string]string
+		dockerfileCandidatesMap     map[string][]string
+		wantDevfilesMap             map[string][]byte
+		wantDevfilesURLMap          map[string]string
+		wantDockerfileContextMap    map[string]string
+		wantDockerfileCandidatesMap map[string][]string
+	}{
+		{
+			name:                        "root of the repository",
+			sourceContext:               getSourceContext("./"),
+			devfilesMap:                 map[string][]byte{"./": []byte("devfile")},
+			devfilesURLMap:              map[string]string{"./": "https://registry/devfile.yaml"},
+			dockerfileContextMap:        map[string]string{"./": "./Dockerfile"},
+			dockerfileCandidatesMap:     map[string][]string{},
+			wantDevfilesMap:             map[string][]byte{"./": []byte("devfile")},
+			wantDevfilesURLMap:          map[string]string{"./": "https://registry/devfile.yaml"},
+			wantDockerfileContextMap:    map[string]string{"./": "./Dockerfile"},
+			wantDockerfileCandidatesMap: map[string][]string{},
+		},
+		{
+			name:          "component at the context",
+			sourceContext: getSourceContext("/services/api/"),
+			devfilesMap:   map[string][]byte{"./": []byte("devfile")},
+			devfilesURLMap: map[string]string{
+				"./": "https://registry/devfile.yaml",
+			},
+			dockerfileContextMap:    map[string]string{"./": "./Dockerfile"},
+			dockerfileCandidatesMap: map[string][]string{},
+			wantDevfilesMap:         map[string][]byte{"services/api": []byte("devfile")},
+			wantDevfilesURLMap: map[string]string{
+				"services/api": "https://registry/devfile.yaml",
+			},
+			wantDockerfileContextMap:    map[string]string{"services/api": "services/api/Dockerfile"},
+			wantDockerfileCandidatesMap: map[string][]string{},
+		},
+		{
+			name:           "components under the context",
+			sourceContext:  getSourceContext("services"),
+			devfilesMap:    map[string][]byte{"api": []byte("devfile")},
+			devfilesURLMap: map[string]string{},
+			dockerfileContextMap: map[string]string{
+				"api": "https://registry/Dockerfile",
+				"web": "web/Containerfile",
+			},
+			dockerfileCandidatesMap: map[string][]string{
+				"web": {"Containerfile", "docker/Dockerfile"},
+			},
+			wantDevfilesMap:    map[string][]byte{"services/api": []byte("devfile")},
+			wantDevfilesURLMap: map[string]string{},
+			wantDockerfileContextMap: map[string]string{
+				"services/api": "https://registry/Dockerfile",
+				"services/web": "services/web/Containerfile",
+			},
+			wantDockerfileCandidatesMap: map[string][]string{
+				"services/web": {"Containerfile", "docker/Dockerfile"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfilesMap, devfilesURLMap, dockerfileContextMap, dockerfileCandidatesMap := addSourceContext(tt.sourceContext, tt.devfilesMap, tt.devfilesURLMap, tt.dockerfileContextMap, tt.dockerfileCandidatesMap)
+			assert.Equal(t, tt.wantDevfilesMap, devfilesMap, "The devfile contexts should match")
+			assert.Equal(t, tt.wantDevfilesURLMap, devfilesURLMap, "The devfile URL contexts should match")
+			assert.Equal(t, tt.wantDockerfileContextMap, dockerfileContextMap, "The Dockerfile contexts should match")
+			assert.Equal(t, tt.wantDockerfileCandidatesMap, dockerfileCandidatesMap, "The Dockerfile candidate contexts should match")
+		})
+	}
+}
+
+func TestUpdateDeploymentComponentStubs(t *testing.T) {
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zap.Options{
+		Development: true,
+	})))
+	fakeClient := NewFakeClient(t)
+	fakeClient.MockGet = func(ctx context.Context, key types.NamespacedName, obj client.Object) error {
+		return nil
+	}
+	r := ComponentDetectionQueryReconciler{
+		Client: fakeClient,
+		Log:    ctrl.Log.WithName("TestUpdateDeploymentComponentStubs"),
+	}
+
+	deploymentComponents := []devfilePkg.DeploymentComponent{
+		{
+			Name:          "web",
+			ProjectType:   devfilePkg.ComposeProjectType,
+			Context:       "web",
+			DockerfileURI: "web/Dockerfile",
+			TargetPort:    8080,
+			Env:           []corev1.EnvVar{{Name: "MODE", Value: "production"}},
+		},
+		{
+			Name:        "db",
+			ProjectType: devfilePkg.ComposeProjectType,
+			Image:       "postgres:14",
+			TargetPort:  5432,
+		},
+		{
+			Name:        "admin",
+			ProjectType: devfilePkg.ComposeProjectType,
+			Context:     "admin",
+		},
+		{
+			Name:        "api",
+			ProjectType: devfilePkg.KubernetesProjectType,
+			Context:     "api",
+			Image:       "quay.io/org/api",
+		},
+		{
+			Name:        "chart",
+			ProjectType: devfilePkg.HelmProjectType,
+			Context:     "deploy/chart",
+		},
+		{
+			Name:        "frontend",
+			ProjectType: devfilePkg.KubernetesProjectType,
+			Context:     "deploy/frontend",
+		},
+		{
+			Name:        "other",
+			ProjectType: devfilePkg.KubernetesProjectType,
+			Context:     "other/kubernetes",
+		},
+	}
+	frontendDevfile := `schemaVersion: 2.2.0
+metadata:
+  name: frontend
+components:
+  - name: outerloop-deploy
+    kubernetes:
+      uri: ../deploy/frontend/deploy.yaml
+`
+	devfilesMap := map[string][]byte{"admin": []byte("devfile"), "frontend": []byte(frontendDevfile)}
+	dockerfileContextMap := map[string]string{
+		"web":   "https://raw.githubusercontent.com/org/repo/main/web/Dockerfile.jvm",
+		"api":   "https://raw.githubusercontent.com/org/repo/main/api/Containerfile",
+		"admin": "https://raw.githubusercontent.com/org/repo/main/admin/Dockerfile",
+		"other": "https://raw.githubusercontent.com/org/repo/main/other/Dockerfile",
+	}
+	dockerfileCandidatesMap := map[string][]string{
+		"web": {"Dockerfile.jvm"},
+		"api": {"Containerfile", "docker/Dockerfile"},
+	}
+	gitSource := func(context, dockerfileURL string) appstudiov1alpha1.ComponentSource {
+		return appstudiov1alpha1.ComponentSource{
+			ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+				GitSource: &appstudiov1alpha1.GitSource{
+					URL:           "https://github.com/org/repo",
+					Revision:      "main",
+					Context:       context,
+					DockerfileURL: dockerfileURL,
+				},
+			},
+		}
+	}
+	want := appstudiov1alpha1.ComponentDetectionMap{
+		"web": {
+			ProjectType: devfilePkg.ComposeProjectType,
+			ComponentStub: appstudiov1alpha1.ComponentSpec{
+				ComponentName: "web",
+				Application:   "insert-application-name",
+				Source:        gitSource("web", "https://raw.githubusercontent.com/org/repo/main/web/Dockerfile"),
+				TargetPort:    8080,
+				Env:           []corev1.EnvVar{{Name: "MODE", Value: "production"}},
+			},
+		},
+		"db": {
+			ProjectType: devfilePkg.ComposeProjectType,
+			ComponentStub: appstudiov1alpha1.ComponentSpec{
+				ComponentName:  "db",
+				Application:    "insert-application-name",
+				ContainerImage: "postgres:14",
+				TargetPort:     5432,
+			},
+		},
+		"api": {
+			ProjectType: devfilePkg.KubernetesProjectType,
+			ComponentStub: appstudiov1alpha1.ComponentSpec{
+				ComponentName:  "api",
+				Application:    "insert-application-name",
+				Source:         gitSource("api", "https://raw.githubusercontent.com/org/repo/main/api/Containerfile"),
+				ContainerImage: "quay.io/org/api",
+			},
+			DockerfileCandidates: []string{"Containerfile", "docker/Dockerfile"},
+		},
+		"chart": {
+			ProjectType: devfilePkg.HelmProjectType,
+			ComponentStub: appstudiov1alpha1.ComponentSpec{
+				ComponentName: "chart",
+				Application:   "insert-application-name",
+				Source:        gitSource("deploy/chart", ""),
+			},
+		},
+	}
+
+	componentDetectionQuery := appstudiov1alpha1.ComponentDetectionQuery{
+		Spec: appstudiov1alpha1.ComponentDetectionQuerySpec{
+			GitSource: appstudiov1alpha1.GitSource{
+				URL:      "https://github.com/org/repo",
+				Revision: "main",
+			},
+		},
+	}
+	err := r.updateDeploymentComponentStubs(ctrl.Request{}, context.Background(), nil, deploymentComponents, devfilesMap, dockerfileContextMap, dockerfileCandidatesMap)
+	assert.NotNil(t, err, "A nil ComponentDetectionQuery should return an error")
+
+	err = r.updateDeploymentComponentStubs(ctrl.Request{}, context.Background(), &componentDetectionQuery, deploymentComponents, devfilesMap, dockerfileContextMap, dockerfileCandidatesMap)
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, want, componentDetectionQuery.Status.ComponentDetected, "The detected components should match")
+	assert.Equal(t, map[string]string{
+		"admin": "https://raw.githubusercontent.com/org/repo/main/admin/Dockerfile",
+		"other": "https://raw.githubusercontent.com/org/repo/main/other/Dockerfile",
+	}, dockerfileContextMap, "The replaced dockerfile only components should be removed")
+
+	// The descriptors of a component at the root of the repository deploy that component
+	componentDetectionQuery.Status.ComponentDetected = nil
+	err = r.updateDeploymentComponentStubs(ctrl.Request{}, context.Background(), &componentDetectionQuery, []devfilePkg.DeploymentComponent{
+		{Name: "kubernetes", ProjectType: devfilePkg.KubernetesProjectType, Context: "kubernetes"},
+	}, map[string][]byte{"./": []byte(frontendDevfile)}, map[string]string{}, map[string][]string{})
+	assert.Nil(t, err, "err should be nil")
+	assert.Empty(t, componentDetectionQuery.Status.ComponentDetected, "The descriptors nested in the root component should be skipped")
+
+	assert.Equal(t, []devfilePkg.DeploymentComponent{
+		{Name: "web", ProjectType: devfilePkg.ComposeProjectType, Context: "services/web", DockerfileURI: "services/web/Dockerfile"},
+		{Name: "db", ProjectType: devfilePkg.ComposeProjectType, Image: "postgres:14"},
+		{Name: "app", ProjectType: devfilePkg.ComposeProjectType, Context: "services", DockerfileURI: "services/Dockerfile"},
+	}, addSourceContextToDeploymentComponents("services", []devfilePkg.DeploymentComponent{
+		{Name: "web", ProjectType: devfilePkg.ComposeProjectType, Context: "web", DockerfileURI: "web/Dockerfile"},
+		{Name: "db", ProjectType: devfilePkg.ComposeProjectType, Image: "postgres:14"},
+		{Name: "app", ProjectType: devfilePkg.ComposeProjectType, Context: "./", DockerfileURI: "Dockerfile"},
+	}), "The contexts should be relative to the root of the repository")
+}
+
+func TestAddComponentAnalyses(t *testing.T) {
+	contexts := getDetectedContexts(map[string][]byte{"api": nil, "./": nil}, map[string]string{"api": "", "web": ""}, []devfilePkg.DeploymentComponent{
+		{Name: "db", Image: "postgres"},
+		{Name: "worker", Context: "worker"},
+	})
+	assert.Equal(t, []string{"./", "api", "web", "worker"}, contexts, "The detected contexts should match")
+
+	gitComponent := func(context string) appstudiov1alpha1.ComponentDetectionDescription {
+		return appstudiov1alpha1.ComponentDetectionDescription{
+			ComponentStub: appstudiov1alpha1.ComponentSpec{
+				Source: appstudiov1alpha1.ComponentSource{
+					ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+						GitSource: &appstudiov1alpha1.GitSource{Context: context},
+					},
+				},
+			},
+		}
+	}
+	componentDetectionQuery := appstudiov1alpha1.ComponentDetectionQuery{
+		Status: appstudiov1alpha1.ComponentDetectionQueryStatus{
+			ComponentDetected: appstudiov1alpha1.ComponentDetectionMap{
+				"api": gitComponent("api"),
+				"web": gitComponent("web"),
+				"db":  {ComponentStub: appstudiov1alpha1.ComponentSpec{ContainerImage: "postgres"}},
+			},
+		},
+	}
+	addComponentAnalyses(&componentDetectionQuery, map[string]devfilePkg.ComponentAnalysis{
+		"api": {
+			Languages: []language.Language{
+				{Name: "Python", UsageInPercentage: 12.6},
+				{Name: "Java", UsageInPercentage: 87.4, Frameworks: []string{"Spring"}, Tools: []string{"Maven"}},
+			},
+			DevfileCandidates: []devfilePkg.DevfileCandidate{
+				{Name: "java-springboot-basic", DevfileURL: "https://registry/devfiles/java-springboot-basic", Language: "java", ProjectType: "springboot",
+					Confidence: 90, Selected: true, Frameworks: []string{"Spring"}, Tools: []string{"Maven"}},
+				{Name: "python-basic", DevfileURL: "https://registry/devfiles/python-basic", Language: "python", ProjectType: "python", Confidence: 10},
+			},
+		},
+	})
+
+	wantAPI := gitComponent("api")
+	wantAPI.Languages = []appstudiov1alpha1.DetectedLanguage{
+		{Name: "Java", UsagePercentage: 87, Frameworks: []string{"Spring"}, Tools: []string{"Maven"}},
+		{Name: "Python", UsagePercentage: 13},
+	}
+	wantAPI.DevfileCandidates = []appstudiov1alpha1.DevfileCandidate{
+		{Name: "java-springboot-basic", DevfileURL: "https://registry/devfiles/java-springboot-basic", Language: "java", ProjectType: "springboot",
+			Confidence: 90, Selected: true, Frameworks: []string{"Spring"}, Tools: []string{"Maven"}},
+		{Name: "python-basic", DevfileURL: "https://registry/devfiles/python-basic", Language: "python", ProjectType: "python", Confidence: 10},
+	}
+	assert.Equal(t, appstudiov1alpha1.ComponentDetectionMap{
+		"api": wantAPI,
+		"web": gitComponent("web"),
+		"db":  {ComponentStub: appstudiov1alpha1.ComponentSpec{ContainerImage: "postgres"}},
+	}, componentDetectionQuery.Status.ComponentDetected, "The languages and the devfile candidates should be reported for the analyzed contexts")
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	gitopsprepare "github.com/redhat-appstudio/application-service/gitops/prepare"
+)
+
+// registryAuthWorkspace is the workspace of the build pipelines holding the credentials of the image registries
+const registryAuthWorkspace = "registry-auth"
+
+// getBuildCredentials returns the build credentials configured for the component, or for its Application
+func getBuildCredentials(component appstudiov1alpha1.Component, gitopsConfig gitopsprepare.GitopsConfig) (appstudiov1alpha1.BuildCredentials, appstudiov1alpha1.BuildCredentials) {
+	componentCredentials, applicationCredentials := appstudiov1alpha1.BuildCredentials{}, appstudiov1alpha1.BuildCredentials{}
+	if component.Spec.BuildCredentials != nil {
+		componentCredentials = *component.Spec.BuildCredentials
+	}
+	if gitopsConfig.ApplicationBuildCredentials != nil {
+		applicationCredentials = *gitopsConfig.ApplicationBuildCredentials
+	}
+	return componentCredentials, applicationCredentials
+}
+
+// GetRegistryAuthSecrets returns the names of the Secrets holding the credentials of the image registries the builds of the
+// component use: the push secret of the image repository provisioned for the component, followed by the registry secrets
+// of the component, or of its Application, or the default registry secret if it exists.
+func GetRegistryAuthSecrets(component appstudiov1alpha1.Component, gitopsConfig gitopsprepare.GitopsConfig) []string {
+	var secrets []string
+	if component.Status.ImageRepository != nil && component.Status.ImageRepository.PushSecret != "" {
+		secrets = append(secrets, component.Status.ImageRepository.PushSecret)
+	}
+
+	componentCredentials, applicationCredentials := getBuildCredentials(component, gitopsConfig)
+	registrySecrets := componentCredentials.RegistrySecrets
+	if len(registrySecrets) == 0 {
+		registrySecrets = applicationCredentials.RegistrySecrets
+	}
+	if len(registrySecrets) == 0 && gitopsConfig.AppStudioRegistrySecretPresent {
+		registrySecrets = []string{gitopsprepare.RegistrySecret}
+	}
+	for _, secret := range registrySecrets {
+		if secret != "" && !containsString(secrets, secret) {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// GetRegistryAuthSecretName returns the name of the Secret bound to the registry-auth workspace of the component's builds:
+// its only registry secret, or the Secret merging its registry secrets. No workspace is bound if empty.
+func GetRegistryAuthSecretName(component appstudiov1alpha1.Component, gitopsConfig gitopsprepare.GitopsConfig) string {
+	secrets := GetRegistryAuthSecrets(component, gitopsConfig)
+	switch len(secrets) {
+	case 0:
+		return ""
+	case 1:
+		return secrets[0]
+	default:
+		return GetMergedRegistryAuthSecretName(component)
+	}
+}
+
+// GetMergedRegistryAuthSecretName returns the name of the Secret merging the registry secrets of the component
+func GetMergedRegistryAuthSecretName(component appstudiov1alpha1.Component) string {
+	return component.Name + "-registry-auth"
+}
+
+// GetBuildServiceAccountName returns the ServiceAccount running the builds of the component: the ServiceAccount of the
+// component, or of its Application, or the default pipeline ServiceAccount
+func GetBuildServiceAccountName(component appstudiov1alpha1.Component, gitopsConfig gitopsprepare.GitopsConfig) string {
+	componentCredentials, applicationCredentials := getBuildCredentials(component, gitopsConfig)
+	if componentCredentials.ServiceAccountName != "" {
+		return componentCredentials.ServiceAccountName
+	}
+	if applicationCredentials.ServiceAccountName != "" {
+		return applicationCredentials.ServiceAccountName
+	}
+	return gitopsprepare.DefaultBuildServiceAccountName
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"reflect"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	gitopsprepare "github.com/redhat-appstudio/application-service/gitops/prepare"
+	triggersapi "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetRegistryAuthSecrets(t *testing.T) {
+	tests := []struct {
+		name            string
+		component       appstudiov1alpha1.Component
+		gitopsConfig    gitopsprepare.GitopsConfig
+		want            []string
+		wantSecretName  string
+		wantAccountName string
+	}{
+		{
+			name:            "no registry secret",
+			component:       getBuildCredentialsComponent(nil, nil),
+			wantAccountName: "pipeline",
+		},
+		{
+			name:            "default registry secret",
+			component:       getBuildCredentialsComponent(nil, nil),
+			gitopsConfig:    gitopsprepare.GitopsConfig{AppStudioRegistrySecretPresent: true},
+			want:            []string{gitopsprepare.RegistrySecret},
+			wantSecretName:  gitopsprepare.RegistrySecret,
+			wantAccountName: "pipeline",
+		},
+		{
+			name: "credentials of the component replace the default registry secret",
+			component: getBuildCredentialsComponent(&appstudiov1alpha1.BuildCredentials{
+				RegistrySecrets:    []string{"registry"},
+				ServiceAccountName: "builder",
+			}, nil),
+			gitopsConfig: gitopsprepare.GitopsConfig{
+				AppStudioRegistrySecretPresent: true,
+				ApplicationBuildCredentials: &appstudiov1alpha1.BuildCredentials{
+					RegistrySecrets:    []string{"application-registry"},
+					ServiceAccountName: "application-builder",
+				},
+			},
+			want:            []string{"registry"},
+			wantSecretName:  "registry",
+			wantAccountName: "builder",
+		},
+		{
+			name:      "credentials of the application",
+			component: getBuildCredentialsComponent(nil, nil),
+			gitopsConfig: gitopsprepare.GitopsConfig{
+				ApplicationBuildCredentials: &appstudiov1alpha1.BuildCredentials{
+					RegistrySecrets:    []string{"registry", "mirror"},
+					ServiceAccountName: "application-builder",
+				},
+			},
+			want:            []string{"registry", "mirror"},
+			wantSecretName:  "my-component-registry-auth",
+			wantAccountName: "application-builder",
+		},
+		{
+			name: "push secret of the image repository comes first",
+			component: getBuildCredentialsComponent(&appstudiov1alpha1.BuildCredentials{RegistrySecrets: []string{"registry", "my-component-image-push"}},
+				&appstudiov1alpha1.ImageRepositoryStatus{URL: "quay.io/org/repo", PushSecret: "my-component-image-push"}),
+			want:            []string{"my-component-image-push", "registry"},
+			wantSecretName:  "my-component-registry-auth",
+			wantAccountName: "pipeline",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetRegistryAuthSecrets(tt.component, tt.gitopsConfig); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetRegistryAuthSecrets() = %v, want %v", got, tt.want)
+			}
+			if got := GetRegistryAuthSecretName(tt.component, tt.gitopsConfig); got != tt.wantSecretName {
+				t.Errorf("GetRegistryAuthSecretName() = %s, want %s", got, tt.wantSecretName)
+			}
+			if got := GetBuildServiceAccountName(tt.component, tt.gitopsConfig); got != tt.wantAccountName {
+				t.Errorf("GetBuildServiceAccountName() = %s, want %s", got, tt.wantAccountName)
+			}
+
+			pipelineRunSpec := DetermineBuildExecution(tt.component, nil, "", tt.gitopsConfig)
+			registryAuthSecret := ""
+			for _, workspace := range pipelineRunSpec.Workspaces {
+				if workspace.Name == registryAuthWorkspace {
+					registryAuthSecret = workspace.Secret.SecretName
+				}
+			}
+			if registryAuthSecret != tt.wantSecretName {
+				t.Errorf("DetermineBuildExecution() bound %q to the registry-auth workspace, want %q", registryAuthSecret, tt.wantSecretName)
+			}
+			wantPipelineRunAccount := tt.wantAccountName
+			if wantPipelineRunAccount == gitopsprepare.DefaultBuildServiceAccountName {
+				wantPipelineRunAccount = ""
+			}
+			if pipelineRunSpec.ServiceAccountName != wantPipelineRunAccount {
+				t.Errorf("DetermineBuildExecution() set the ServiceAccount %q, want %q", pipelineRunSpec.ServiceAccountName, wantPipelineRunAccount)
+			}
+
+			eventListener, err := GenerateEventListener(tt.component, triggersapi.TriggerTemplate{ObjectMeta: metav1.ObjectMeta{Name: "my-component"}}, tt.gitopsConfig)
+			if err != nil {
+				t.Fatalf("GenerateEventListener() error = %v", err)
+			}
+			if eventListener.Spec.ServiceAccountName != tt.wantAccountName {
+				t.Errorf("GenerateEventListener() set the ServiceAccount %s, want %s", eventListener.Spec.ServiceAccountName, tt.wantAccountName)
+			}
+		})
+	}
+}
+
+func getBuildCredentialsComponent(buildCredentials *appstudiov1alpha1.BuildCredentials, imageRepository *appstudiov1alpha1.ImageRepositoryStatus) appstudiov1alpha1.Component {
+	return appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-component",
+			Namespace: "my-namespace",
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			ComponentName:    "my-component",
+			Application:      "my-application",
+			ContainerImage:   "quay.io/org/repo",
+			BuildCredentials: buildCredentials,
+			Source: appstudiov1alpha1.ComponentSource{
+				ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+					GitSource: &appstudiov1alpha1.GitSource{URL: "https://github.com/org/repo"},
+				},
+			},
+		},
+		Status: appstudiov1alpha1.ComponentStatus{
+			ImageRepository: imageRepository,
+		},
+	}
+}
@@ -0,0 +1,187 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	gitopsgen "github.com/redhat-developer/gitops-generator/pkg"
+	"github.com/spf13/afero"
+)
+
+const (
+	// SSHSigningKey is the key of the commit signing Secret holding an SSH private key
+	SSHSigningKey = "ssh-privatekey"
+	// GPGSigningKey is the key of the commit signing Secret holding an armored GPG private key
+	GPGSigningKey = "gpg-privatekey"
+	// GPGSigningKeyID is the optional key of the commit signing Secret holding the ID of the GPG key to sign with
+	GPGSigningKeyID = "gpg-keyid"
+)
+
+// The first git version signing commits with SSH keys
+const (
+	sshSigningGitMajor = 2
+	sshSigningGitMinor = 34
+)
+
+// CommitMessageData is the data available to the GitOps commit message templates
+type CommitMessageData struct {
+	Component   string
+	Application string
+	Namespace   string
+	// Resource is the kind and name of the resource that triggered the commit, e.g. Component/my-component
+	Resource string
+	// Message is the default commit message
+	Message string
+}
+
+// RenderCommitMessage renders the commit message template with the given data.
+// The default message is returned if the template is empty.
+func RenderCommitMessage(messageTemplate string, data CommitMessageData) (string, error) {
+	if messageTemplate == "" {
+		return data.Message, nil
+	}
+	tmpl, err := template.New("message").Option("missingkey=error").Parse(messageTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the commit message template: %v", err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render the commit message template: %v", err)
+	}
+	return sb.String(), nil
+}
+
+// CommitExecutor wraps an Executor and applies the configured identity, signing and message template to every git commit it runs.
+// All the other commands are passed through to the wrapped Executor untouched.
+type CommitExecutor struct {
+	gitopsgen.Executor
+
+	AuthorName      string
+	AuthorEmail     string
+	MessageTemplate string
+	MessageData     CommitMessageData
+
+	// SigningConfig holds the git configuration, as key=value pairs, used to sign the commits
+	SigningConfig []string
+}
+
+// Execute runs the command with the wrapped Executor, configuring the commit first if the command is a git commit
+func (e *CommitExecutor) Execute(baseDir, command string, args ...string) ([]byte, error) {
+	if command != "git" || len(args) == 0 || args[0] != "commit" {
+		return e.Executor.Execute(baseDir, command, args...)
+	}
+
+	var gitArgs []string
+	for _, config := range e.gitConfig() {
+		gitArgs = append(gitArgs, "-c", config)
+	}
+	gitArgs = append(gitArgs, "commit")
+	for i := 1; i < len(args); i++ {
+		gitArgs = append(gitArgs, args[i])
+		if args[i] == "-m" && i+1 < len(args) {
+			data := e.MessageData
+			data.Message = args[i+1]
+			message, err := RenderCommitMessage(e.MessageTemplate, data)
+			if err != nil {
+				return nil, err
+			}
+			gitArgs = append(gitArgs, message)
+			i++
+		}
+	}
+	return e.Executor.Execute(baseDir, command, gitArgs...)
+}
+
+// checkSSHSigningSupport returns an error if the git of the service cannot sign commits with SSH keys,
+// which requires git 2.34 or later
+func checkSSHSigningSupport(e gitopsgen.Executor, dir string) error {
+	out, err := e.Execute(dir, "git", "--version")
+	if err != nil {
+		return fmt.Errorf("failed to read the git version %q: %s", string(out), err)
+	}
+	// The output reads "git version 2.34.1", possibly followed by a vendor suffix
+	var major, minor int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(strings.TrimSpace(string(out)), "git version "), "%d.%d", &major, &minor); err != nil {
+		return fmt.Errorf("failed to parse the git version %q: %v", string(out), err)
+	}
+	if major < sshSigningGitMajor || (major == sshSigningGitMajor && minor < sshSigningGitMinor) {
+		return fmt.Errorf("signing commits with an SSH key requires git %d.%d or later, found %q", sshSigningGitMajor, sshSigningGitMinor, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// gitConfig returns the git configuration, as key=value pairs, applied to the commits
+func (e *CommitExecutor) gitConfig() []string {
+	var config []string
+	if e.AuthorName != "" {
+		config = append(config, "user.name="+e.AuthorName)
+	}
+	if e.AuthorEmail != "" {
+		config = append(config, "user.email="+e.AuthorEmail)
+	}
+	return append(config, e.SigningConfig...)
+}
+
+// ConfigureCommitSigning writes the signing key found in the Secret data into keyDir and returns the git configuration,
+// as key=value pairs, that signs the commits with it. SSH keys take precedence over GPG keys.
+// GPG keys are imported into a keyring in keyDir, so that the keyring of the service is left untouched.
+func ConfigureCommitSigning(e gitopsgen.Executor, appFs afero.Afero, keyDir string, secretData map[string][]byte) ([]string, error) {
+	if err := appFs.MkdirAll(keyDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the signing key directory %q: %v", keyDir, err)
+	}
+
+	if sshKey, ok := secretData[SSHSigningKey]; ok && len(sshKey) > 0 {
+		if err := checkSSHSigningSupport(e, keyDir); err != nil {
+			return nil, err
+		}
+		keyPath := filepath.Join(keyDir, "signing-key")
+		if err := appFs.WriteFile(keyPath, sshKey, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write the SSH signing key: %v", err)
+		}
+		return []string{"gpg.format=ssh", "user.signingkey=" + keyPath, "commit.gpgsign=true"}, nil
+	}
+
+	if gpgKey, ok := secretData[GPGSigningKey]; ok && len(gpgKey) > 0 {
+		keyPath := filepath.Join(keyDir, "signing-key.asc")
+		if err := appFs.WriteFile(keyPath, gpgKey, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write the GPG signing key: %v", err)
+		}
+		gnupgHome := filepath.Join(keyDir, "gnupg")
+		if err := appFs.MkdirAll(gnupgHome, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create the GPG home directory %q: %v", gnupgHome, err)
+		}
+		if out, err := e.Execute(keyDir, "gpg", "--homedir", gnupgHome, "--batch", "--import", keyPath); err != nil {
+			return nil, fmt.Errorf("failed to import the GPG signing key %q: %s", string(out), err)
+		}
+
+		// git has no option to pass the keyring to gpg, so point it to a wrapper using the imported keyring
+		gpgProgram := filepath.Join(keyDir, "gpg.sh")
+		if err := appFs.WriteFile(gpgProgram, []byte(fmt.Sprintf("#!/bin/sh\nexec gpg --homedir %q \"$@\"\n", gnupgHome)), 0700); err != nil {
+			return nil, fmt.Errorf("failed to write the GPG program wrapper: %v", err)
+		}
+		config := []string{"gpg.format=openpgp", "gpg.program=" + gpgProgram, "commit.gpgsign=true"}
+		if keyID := string(secretData[GPGSigningKeyID]); keyID != "" {
+			config = append(config, "user.signingkey="+keyID)
+		}
+		return config, nil
+	}
+
+	return nil, fmt.Errorf("the signing secret contains neither a %q nor a %q key", SSHSigningKey, GPGSigningKey)
+}
@@ -0,0 +1,237 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"github.com/redhat-developer/gitops-generator/pkg/testutils"
+)
+
+func TestRenderCommitMessage(t *testing.T) {
+	data := CommitMessageData{
+		Component:   "test-component",
+		Application: "test-app",
+		Namespace:   "test-namespace",
+		Resource:    "Component/test-component",
+		Message:     "Generating Tekton resources",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "No template uses the default message",
+			want: "Generating Tekton resources",
+		},
+		{
+			name:     "Template referencing all the fields",
+			template: "{{ .Namespace }}/{{ .Application }}/{{ .Component }}: {{ .Message }} ({{ .Resource }})",
+			want:     "test-namespace/test-app/test-component: Generating Tekton resources (Component/test-component)",
+		},
+		{
+			name:     "Invalid template",
+			template: "{{ .Message ",
+			wantErr:  true,
+		},
+		{
+			name:     "Template referencing an unknown field",
+			template: "{{ .Environment }}",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderCommitMessage(tt.template, data)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error return value. Got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RenderCommitMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitExecutor(t *testing.T) {
+	tests := []struct {
+		name     string
+		executor CommitExecutor
+		command  string
+		args     []string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "Commands other than commits are passed through",
+			executor: CommitExecutor{
+				AuthorName: "GitOps Bot",
+			},
+			command: "git",
+			args:    []string{"push", "origin", "main"},
+			want:    []string{"push", "origin", "main"},
+		},
+		{
+			name:    "Commit without any configuration is passed through",
+			command: "git",
+			args:    []string{"commit", "-m", "Generating Tekton resources"},
+			want:    []string{"commit", "-m", "Generating Tekton resources"},
+		},
+		{
+			name: "Commit with identity, signing and message template",
+			executor: CommitExecutor{
+				AuthorName:      "GitOps Bot",
+				AuthorEmail:     "gitops-bot@example.com",
+				SigningConfig:   []string{"gpg.format=ssh", "user.signingkey=/tmp/key", "commit.gpgsign=true"},
+				MessageTemplate: "[{{ .Component }}] {{ .Message }}",
+				MessageData:     CommitMessageData{Component: "test-component"},
+			},
+			command: "git",
+			args:    []string{"commit", "-m", "Generating Tekton resources"},
+			want: []string{
+				"-c", "user.name=GitOps Bot",
+				"-c", "user.email=gitops-bot@example.com",
+				"-c", "gpg.format=ssh",
+				"-c", "user.signingkey=/tmp/key",
+				"-c", "commit.gpgsign=true",
+				"commit", "-m", "[test-component] Generating Tekton resources",
+			},
+		},
+		{
+			name: "Commit message template fails to render",
+			executor: CommitExecutor{
+				MessageTemplate: "{{ .Environment }}",
+			},
+			command: "git",
+			args:    []string{"commit", "-m", "Generating Tekton resources"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := testutils.NewMockExecutor()
+			tt.executor.Executor = e
+
+			_, err := tt.executor.Execute("/fake/path", tt.command, tt.args...)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error return value. Got %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+			e.AssertCommandsExecuted(t, []testutils.Execution{{BaseDir: "/fake/path", Command: tt.command, Args: tt.want}})
+		})
+	}
+}
+
+func TestConfigureCommitSigning(t *testing.T) {
+	keyDir := "/fake/path/.gitops-signing"
+
+	tests := []struct {
+		name       string
+		secretData map[string][]byte
+		outputs    [][]byte
+		errors     []error
+		want       []string
+		wantFiles  []string
+		wantErr    string
+	}{
+		{
+			name: "SSH signing key",
+			secretData: map[string][]byte{
+				SSHSigningKey: []byte("ssh-key"),
+				GPGSigningKey: []byte("gpg-key"),
+			},
+			outputs:   [][]byte{[]byte("git version 2.39.3 (Apple Git-145)\n")},
+			want:      []string{"gpg.format=ssh", "user.signingkey=" + filepath.Join(keyDir, "signing-key"), "commit.gpgsign=true"},
+			wantFiles: []string{"signing-key"},
+		},
+		{
+			name: "SSH signing key with a git version without SSH signing",
+			secretData: map[string][]byte{
+				SSHSigningKey: []byte("ssh-key"),
+			},
+			outputs: [][]byte{[]byte("git version 2.31.1\n")},
+			wantErr: "signing commits with an SSH key requires git 2.34 or later",
+		},
+		{
+			name: "SSH signing key with an unreadable git version",
+			secretData: map[string][]byte{
+				SSHSigningKey: []byte("ssh-key"),
+			},
+			outputs: [][]byte{[]byte("unknown")},
+			wantErr: "failed to parse the git version",
+		},
+		{
+			name: "GPG signing key with a key ID",
+			secretData: map[string][]byte{
+				GPGSigningKey:   []byte("gpg-key"),
+				GPGSigningKeyID: []byte("ABCDEF0123456789"),
+			},
+			want:      []string{"gpg.format=openpgp", "gpg.program=" + filepath.Join(keyDir, "gpg.sh"), "commit.gpgsign=true", "user.signingkey=ABCDEF0123456789"},
+			wantFiles: []string{"signing-key.asc", "gpg.sh"},
+		},
+		{
+			name: "GPG signing key import fails",
+			secretData: map[string][]byte{
+				GPGSigningKey: []byte("gpg-key"),
+			},
+			errors:  []error{errors.New("invalid key")},
+			wantErr: "failed to import the GPG signing key",
+		},
+		{
+			name: "No signing key",
+			secretData: map[string][]byte{
+				"password": []byte("token"),
+			},
+			wantErr: "the signing secret contains neither",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			e := testutils.NewMockExecutor(tt.outputs...)
+			for _, err := range tt.errors {
+				e.Errors.Push(err)
+			}
+
+			got, err := ConfigureCommitSigning(e, fs, keyDir, tt.secretData)
+			if tt.wantErr != "" {
+				testutils.AssertErrorMatch(t, tt.wantErr, err)
+				return
+			}
+			testutils.AssertNoError(t, err)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConfigureCommitSigning() = %v, want %v", got, tt.want)
+			}
+			for _, file := range tt.wantFiles {
+				if exists, _ := fs.Exists(filepath.Join(keyDir, file)); !exists {
+					t.Errorf("ConfigureCommitSigning() did not write %s", file)
+				}
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"path"
 	"regexp"
 	"strings"
 	"time"
@@ -38,25 +39,51 @@ import (
 	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	triggersapi "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
-	buildCommonStoragePVCFileName = "common-storage-pvc.yaml"
-	buildTriggerTemplateFileName  = "trigger-template.yaml"
-	buildEventListenerFileName    = "event-listener.yaml"
-	buildWebhookRouteFileName     = "build-webhook-route.yaml"
-	buildRepositoryFileName       = "pac-repository.yaml"
+	buildTriggerTemplateFileName = "trigger-template.yaml"
+	buildEventListenerFileName   = "event-listener.yaml"
+	buildTriggerBindingFileName  = "trigger-binding.yaml"
+	buildWebhookRouteFileName    = "build-webhook-route.yaml"
+	buildWebhookIngressFileName  = "build-webhook-ingress.yaml"
+	buildRepositoryFileName      = "pac-repository.yaml"
+
+	buildPullRequestTriggerTemplateFileName = "pull-request-trigger-template.yaml"
+	buildPullRequestTriggerBindingFileName  = "pull-request-trigger-binding.yaml"
 
 	DefaultImageRepo = "quay.io/redhat-appstudio/user-workload"
 
 	PaCAnnotation                     = "pipelinesascode"
 	GitProviderAnnotationName         = "git-provider"
+	GitProviderURLAnnotationName      = "git-provider-url"
+	GitProviderUserAnnotationName     = "git-provider-user"
 	PipelinesAsCodeWebhooksSecretName = "pipelines-as-code-webhooks-secret"
 	PipelinesAsCode_githubAppIdKey    = "github-application-id"
 	PipelinesAsCode_githubPrivateKey  = "github-private-key"
+
+	// PullRequestBuildAnnotation opts a Component in to build its pull requests, when set to "1"
+	PullRequestBuildAnnotation = "pull-request-builds"
+
+	// RebuildAnnotation requests a new build of a Component, when set to "1". It is removed once the build is submitted
+	RebuildAnnotation = "rebuild"
+
+	// BuildComponentLabel is the label of the build PipelineRuns holding the name of the Component they build
+	BuildComponentLabel = "build.appstudio.openshift.io/component"
+
+	// BuildEventTypeLabel is the label of the build PipelineRuns triggered by git events holding the type of the event,
+	// either BuildPushEventType or BuildPullRequestEventType
+	BuildEventTypeLabel       = "build.appstudio.openshift.io/event-type"
+	BuildPushEventType        = "push"
+	BuildPullRequestEventType = "pull_request"
+
+	// BuildWebhookSecretKey is the key of the build webhook Secret holding the secret to configure on the git repository webhook
+	BuildWebhookSecretKey = "webhook.secret"
 )
 
 var (
@@ -71,10 +98,25 @@ func SetDefaultImageRepo(repo string) {
 	imageRegistry = repo
 }
 
+// IsPaCBuild returns whether the component is built with Pipelines as Code rather than with the Tekton Triggers build webhook
+func IsPaCBuild(component appstudiov1alpha1.Component, gitopsConfig gitopsprepare.GitopsConfig) bool {
+	val, ok := component.Annotations[PaCAnnotation]
+	return (ok && val == "1") || gitopsConfig.IsHACBS
+}
+
+// IsRebuildRequested returns whether a new build of the component was requested with the rebuild annotation
+func IsRebuildRequested(component appstudiov1alpha1.Component) bool {
+	return component.Annotations[RebuildAnnotation] == "1"
+}
+
+// GetBuildWebhookSecretName returns the name of the Secret holding the secret that authenticates the events sent to the build webhook of the component
+func GetBuildWebhookSecretName(component appstudiov1alpha1.Component) string {
+	return component.Name + "-webhook-secret"
+}
+
 func GenerateBuild(fs afero.Fs, outputFolder string, component appstudiov1alpha1.Component, gitopsConfig gitopsprepare.GitopsConfig) error {
 	var buildResources map[string]interface{}
-	val, ok := component.Annotations[PaCAnnotation]
-	if (ok && val == "1") || gitopsConfig.IsHACBS {
+	if IsPaCBuild(component, gitopsConfig) {
 		repository, err := GeneratePACRepository(component, gitopsConfig.PipelinesAsCodeCredentials)
 		if err != nil {
 			return err
@@ -87,15 +129,38 @@ func GenerateBuild(fs afero.Fs, outputFolder string, component appstudiov1alpha1
 		if err != nil {
 			return err
 		}
-		eventListener := GenerateEventListener(component, *triggerTemplate)
-		webhookRoute := GenerateBuildWebhookRoute(component)
+		eventListener, err := GenerateEventListener(component, *triggerTemplate, gitopsConfig)
+		if err != nil {
+			return err
+		}
 
 		buildResources = map[string]interface{}{
-			//buildCommonStoragePVCFileName: commonStoragePVC,
 			buildTriggerTemplateFileName: triggerTemplate,
 			buildEventListenerFileName:   eventListener,
-			buildWebhookRouteFileName:    webhookRoute,
 		}
+		if eventListener.Spec.Triggers[0].Bindings[0].Kind == triggersapi.NamespacedTriggerBindingKind {
+			buildResources[buildTriggerBindingFileName] = GenerateTriggerBinding(component)
+		}
+		if IsPullRequestBuildEnabled(component) {
+			pullRequestTriggerTemplate, err := GeneratePullRequestTriggerTemplate(component, gitopsConfig)
+			if err != nil {
+				return err
+			}
+			buildResources[buildPullRequestTriggerTemplateFileName] = pullRequestTriggerTemplate
+			buildResources[buildPullRequestTriggerBindingFileName] = GeneratePullRequestTriggerBinding(component)
+		}
+		if gitopsConfig.Ingress != nil {
+			webhookIngress, err := GenerateBuildWebhookIngress(component, *gitopsConfig.Ingress)
+			if err != nil {
+				return err
+			}
+			buildResources[buildWebhookIngressFileName] = webhookIngress
+		} else {
+			buildResources[buildWebhookRouteFileName] = GenerateBuildWebhookRoute(component)
+		}
+	}
+	if gitopsConfig.BuildWorkspace.Strategy == gitopsprepare.ComponentBuildWorkspace {
+		buildResources[getBuildWorkspaceClaimFileName(component)] = GenerateBuildWorkspaceClaim(component, gitopsConfig.BuildWorkspace)
 	}
 
 	kustomize := resources.Kustomization{}
@@ -114,7 +179,7 @@ func GenerateBuild(fs afero.Fs, outputFolder string, component appstudiov1alpha1
 // GenerateInitialBuildPipelineRun generates pipeline run for initial build of the component.
 func GenerateInitialBuildPipelineRun(component appstudiov1alpha1.Component, gitopsConfig gitopsprepare.GitopsConfig) (tektonapi.PipelineRun, error) {
 	// normalizeOutputImageURL is not called with initial builds so we can ignore the error here
-	params, err := getParamsForComponentBuild(component, true)
+	params, err := getParamsForComponentBuild(component, true, GetImageTagging(component, gitopsConfig))
 	if err != nil {
 		return tektonapi.PipelineRun{}, err
 	}
@@ -138,44 +203,149 @@ func getInitialBuildWorkspaceSubpath() string {
 // in webhooks-triggered pipelineRuns as well as user-triggered PipelineRuns
 func DetermineBuildExecution(component appstudiov1alpha1.Component, params []tektonapi.Param, workspaceSubPath string, gitopsConfig gitopsprepare.GitopsConfig) tektonapi.PipelineRunSpec {
 
+	buildPipeline := SelectBuildPipeline(component, gitopsConfig)
 	pipelineRunSpec := tektonapi.PipelineRunSpec{
-		Params: params,
+		Params: mergeBuildPipelineParams(params, buildPipeline.Params),
 		PipelineRef: &tektonapi.PipelineRef{
-			Name:   determineBuildPipeline(component),
+			Name:   buildPipeline.Name,
 			Bundle: gitopsConfig.BuildBundle,
 		},
 
 		Workspaces: []tektonapi.WorkspaceBinding{
-			{
-				Name: "workspace",
-				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: "appstudio",
-				},
-				SubPath: component.Name + "/" + workspaceSubPath,
-			},
+			getBuildWorkspaceBinding(component, workspaceSubPath, gitopsConfig.BuildWorkspace),
 		},
 	}
-	if gitopsConfig.AppStudioRegistrySecretPresent {
+	if registryAuthSecret := GetRegistryAuthSecretName(component, gitopsConfig); registryAuthSecret != "" {
 		pipelineRunSpec.Workspaces = append(pipelineRunSpec.Workspaces, tektonapi.WorkspaceBinding{
-			Name: "registry-auth",
+			Name: registryAuthWorkspace,
 			Secret: &corev1.SecretVolumeSource{
-				SecretName: "redhat-appstudio-registry-pull-secret",
+				SecretName: registryAuthSecret,
 			},
 		})
 	}
+	// The PipelineRuns of the components without build credentials keep running with the default ServiceAccount
+	if serviceAccountName := GetBuildServiceAccountName(component, gitopsConfig); serviceAccountName != gitopsprepare.DefaultBuildServiceAccountName {
+		pipelineRunSpec.ServiceAccountName = serviceAccountName
+	}
 	return pipelineRunSpec
 }
 
-// determineBuildPipeline should detect build pipeline to use for the component and return its name.
-// If it fails to autodetect right pipeline, noop pipeline will be returned.
+// getBuildWorkspaceBinding returns the binding of the workspace of the component's builds to the storage
+// of the workspace strategy
+func getBuildWorkspaceBinding(component appstudiov1alpha1.Component, workspaceSubPath string, workspaceConfig gitopsprepare.BuildWorkspaceConfig) tektonapi.WorkspaceBinding {
+	switch workspaceConfig.Strategy {
+	case gitopsprepare.ComponentBuildWorkspace:
+		return tektonapi.WorkspaceBinding{
+			Name: "workspace",
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: GetBuildWorkspaceClaimName(component),
+			},
+			SubPath: workspaceSubPath,
+		}
+	case gitopsprepare.PipelineRunBuildWorkspace:
+		return tektonapi.WorkspaceBinding{
+			Name: "workspace",
+			VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+				Spec: getBuildWorkspaceClaimSpec(workspaceConfig),
+			},
+		}
+	default:
+		claimName := workspaceConfig.ClaimName
+		if claimName == "" {
+			claimName = gitopsprepare.DefaultBuildWorkspaceClaimName
+		}
+		return tektonapi.WorkspaceBinding{
+			Name: "workspace",
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: claimName,
+			},
+			SubPath: component.Name + "/" + workspaceSubPath,
+		}
+	}
+}
+
+// GetBuildWorkspaceClaimName returns the name of the claim generated for the workspace of the component's builds
+func GetBuildWorkspaceClaimName(component appstudiov1alpha1.Component) string {
+	return component.Name + "-build-workspace"
+}
+
+// getBuildWorkspaceClaimFileName returns the name of the file holding the claim of the component's build workspace
+func getBuildWorkspaceClaimFileName(component appstudiov1alpha1.Component) string {
+	return GetBuildWorkspaceClaimName(component) + "-pvc.yaml"
+}
+
+// GenerateBuildWorkspaceClaim returns the claim backing the workspace of the component's builds,
+// when each component has its own build workspace
+func GenerateBuildWorkspaceClaim(component appstudiov1alpha1.Component, workspaceConfig gitopsprepare.BuildWorkspaceConfig) corev1.PersistentVolumeClaim {
+	return corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PersistentVolumeClaim",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetBuildWorkspaceClaimName(component),
+			Namespace: component.Namespace,
+			Labels:    getBuildCommonLabelsForComponent(&component),
+		},
+		Spec: getBuildWorkspaceClaimSpec(workspaceConfig),
+	}
+}
+
+// getBuildWorkspaceClaimSpec returns the spec of the claims generated for the build workspaces
+func getBuildWorkspaceClaimSpec(workspaceConfig gitopsprepare.BuildWorkspaceConfig) corev1.PersistentVolumeClaimSpec {
+	size := workspaceConfig.Size
+	if size.IsZero() {
+		size = resource.MustParse(gitopsprepare.DefaultBuildWorkspaceSize)
+	}
+	claimSpec := corev1.PersistentVolumeClaimSpec{
+		AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceStorage: size,
+			},
+		},
+	}
+	if workspaceConfig.StorageClassName != "" {
+		storageClassName := workspaceConfig.StorageClassName
+		claimSpec.StorageClassName = &storageClassName
+	}
+	return claimSpec
+}
+
+// BuildPipelineSelection is the build pipeline selected for a component, with the additional parameters to pass to it
+type BuildPipelineSelection struct {
+	Name   string
+	Params []appstudiov1alpha1.BuildPipelineParam
+	// Reason explains why the pipeline was selected
+	Reason string
+}
+
+// defaultBuildPipelineSelectors are used when the cluster does not configure any build pipeline selectors
+var defaultBuildPipelineSelectors = []gitopsprepare.BuildPipelineSelector{
+	{Name: "dockerfile", PipelineName: "docker-build", Dockerfile: true},
+	{Name: "java", PipelineName: "java-builder", Language: "java"},
+	{Name: "nodejs", PipelineName: "nodejs-builder", Language: "nodejs"},
+	{Name: "node", PipelineName: "nodejs-builder", Language: "node"},
+}
+
+// SelectBuildPipeline should detect build pipeline to use for the component and return it.
+// The pipeline set in the component's spec takes precedence. Otherwise, the first build pipeline selector matching the devfile
+// of the component is used. If it fails to autodetect right pipeline, noop pipeline will be returned.
 // If a repository consists of two parts (e.g. frontend and backend), it should be mapped to two components (see context field in CR).
 // Available build pipeleines are located here: https://github.com/redhat-appstudio/build-definitions/tree/main/pipelines
-func determineBuildPipeline(component appstudiov1alpha1.Component) string {
+func SelectBuildPipeline(component appstudiov1alpha1.Component, gitopsConfig gitopsprepare.GitopsConfig) BuildPipelineSelection {
+	if component.Spec.BuildPipeline != nil && component.Spec.BuildPipeline.Name != "" {
+		return BuildPipelineSelection{
+			Name:   component.Spec.BuildPipeline.Name,
+			Params: component.Spec.BuildPipeline.Params,
+			Reason: "Set in the buildPipeline of the Component",
+		}
+	}
+
 	// It is possible to skip error checks here because the model is propogated by component controller
 	componentDevfileData, err := devfile.ParseDevfileModel(component.Status.Devfile)
 	if err != nil {
-		// Return dummy value for tests
-		return "noop"
+		return BuildPipelineSelection{Name: "noop", Reason: "The devfile of the Component could not be parsed"}
 	}
 
 	// Check for Dockerfile
@@ -184,33 +354,71 @@ func determineBuildPipeline(component appstudiov1alpha1.Component) string {
 			ComponentType: devfilev1alpha2.ImageComponentType,
 		},
 	}
+	hasDockerfile := false
 	devfileComponents, _ := componentDevfileData.GetComponents(filterOptions)
 	for _, devfileComponent := range devfileComponents {
 		if devfileComponent.Image != nil && devfileComponent.Image.Dockerfile != nil {
-			return "docker-build"
+			hasDockerfile = true
 		}
 	}
 
 	// The only information about project is in language and projectType fileds under metadata of the devfile.
 	// They must be used to determine the right build pipeline.
 	devfileMetadata := componentDevfileData.GetMetadata()
-	language := devfileMetadata.Language
-	// TODO use projectType when build pipelines support frameworks
-	// projectType := devfileMetadata.ProjectType
 
-	switch strings.ToLower(language) {
-	case "java":
-		return "java-builder"
-	case "nodejs", "node":
-		return "nodejs-builder"
-	case "python":
-		// TODO return python-builder when the pipeline ready
-		return "noop"
+	selectors := gitopsConfig.BuildPipelineSelectors
+	if len(selectors) == 0 {
+		selectors = defaultBuildPipelineSelectors
+	}
+	for _, selector := range selectors {
+		if selector.Dockerfile && !hasDockerfile {
+			continue
+		}
+		if selector.Language != "" && !strings.EqualFold(selector.Language, devfileMetadata.Language) {
+			continue
+		}
+		if selector.ProjectType != "" && !strings.EqualFold(selector.ProjectType, devfileMetadata.ProjectType) {
+			continue
+		}
+		return BuildPipelineSelection{
+			Name:   selector.PipelineName,
+			Params: selector.Params,
+			Reason: fmt.Sprintf("Matched the %s build pipeline selector", selector.Name),
+		}
 	}
 
 	// Failed to detect build pipeline
 	// Do nothing as we do not know how to build given component
-	return "noop"
+	return BuildPipelineSelection{Name: "noop", Reason: "No build pipeline selector matched the devfile of the Component"}
+}
+
+// mergeBuildPipelineParams returns the parameters with the additional build pipeline parameters,
+// which replace the parameters of the same name
+func mergeBuildPipelineParams(params []tektonapi.Param, additionalParams []appstudiov1alpha1.BuildPipelineParam) []tektonapi.Param {
+	if len(additionalParams) == 0 {
+		return params
+	}
+	merged := append([]tektonapi.Param{}, params...)
+	for _, additionalParam := range additionalParams {
+		param := tektonapi.Param{
+			Name: additionalParam.Name,
+			Value: tektonapi.ArrayOrString{
+				Type:      tektonapi.ParamTypeString,
+				StringVal: additionalParam.Value,
+			},
+		}
+		replaced := false
+		for i := range merged {
+			if merged[i].Name == param.Name {
+				merged[i] = param
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, param)
+		}
+	}
+	return merged
 }
 
 func protectDefaultImageRepo(outputImage, namespace string) error {
@@ -225,6 +433,25 @@ func protectDefaultImageRepo(outputImage, namespace string) error {
 }
 
 func normalizeOutputImageURL(outputImage string) string {
+	// If provided image has a tag, then append dash and git revision to it.
+	// Otherwise, use git revision as the tag.
+	// Examples:
+	//   quay.io/foo/bar:mytag ==> quay.io/foo/bar:mytag-git-revision
+	//   quay.io/foo/bar       ==> quay.io/foo/bar:latest-git-revision
+	return appendImageTagSuffix(outputImage, "$(tt.params.git-revision)", "latest-")
+}
+
+// normalizePullRequestOutputImageURL returns the image the pull requests are built into, tagged with the pull request number
+func normalizePullRequestOutputImageURL(outputImage string) string {
+	// Examples:
+	//   quay.io/foo/bar:mytag ==> quay.io/foo/bar:mytag-pr-number
+	//   quay.io/foo/bar       ==> quay.io/foo/bar:pr-number
+	return appendImageTagSuffix(outputImage, "pr-$(tt.params.pull-request-number)", "")
+}
+
+// appendImageTagSuffix appends dash and the suffix to the tag of the image, or tags the image with the default tag prefix
+// followed by the suffix if it has no tag
+func appendImageTagSuffix(outputImage string, suffix string, defaultTagPrefix string) string {
 	// Check if the image has commit SHA suffix and delete it if so
 	shaSuffixRegExp := regexp.MustCompile(`(.+)-[0-9a-f]{40}$`)
 	foundImage := shaSuffixRegExp.FindSubmatch([]byte(outputImage))
@@ -234,24 +461,52 @@ func normalizeOutputImageURL(outputImage string) string {
 		outputImage = string(foundImage[1])
 	}
 
-	// If provided image has a tag, then append dash and git revision to it.
-	// Otherwise, use git revision as the tag.
-	// Examples:
-	//   quay.io/foo/bar:mytag ==> quay.io/foo/bar:mytag-git-revision
-	//   quay.io/foo/bar       ==> quay.io/foo/bar:latest-git-revision
 	if strings.Contains(outputImage, ":") {
-		outputImage = outputImage + "-" + "$(tt.params.git-revision)"
+		outputImage = outputImage + "-" + suffix
 	} else {
-		outputImage = outputImage + ":latest-" + "$(tt.params.git-revision)"
+		outputImage = outputImage + ":" + defaultTagPrefix + suffix
 	}
 	return outputImage
 }
 
+// getParamsForPullRequestBuild would return the 'input' parameters for the PipelineRun that would build an image
+// from the head of a pull request against the source of the Component
+func getParamsForPullRequestBuild(component appstudiov1alpha1.Component) ([]tektonapi.Param, error) {
+	// The pull requests are tagged with their number and do not move the additional tags
+	params, err := getParamsForComponentBuild(component, true, appstudiov1alpha1.ImageTagging{})
+	if err != nil {
+		return []tektonapi.Param{}, err
+	}
+
+	revisionParam := tektonapi.Param{
+		Name: "revision",
+		Value: tektonapi.ArrayOrString{
+			Type:      tektonapi.ParamTypeString,
+			StringVal: "$(tt.params.git-revision)",
+		},
+	}
+	hasRevision := false
+	for i, param := range params {
+		switch param.Name {
+		case "output-image":
+			params[i].Value.StringVal = normalizePullRequestOutputImageURL(param.Value.StringVal)
+		case "revision":
+			params[i] = revisionParam
+			hasRevision = true
+		}
+	}
+	if !hasRevision {
+		params = append(params, revisionParam)
+	}
+	return params, nil
+}
+
 // getParamsForComponentBuild would return the 'input' parameters for the PipelineRun
 // that would build an image from source of the Component.
 // The key difference between webhook (regular) triggered PipelineRuns and user-triggered (initial) PipelineRuns
-// is that the git revision appended to the output image tag in case of webhook build.
-func getParamsForComponentBuild(component appstudiov1alpha1.Component, isInitialBuild bool) ([]tektonapi.Param, error) {
+// is that the tag of the image tagging strategy, the git revision by default, is appended to the output image tag
+// in case of webhook build. Initial builds only append the tags that are known without a push event.
+func getParamsForComponentBuild(component appstudiov1alpha1.Component, isInitialBuild bool, imageTagging appstudiov1alpha1.ImageTagging) ([]tektonapi.Param, error) {
 	sourceCode := component.Spec.Source.GitSource.URL
 	revision := component.Spec.Source.GitSource.Revision
 	outputImage := component.Spec.ContainerImage
@@ -262,7 +517,21 @@ func getParamsForComponentBuild(component appstudiov1alpha1.Component, isInitial
 	}
 
 	if !isInitialBuild {
-		outputImage = normalizeOutputImageURL(component.Spec.ContainerImage)
+		if isImageTagComputed(imageTagging.Strategy) {
+			outputImage = appendImageTagSuffix(outputImage, "$(tt.params."+imageTagParam+")", "")
+		} else {
+			outputImage = normalizeOutputImageURL(outputImage)
+		}
+	} else if imageTag := getInitialBuildImageTag(component, imageTagging.Strategy); imageTag != "" && outputImage != "" {
+		outputImage = appendImageTagSuffix(outputImage, imageTag, "")
+	}
+	if component.Spec.ContainerImage != "" {
+		if err = validateOutputImage(outputImage); err != nil {
+			return []tektonapi.Param{}, err
+		}
+	}
+	if err = validateAdditionalTags(imageTagging.AdditionalTags, component.Spec.ContainerImage, component.Namespace); err != nil {
+		return []tektonapi.Param{}, err
 	}
 
 	// Default required parameters
@@ -282,6 +551,9 @@ func getParamsForComponentBuild(component appstudiov1alpha1.Component, isInitial
 			},
 		},
 	}
+	if len(imageTagging.AdditionalTags) > 0 {
+		params = append(params, getAdditionalTagsParam(imageTagging.AdditionalTags))
+	}
 	// if revision is specified in the component
 	// use it in the parms to the Pipeline Run
 	if revision != "" {
@@ -329,7 +601,7 @@ func getBuildCommonLabelsForComponent(component *appstudiov1alpha1.Component) ma
 		"build.appstudio.openshift.io/build":       "true",
 		"build.appstudio.openshift.io/type":        "build",
 		"build.appstudio.openshift.io/version":     "0.1",
-		"build.appstudio.openshift.io/component":   component.Name,
+		BuildComponentLabel:                        component.Name,
 		"build.appstudio.openshift.io/application": component.Spec.Application,
 	}
 	return labels
@@ -368,43 +640,68 @@ func GenerateBuildWebhookRoute(component appstudiov1alpha1.Component) routev1.Ro
 // which defines how a webhook-based trigger event would be handled -
 // In this case, a PipelineRun to build an image would be created.
 func GenerateTriggerTemplate(component appstudiov1alpha1.Component, gitopsConfig gitopsprepare.GitopsConfig) (*triggersapi.TriggerTemplate, error) {
-	params, err := getParamsForComponentBuild(component, false)
+	imageTagging := GetImageTagging(component, gitopsConfig)
+	params, err := getParamsForComponentBuild(component, false, imageTagging)
 	if err != nil {
 		return nil, err
 	}
+	paramNames := []string{"git-revision"}
+	if isImageTagComputed(imageTagging.Strategy) {
+		paramNames = append(paramNames, imageTagParam)
+	}
 	webhookBasedBuildTemplate := DetermineBuildExecution(component, params, "$(tt.params.git-revision)", gitopsConfig)
+	return generateTriggerTemplate(component, component.Name, BuildPushEventType, paramNames, webhookBasedBuildTemplate)
+}
+
+// GeneratePullRequestTriggerTemplate generates the TriggerTemplate resources which defines how a pull request event
+// would be handled - In this case, a PipelineRun building the head of the pull request into an image tagged with
+// the pull request number would be created, in a workspace separate from the mainline builds.
+func GeneratePullRequestTriggerTemplate(component appstudiov1alpha1.Component, gitopsConfig gitopsprepare.GitopsConfig) (*triggersapi.TriggerTemplate, error) {
+	params, err := getParamsForPullRequestBuild(component)
+	if err != nil {
+		return nil, err
+	}
+	pullRequestBuildTemplate := DetermineBuildExecution(component, params, "pr-$(tt.params.pull-request-number)/$(tt.params.git-revision)", gitopsConfig)
+	return generateTriggerTemplate(component, GetPullRequestTriggerName(component), BuildPullRequestEventType, []string{"git-revision", "pull-request-number"}, pullRequestBuildTemplate)
+}
+
+// generateTriggerTemplate generates a TriggerTemplate with the given parameters creating a PipelineRun with the given spec,
+// labelled with the type of the event triggering it
+func generateTriggerTemplate(component appstudiov1alpha1.Component, name string, eventType string, paramNames []string, pipelineRunSpec tektonapi.PipelineRunSpec) (*triggersapi.TriggerTemplate, error) {
+	labels := getBuildCommonLabelsForComponent(&component)
+	labels[BuildEventTypeLabel] = eventType
 	resoureTemplatePipelineRun := tektonapi.PipelineRun{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: component.Name + "-",
 			Namespace:    component.Namespace,
 			Annotations:  getBuildCommonLabelsForComponent(&component),
-			Labels:       getBuildCommonLabelsForComponent(&component),
+			Labels:       labels,
 		},
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "PipelineRun",
 			APIVersion: "tekton.dev/v1beta1",
 		},
-		Spec: webhookBasedBuildTemplate,
+		Spec: pipelineRunSpec,
 	}
 	resourceTemplatePipelineRunBytes, err := json.Marshal(resoureTemplatePipelineRun)
 	if err != nil {
 		return nil, err
 	}
+	var params []triggersapi.ParamSpec
+	for _, paramName := range paramNames {
+		params = append(params, triggersapi.ParamSpec{Name: paramName})
+	}
 	triggerTemplate := triggersapi.TriggerTemplate{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "TriggerTemplate",
 			APIVersion: "triggers.tekton.dev/v1beta1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      component.Name,
+			Name:      name,
 			Namespace: component.Namespace,
 		},
 		Spec: triggersapi.TriggerTemplateSpec{
-			Params: []triggersapi.ParamSpec{
-				{
-					Name: "git-revision",
-				},
-			},
+			Params: params,
 			ResourceTemplates: []triggersapi.TriggerResourceTemplate{
 				{
 					RawExtension: runtime.RawExtension{Raw: resourceTemplatePipelineRunBytes},
@@ -415,11 +712,238 @@ func GenerateTriggerTemplate(component appstudiov1alpha1.Component, gitopsConfig
 	return &triggerTemplate, err
 }
 
+// buildTriggerProvider describes the push and pull request events a git provider sends to the build webhook
+type buildTriggerProvider struct {
+	// eventHeader is the header holding the type of the event, and eventType the type of the push events
+	eventHeader string
+	eventType   string
+	// unsignedEvents is whether the provider does not sign its events, so they cannot be checked against the webhook secret
+	unsignedEvents bool
+	// refField is the CEL expression of the pushed git reference, which is the branch prefixed with refPrefix
+	refField  string
+	refPrefix string
+	// defaultBranchField is the CEL expression of the repository's default branch, if the events provide it
+	defaultBranchField string
+	// revisionField is the TriggerBinding expression of the pushed commit
+	revisionField string
+	// commitsField is the CEL expression of the pushed commits listing their added, modified and removed files,
+	// if the events provide them
+	commitsField string
+	// branchField and revisionDateField are the CEL expressions of the pushed branch and of the date of the pushed commit
+	branchField       string
+	revisionDateField string
+
+	// tagEventType is the type of the events sent when a tag is pushed, tagFilter the CEL expression selecting them
+	// and tagField the CEL expression of the pushed tag
+	tagEventType string
+	tagFilter    string
+	tagField     string
+
+	// pullRequestEventTypes are the types of the events sent when a pull request is opened or updated,
+	// and pullRequestActionFilter the CEL expression filtering them if the types are not specific enough
+	pullRequestEventTypes   []string
+	pullRequestActionFilter string
+	// pullRequestTargetField is the CEL expression of the branch the pull request targets
+	pullRequestTargetField string
+	// pullRequestRevisionField and pullRequestNumberField are the TriggerBinding expressions of the head commit
+	// and of the number of the pull request
+	pullRequestRevisionField string
+	pullRequestNumberField   string
+}
+
+// buildTriggerProviders holds the events of each supported git provider. Bitbucket refers to Bitbucket Cloud.
+var buildTriggerProviders = map[string]buildTriggerProvider{
+	"github": {
+		eventHeader:        "X-GitHub-Event",
+		eventType:          "push",
+		refField:           "body.ref",
+		refPrefix:          "refs/heads/",
+		defaultBranchField: "body.repository.default_branch",
+		revisionField:      "$(body.head_commit.id)",
+		commitsField:       "body.commits",
+		branchField:        "body.ref.replace('refs/heads/', '')",
+		revisionDateField:  "body.head_commit.timestamp",
+
+		tagEventType: "push",
+		tagFilter:    "body.ref.startsWith('refs/tags/')",
+		tagField:     "body.ref.replace('refs/tags/', '')",
+
+		pullRequestEventTypes:    []string{"pull_request"},
+		pullRequestActionFilter:  "body.action in ['opened', 'synchronize', 'reopened']",
+		pullRequestTargetField:   "body.pull_request.base.ref",
+		pullRequestRevisionField: "$(body.pull_request.head.sha)",
+		pullRequestNumberField:   "$(body.number)",
+	},
+	"gitlab": {
+		eventHeader:        "X-Gitlab-Event",
+		eventType:          "Push Hook",
+		refField:           "body.ref",
+		refPrefix:          "refs/heads/",
+		defaultBranchField: "body.project.default_branch",
+		revisionField:      "$(body.checkout_sha)",
+		commitsField:       "body.commits",
+		branchField:        "body.ref.replace('refs/heads/', '')",
+		revisionDateField:  "body.commits[size(body.commits) - 1].timestamp",
+
+		tagEventType: "Tag Push Hook",
+		tagFilter:    "body.ref.startsWith('refs/tags/')",
+		tagField:     "body.ref.replace('refs/tags/', '')",
+
+		pullRequestEventTypes:    []string{"Merge Request Hook"},
+		pullRequestActionFilter:  "body.object_attributes.action in ['open', 'update', 'reopen']",
+		pullRequestTargetField:   "body.object_attributes.target_branch",
+		pullRequestRevisionField: "$(body.object_attributes.last_commit.id)",
+		pullRequestNumberField:   "$(body.object_attributes.iid)",
+	},
+	"bitbucket": {
+		eventHeader:       "X-Event-Key",
+		eventType:         "repo:push",
+		unsignedEvents:    true,
+		refField:          "body.push.changes[0].new.name",
+		revisionField:     "$(body.push.changes[0].new.target.hash)",
+		branchField:       "body.push.changes[0].new.name",
+		revisionDateField: "body.push.changes[0].new.target.date",
+
+		tagEventType: "repo:push",
+		tagFilter:    "body.push.changes[0].new.type == 'tag'",
+		tagField:     "body.push.changes[0].new.name",
+
+		pullRequestEventTypes:    []string{"pullrequest:created", "pullrequest:updated"},
+		pullRequestTargetField:   "body.pullrequest.destination.branch.name",
+		pullRequestRevisionField: "$(body.pullrequest.source.commit.hash)",
+		pullRequestNumberField:   "$(body.pullrequest.id)",
+	},
+}
+
+// getBuildTriggerProvider returns the git provider of the component and its events.
+// GitHub events are expected if the git provider is not supported.
+func getBuildTriggerProvider(component appstudiov1alpha1.Component) (string, buildTriggerProvider) {
+	gitProvider, _ := GetGitProvider(component)
+	if provider, ok := buildTriggerProviders[gitProvider]; ok {
+		return gitProvider, provider
+	}
+	return "github", buildTriggerProviders["github"]
+}
+
+// IsPullRequestBuildEnabled returns whether the component opted in to build its pull requests
+func IsPullRequestBuildEnabled(component appstudiov1alpha1.Component) bool {
+	return component.Annotations[PullRequestBuildAnnotation] == "1"
+}
+
+// GetPullRequestTriggerName returns the name of the TriggerTemplate and TriggerBinding handling the pull request events of the component
+func GetPullRequestTriggerName(component appstudiov1alpha1.Component) string {
+	return component.Name + "-pull-request"
+}
+
+// GenerateTriggerBinding generates the TriggerBinding extracting the parameters of the TriggerTemplate
+// from the push events of the component's git provider, for clusters without the provider's ClusterTriggerBinding
+func GenerateTriggerBinding(component appstudiov1alpha1.Component) triggersapi.TriggerBinding {
+	gitProvider, provider := getBuildTriggerProvider(component)
+	return generateTriggerBinding(component, component.Name+"-"+gitopsprepare.PushClusterTriggerBindings[gitProvider], []triggersapi.Param{
+		{
+			Name:  "git-revision",
+			Value: provider.revisionField,
+		},
+	})
+}
+
+// GeneratePullRequestTriggerBinding generates the TriggerBinding extracting the parameters of the pull request
+// TriggerTemplate from the pull request events of the component's git provider
+func GeneratePullRequestTriggerBinding(component appstudiov1alpha1.Component) triggersapi.TriggerBinding {
+	_, provider := getBuildTriggerProvider(component)
+	return generateTriggerBinding(component, GetPullRequestTriggerName(component), []triggersapi.Param{
+		{
+			Name:  "git-revision",
+			Value: provider.pullRequestRevisionField,
+		},
+		{
+			Name:  "pull-request-number",
+			Value: provider.pullRequestNumberField,
+		},
+	})
+}
+
+func generateTriggerBinding(component appstudiov1alpha1.Component, name string, params []triggersapi.Param) triggersapi.TriggerBinding {
+	triggerBinding := triggersapi.TriggerBinding{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "TriggerBinding",
+			APIVersion: "triggers.tekton.dev/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   component.Namespace,
+			Annotations: getBuildCommonLabelsForComponent(&component),
+		},
+		Spec: triggersapi.TriggerBindingSpec{
+			Params: params,
+		},
+	}
+	return triggerBinding
+}
+
 // The GenerateEventListener is responsible for defining how to "parse" the incoming event ( "github-push ")
 // and create the resultant PipelineRun ( defined as a TriggerTemplate ).
 // The reconciler for EventListeners create a Service, which when exposed enables
 // ingress traffic from Github events.
-func GenerateEventListener(component appstudiov1alpha1.Component, triggerTemplate triggersapi.TriggerTemplate) triggersapi.EventListener {
+// Only the push events to the component's revision, or to the default branch if no revision is set,
+// signed with the component's build webhook secret are accepted. If the component has a context,
+// the pushed commits must also change a file under the context or under one of the build trigger paths.
+// The events are parsed with the ClusterTriggerBinding of the component's git provider, or with
+// the TriggerBinding generated by GenerateTriggerBinding if the cluster does not provide it.
+// If the component opted in to pull request builds, the pull requests targeting the same branch are built as well.
+func GenerateEventListener(component appstudiov1alpha1.Component, triggerTemplate triggersapi.TriggerTemplate, gitopsConfig gitopsprepare.GitopsConfig) (triggersapi.EventListener, error) {
+	gitProvider, provider := getBuildTriggerProvider(component)
+	imageTagging := GetImageTagging(component, gitopsConfig)
+
+	eventType := provider.eventType
+	var filter string
+	if imageTagging.Strategy == appstudiov1alpha1.GitTagImageTagStrategy {
+		// The pushed semantic version tags are built instead of the pushed commits
+		eventType = provider.tagEventType
+		filter = fmt.Sprintf("header.match(%q, %q) && %s && %s.matches('%s')", provider.eventHeader, eventType, provider.tagFilter, provider.tagField, semverTagPattern)
+	} else {
+		filter = fmt.Sprintf("header.match(%q, %q)", provider.eventHeader, eventType)
+		if revision := component.Spec.Source.GitSource.Revision; revision != "" {
+			filter += fmt.Sprintf(" && %s == %q", provider.refField, provider.refPrefix+revision)
+		} else if provider.defaultBranchField != "" {
+			filter += fmt.Sprintf(" && %s == '%s' + %s", provider.refField, provider.refPrefix, provider.defaultBranchField)
+		}
+		if pathsFilter := getChangedPathsFilter(provider.commitsField, getBuildTriggerPaths(component)); pathsFilter != "" {
+			filter += " && " + pathsFilter
+		}
+	}
+	var overlays []triggersapi.CELOverlay
+	if isImageTagComputed(imageTagging.Strategy) {
+		overlays = append(overlays, triggersapi.CELOverlay{
+			Key:        imageTagExtension,
+			Expression: getImageTagExpression(imageTagging.Strategy, provider),
+		})
+	}
+	interceptors, err := getBuildWebhookInterceptors(component, []string{eventType}, filter, overlays)
+	if err != nil {
+		return triggersapi.EventListener{}, err
+	}
+
+	binding := &triggersapi.TriggerSpecBinding{
+		Ref:  gitopsprepare.PushClusterTriggerBindings[gitProvider],
+		Kind: triggersapi.ClusterTriggerBindingKind,
+	}
+	if gitopsConfig.MissingClusterTriggerBindings[binding.Ref] {
+		binding = &triggersapi.TriggerSpecBinding{
+			Ref:  GenerateTriggerBinding(component).Name,
+			Kind: triggersapi.NamespacedTriggerBindingKind,
+		}
+	}
+
+	bindings := []*triggersapi.TriggerSpecBinding{binding}
+	if isImageTagComputed(imageTagging.Strategy) {
+		imageTagValue := "$(extensions." + imageTagExtension + ")"
+		bindings = append(bindings, &triggersapi.TriggerSpecBinding{
+			Name:  imageTagParam,
+			Value: &imageTagValue,
+		})
+	}
+
 	eventListener := triggersapi.EventListener{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "EventListener",
@@ -431,15 +955,11 @@ func GenerateEventListener(component appstudiov1alpha1.Component, triggerTemplat
 			Annotations: getBuildCommonLabelsForComponent(&component),
 		},
 		Spec: triggersapi.EventListenerSpec{
-			ServiceAccountName: "pipeline",
+			ServiceAccountName: GetBuildServiceAccountName(component, gitopsConfig),
 			Triggers: []triggersapi.EventListenerTrigger{
 				{
-					Bindings: []*triggersapi.TriggerSpecBinding{
-						{
-							Ref:  "github-push",
-							Kind: triggersapi.ClusterTriggerBindingKind,
-						},
-					},
+					Bindings:     bindings,
+					Interceptors: interceptors,
 					Template: &triggersapi.TriggerSpecTemplate{
 						Ref: &triggerTemplate.Name,
 					},
@@ -447,7 +967,146 @@ func GenerateEventListener(component appstudiov1alpha1.Component, triggerTemplat
 			},
 		},
 	}
-	return eventListener
+
+	if IsPullRequestBuildEnabled(component) {
+		pullRequestTrigger, err := getPullRequestTrigger(component)
+		if err != nil {
+			return triggersapi.EventListener{}, err
+		}
+		eventListener.Spec.Triggers = append(eventListener.Spec.Triggers, pullRequestTrigger)
+	}
+	return eventListener, nil
+}
+
+// getBuildTriggerPaths returns the paths of the repository whose changes trigger a build of the component:
+// its context and its additional build trigger paths. A component whose context is the whole repository is only
+// built on the changes to its build trigger paths if it has some. No paths are returned if any change triggers a build.
+func getBuildTriggerPaths(component appstudiov1alpha1.Component) []string {
+	gitSource := component.Spec.Source.GitSource
+	triggerPaths := gitSource.BuildTriggerPaths
+	if context := path.Clean("/" + gitSource.Context); context != "/" || len(triggerPaths) == 0 {
+		triggerPaths = append([]string{gitSource.Context}, triggerPaths...)
+	}
+	var paths []string
+	for _, triggerPath := range triggerPaths {
+		triggerPath = path.Clean("/" + triggerPath)
+		if triggerPath == "/" {
+			// Any change in the repository triggers a build
+			return nil
+		}
+		paths = append(paths, strings.TrimPrefix(triggerPath, "/"))
+	}
+	return paths
+}
+
+// getChangedPathsFilter returns the CEL expression matching the events whose commits added, modified or removed a file
+// under one of the paths. An empty expression is returned if there are no paths or the events do not list the files.
+func getChangedPathsFilter(commitsField string, paths []string) string {
+	if commitsField == "" || len(paths) == 0 {
+		return ""
+	}
+	var conditions []string
+	for _, changedPath := range paths {
+		conditions = append(conditions, fmt.Sprintf("f == %q || f.startsWith(%q)", changedPath, changedPath+"/"))
+	}
+	fileFilter := strings.Join(conditions, " || ")
+	return fmt.Sprintf("%s.exists(c, c.added.exists(f, %s) || c.modified.exists(f, %s) || c.removed.exists(f, %s))", commitsField, fileFilter, fileFilter, fileFilter)
+}
+
+// getPullRequestTrigger returns the trigger building the pull requests opened or updated against the component's revision,
+// or against the default branch if no revision is set
+func getPullRequestTrigger(component appstudiov1alpha1.Component) (triggersapi.EventListenerTrigger, error) {
+	_, provider := getBuildTriggerProvider(component)
+
+	var conditions []string
+	for _, eventType := range provider.pullRequestEventTypes {
+		conditions = append(conditions, fmt.Sprintf("header.match(%q, %q)", provider.eventHeader, eventType))
+	}
+	filter := "(" + strings.Join(conditions, " || ") + ")"
+	if provider.pullRequestActionFilter != "" {
+		filter += " && " + provider.pullRequestActionFilter
+	}
+	if revision := component.Spec.Source.GitSource.Revision; revision != "" {
+		filter += fmt.Sprintf(" && %s == %q", provider.pullRequestTargetField, revision)
+	} else if provider.defaultBranchField != "" {
+		filter += fmt.Sprintf(" && %s == %s", provider.pullRequestTargetField, provider.defaultBranchField)
+	}
+	interceptors, err := getBuildWebhookInterceptors(component, provider.pullRequestEventTypes, filter, nil)
+	if err != nil {
+		return triggersapi.EventListenerTrigger{}, err
+	}
+
+	triggerName := GetPullRequestTriggerName(component)
+	return triggersapi.EventListenerTrigger{
+		Name: "pull-request",
+		Bindings: []*triggersapi.TriggerSpecBinding{
+			{
+				Ref:  triggerName,
+				Kind: triggersapi.NamespacedTriggerBindingKind,
+			},
+		},
+		Interceptors: interceptors,
+		Template: &triggersapi.TriggerSpecTemplate{
+			Ref: &triggerName,
+		},
+	}, nil
+}
+
+// getBuildWebhookInterceptors returns the interceptors validating the events of the given types sent to the build webhook
+// with the git provider's interceptor, and filtering them with the given CEL expression. The overlays, if any, add the
+// values they compute from the events to the extensions passed to the bindings.
+// The branch is not filtered if no revision is set and the git provider's events do not tell the default branch.
+// The events are checked against the webhook secret unless the git provider does not sign them.
+func getBuildWebhookInterceptors(component appstudiov1alpha1.Component, eventTypes []string, filter string, overlays []triggersapi.CELOverlay) ([]*triggersapi.EventInterceptor, error) {
+	gitProvider, provider := getBuildTriggerProvider(component)
+
+	var providerParams []triggersapi.InterceptorParams
+	if !provider.unsignedEvents {
+		secretRefValue, err := json.Marshal(triggersapi.SecretRef{
+			SecretName: GetBuildWebhookSecretName(component),
+			SecretKey:  BuildWebhookSecretKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+		providerParams = append(providerParams, triggersapi.InterceptorParams{Name: "secretRef", Value: apiextensionsv1.JSON{Raw: secretRefValue}})
+	}
+	eventTypesValue, err := json.Marshal(eventTypes)
+	if err != nil {
+		return nil, err
+	}
+	providerParams = append(providerParams, triggersapi.InterceptorParams{Name: "eventTypes", Value: apiextensionsv1.JSON{Raw: eventTypesValue}})
+	filterValue, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+	celParams := []triggersapi.InterceptorParams{
+		{Name: "filter", Value: apiextensionsv1.JSON{Raw: filterValue}},
+	}
+	if len(overlays) > 0 {
+		overlaysValue, err := json.Marshal(overlays)
+		if err != nil {
+			return nil, err
+		}
+		celParams = append(celParams, triggersapi.InterceptorParams{Name: "overlays", Value: apiextensionsv1.JSON{Raw: overlaysValue}})
+	}
+
+	return []*triggersapi.EventInterceptor{
+		{
+			Ref: triggersapi.InterceptorRef{
+				Name: gitProvider,
+				Kind: triggersapi.ClusterInterceptorKind,
+			},
+			Params: providerParams,
+		},
+		{
+			Ref: triggersapi.InterceptorRef{
+				Name: "cel",
+				Kind: triggersapi.ClusterInterceptorKind,
+			},
+			Params: celParams,
+		},
+	}, nil
 }
 
 // GeneratePACRepository creates configuration of Pipelines as Code repository object.
@@ -462,10 +1121,17 @@ func GeneratePACRepository(component appstudiov1alpha1.Component, config map[str
 	var gitProviderConfig *pacv1alpha1.GitProvider = nil
 	if !isAppUsed {
 		// Webhook is used
+		host := getGitSourceHost(component)
+		providerURL, err := GetGitProviderURL(component, gitProvider)
+		if err != nil {
+			return nil, err
+		}
+
 		gitProviderConfig = &pacv1alpha1.GitProvider{
+			URL: providerURL,
 			Secret: &pacv1alpha1.Secret{
 				Name: gitopsprepare.PipelinesAsCodeSecretName,
-				Key:  GetProviderTokenKey(gitProvider),
+				Key:  GetProviderCredentialKey(gitProvider, host, "token", config),
 			},
 			WebhookSecret: &pacv1alpha1.Secret{
 				Name: PipelinesAsCodeWebhooksSecretName,
@@ -473,8 +1139,10 @@ func GeneratePACRepository(component appstudiov1alpha1.Component, config map[str
 			},
 		}
 
-		if gitProvider == "gitlab" {
-			gitProviderConfig.URL = "https://gitlab.com"
+		if gitProvider == "bitbucket" {
+			// Bitbucket authenticates the token together with the name of its user. Without it, the Repository is still
+			// generated, but Pipelines as Code cannot report the builds to Bitbucket until the user is configured.
+			gitProviderConfig.User = GetGitProviderUser(component, config)
 		}
 	}
 
@@ -497,9 +1165,89 @@ func GeneratePACRepository(component appstudiov1alpha1.Component, config map[str
 	return repository, nil
 }
 
-// GetProviderTokenKey returns key (field name) of the given provider access token in the Pipelines as Code k8s secret
-func GetProviderTokenKey(gitProvider string) string {
-	return gitProvider + ".token"
+// GetProviderCredentialKey returns key (field name) of the given credential of the provider in the Pipelines as Code k8s secret.
+// The key of the git host, e.g. gitlab.example.com.token, is preferred over the key of the provider, e.g. gitlab.token,
+// when present in the secret, so that several self-hosted instances of a provider can be used.
+func GetProviderCredentialKey(gitProvider, host, credential string, config map[string][]byte) string {
+	if host != "" {
+		hostKey := gitProvider + "." + host + "." + credential
+		if len(config[hostKey]) != 0 {
+			return hostKey
+		}
+	}
+	return gitProvider + "." + credential
+}
+
+// GetGitProviderUser returns the name of the user of the git provider token of the component, or empty string if it is not configured.
+// The user is taken from the gitProviderUser field of the git source, or the git-provider-user annotation, of the component
+// if set, otherwise from the user key of the provider in the Pipelines as Code secret.
+func GetGitProviderUser(component appstudiov1alpha1.Component, config map[string][]byte) string {
+	if user := component.Spec.Source.GitSource.GitProviderUser; user != "" {
+		return user
+	}
+	if user := component.GetAnnotations()[GitProviderUserAnnotationName]; user != "" {
+		return user
+	}
+	gitProvider, err := GetGitProvider(component)
+	if err != nil {
+		return ""
+	}
+	return string(config[GetProviderCredentialKey(gitProvider, getGitSourceHost(component), "user", config)])
+}
+
+// GetGitProviderURL returns the API URL of the git provider of the component, or empty string if Pipelines as Code
+// defaults to the public service of the provider.
+// The URL is taken from the gitProviderUrl field of the git source, or the git-provider-url annotation, if set,
+// otherwise it is derived from the host of the source url.
+func GetGitProviderURL(component appstudiov1alpha1.Component, gitProvider string) (string, error) {
+	providerURL, providerURLSource := component.Spec.Source.GitSource.GitProviderURL, "\"gitProviderUrl\" field"
+	if providerURL == "" {
+		providerURL, providerURLSource = component.GetAnnotations()[GitProviderURLAnnotationName], fmt.Sprintf("\"%s\" annotation", GitProviderURLAnnotationName)
+	}
+	if providerURL != "" {
+		u, err := url.Parse(providerURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "", fmt.Errorf("invalid %s value: %s", providerURLSource, providerURL)
+		}
+		return strings.TrimSuffix(providerURL, "/"), nil
+	}
+
+	u, err := url.Parse(component.Spec.Source.GitSource.URL)
+	if err != nil {
+		return "", err
+	}
+	scheme := u.Scheme
+	if scheme != "http" {
+		scheme = "https"
+	}
+	baseURL := scheme + "://" + u.Host
+
+	switch gitProvider {
+	case "github":
+		if u.Hostname() == "github.com" {
+			return "", nil
+		}
+		// GitHub Enterprise
+		return baseURL + "/api/v3", nil
+	case "gitlab":
+		return baseURL, nil
+	case "bitbucket":
+		if u.Hostname() == "bitbucket.org" {
+			return "", nil
+		}
+		// Bitbucket Server
+		return baseURL + "/rest", nil
+	}
+	return "", nil
+}
+
+// getGitSourceHost returns the host of the source url of the component, or empty string if it cannot be parsed
+func getGitSourceHost(component appstudiov1alpha1.Component) string {
+	u, err := url.Parse(component.Spec.Source.GitSource.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
 }
 
 func GetWebhookSecretKeyForComponent(component appstudiov1alpha1.Component) string {
@@ -34,7 +34,9 @@ import (
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersapi "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
@@ -121,6 +123,117 @@ func TestGenerateBuild(t *testing.T) {
 				buildWebhookRouteFileName,
 			},
 		},
+		{
+			name: "Check trigger based resources exposed with an ingress",
+			fs:   ioutils.NewMemoryFilesystem(),
+			component: appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testcomponent",
+					Namespace: "workspace-name",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "https://host/git-repo.git",
+							},
+						},
+					},
+				},
+			},
+			gitopsConfig: gitopsprepare.GitopsConfig{Ingress: &gitopsprepare.IngressConfig{ClassName: "nginx", HostTemplate: "{{ .Name }}-{{ .Namespace }}.apps.example.com"}},
+			want: []string{
+				kustomizeFileName,
+				buildTriggerTemplateFileName,
+				buildEventListenerFileName,
+				buildWebhookIngressFileName,
+			},
+		},
+		{
+			name: "Check trigger based resources without a cluster trigger binding",
+			fs:   ioutils.NewMemoryFilesystem(),
+			component: appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testcomponent",
+					Namespace: "workspace-name",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "https://gitlab.com/user/git-repo.git",
+							},
+						},
+					},
+				},
+			},
+			gitopsConfig: gitopsprepare.GitopsConfig{MissingClusterTriggerBindings: map[string]bool{"gitlab-push": true}},
+			want: []string{
+				kustomizeFileName,
+				buildTriggerTemplateFileName,
+				buildEventListenerFileName,
+				buildTriggerBindingFileName,
+				buildWebhookRouteFileName,
+			},
+		},
+		{
+			name: "Check trigger based resources with pull request builds",
+			fs:   ioutils.NewMemoryFilesystem(),
+			component: appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testcomponent",
+					Namespace: "workspace-name",
+					Annotations: map[string]string{
+						PullRequestBuildAnnotation: "1",
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "https://github.com/user/git-repo.git",
+							},
+						},
+					},
+				},
+			},
+			gitopsConfig: emptyGitopsConfig,
+			want: []string{
+				kustomizeFileName,
+				buildTriggerTemplateFileName,
+				buildEventListenerFileName,
+				buildPullRequestTriggerTemplateFileName,
+				buildPullRequestTriggerBindingFileName,
+				buildWebhookRouteFileName,
+			},
+		},
+		{
+			name: "Check trigger based resources with a component build workspace",
+			fs:   ioutils.NewMemoryFilesystem(),
+			component: appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testcomponent",
+					Namespace: "workspace-name",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "https://github.com/user/git-repo.git",
+							},
+						},
+					},
+				},
+			},
+			gitopsConfig: gitopsprepare.GitopsConfig{BuildWorkspace: gitopsprepare.BuildWorkspaceConfig{Strategy: gitopsprepare.ComponentBuildWorkspace}},
+			want: []string{
+				kustomizeFileName,
+				buildTriggerTemplateFileName,
+				buildEventListenerFileName,
+				buildWebhookRouteFileName,
+				"testcomponent-build-workspace-pvc.yaml",
+			},
+		},
 		{
 			name: "Check pipeline as code resources with annotation",
 			fs:   ioutils.NewMemoryFilesystem(),
@@ -261,6 +374,37 @@ func TestNormalizeOutputImageURL(t *testing.T) {
 	}
 }
 
+func TestNormalizePullRequestOutputImageURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		outputImage string
+		want        string
+	}{
+		{
+			name:        "not a fully qualified url",
+			outputImage: "quay.io/foo/bar",
+			want:        "quay.io/foo/bar:pr-$(tt.params.pull-request-number)",
+		},
+		{
+			name:        "fully qualified url",
+			outputImage: "quay.io/foo/bar:latest",
+			want:        "quay.io/foo/bar:latest-pr-$(tt.params.pull-request-number)",
+		},
+		{
+			name:        "contains git revision suffix in tag",
+			outputImage: "quay.io/foo/bar:tag-29b0823364ba05bd5a9d3a89d4e6cad57d2d3723",
+			want:        "quay.io/foo/bar:tag-pr-$(tt.params.pull-request-number)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizePullRequestOutputImageURL(tt.outputImage); got != tt.want {
+				t.Errorf("normalizePullRequestOutputImageURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestProtectDefaultImageRepo(t *testing.T) {
 	type args struct {
 		outputImage string
@@ -616,7 +760,97 @@ func TestDetermineBuildExecution(t *testing.T) {
 	}
 }
 
-func TestDetermineBuildPipeline(t *testing.T) {
+func TestGetBuildWorkspaceBinding(t *testing.T) {
+	component := appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testcomponent",
+			Namespace: "workspace-name",
+		},
+	}
+	storageClassName := "fast"
+
+	tests := []struct {
+		name            string
+		workspaceConfig gitopsprepare.BuildWorkspaceConfig
+		want            tektonapi.WorkspaceBinding
+	}{
+		{
+			name: "Default shared claim",
+			want: tektonapi.WorkspaceBinding{
+				Name:                  "workspace",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "appstudio"},
+				SubPath:               "testcomponent/initialbuild",
+			},
+		},
+		{
+			name:            "Configured shared claim",
+			workspaceConfig: gitopsprepare.BuildWorkspaceConfig{Strategy: gitopsprepare.SharedBuildWorkspace, ClaimName: "builds"},
+			want: tektonapi.WorkspaceBinding{
+				Name:                  "workspace",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "builds"},
+				SubPath:               "testcomponent/initialbuild",
+			},
+		},
+		{
+			name:            "Component claim",
+			workspaceConfig: gitopsprepare.BuildWorkspaceConfig{Strategy: gitopsprepare.ComponentBuildWorkspace},
+			want: tektonapi.WorkspaceBinding{
+				Name:                  "workspace",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "testcomponent-build-workspace"},
+				SubPath:               "initialbuild",
+			},
+		},
+		{
+			name: "PipelineRun claim",
+			workspaceConfig: gitopsprepare.BuildWorkspaceConfig{
+				Strategy:         gitopsprepare.PipelineRunBuildWorkspace,
+				StorageClassName: storageClassName,
+				Size:             resource.MustParse("5Gi"),
+			},
+			want: tektonapi.WorkspaceBinding{
+				Name: "workspace",
+				VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources:        corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")}},
+						StorageClassName: &storageClassName,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getBuildWorkspaceBinding(component, "initialbuild", tt.workspaceConfig); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getBuildWorkspaceBinding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateBuildWorkspaceClaim(t *testing.T) {
+	component := appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testcomponent",
+			Namespace: "workspace-name",
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			Application: "test-application",
+		},
+	}
+
+	claim := GenerateBuildWorkspaceClaim(component, gitopsprepare.BuildWorkspaceConfig{Strategy: gitopsprepare.ComponentBuildWorkspace})
+
+	assert.Equal(t, "testcomponent-build-workspace", claim.Name)
+	assert.Equal(t, "workspace-name", claim.Namespace)
+	assert.Equal(t, "testcomponent", claim.Labels["build.appstudio.openshift.io/component"])
+	assert.Nil(t, claim.Spec.StorageClassName)
+	size := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	assert.Equal(t, "1Gi", size.String())
+}
+
+func TestSelectBuildPipeline(t *testing.T) {
 	createDevfileWithBuildInfo := func(language string, projectType string) data.DevfileData {
 		devfileVersion := string(data.APISchemaVersion220)
 		devfileData, _ := data.NewDevfileData(devfileVersion)
@@ -638,9 +872,12 @@ func TestDetermineBuildPipeline(t *testing.T) {
 	}
 
 	tests := []struct {
-		name      string
-		component appstudiov1alpha1.Component
-		want      string
+		name         string
+		component    appstudiov1alpha1.Component
+		gitopsConfig gitopsprepare.GitopsConfig
+		want         string
+		wantParams   []appstudiov1alpha1.BuildPipelineParam
+		wantReason   string
 	}{
 		{
 			name: "should use java builder",
@@ -688,11 +925,102 @@ func TestDetermineBuildPipeline(t *testing.T) {
 			},
 			want: "docker-build",
 		},
+		{
+			name: "should use the first matching configured selector",
+			component: appstudiov1alpha1.Component{
+				Status: appstudiov1alpha1.ComponentStatus{
+					Devfile: createDevfileStatusModelWithBuildInfo("Python", "django"),
+				},
+			},
+			gitopsConfig: gitopsprepare.GitopsConfig{
+				BuildPipelineSelectors: []gitopsprepare.BuildPipelineSelector{
+					{Name: "flask", PipelineName: "flask-builder", Language: "python", ProjectType: "flask"},
+					{Name: "python", PipelineName: "python-builder", Language: "python", Params: []appstudiov1alpha1.BuildPipelineParam{{Name: "python-version", Value: "3.9"}}},
+					{Name: "fallback", PipelineName: "docker-build"},
+				},
+			},
+			want:       "python-builder",
+			wantParams: []appstudiov1alpha1.BuildPipelineParam{{Name: "python-version", Value: "3.9"}},
+			wantReason: "Matched the python build pipeline selector",
+		},
+		{
+			name: "should not use the default selectors if selectors are configured",
+			component: appstudiov1alpha1.Component{
+				Status: appstudiov1alpha1.ComponentStatus{
+					Devfile: createDevfileStatusModelWithBuildInfo("java", ""),
+				},
+			},
+			gitopsConfig: gitopsprepare.GitopsConfig{
+				BuildPipelineSelectors: []gitopsprepare.BuildPipelineSelector{
+					{Name: "dockerfile", PipelineName: "docker-build", Dockerfile: true},
+				},
+			},
+			want:       "noop",
+			wantReason: "No build pipeline selector matched the devfile of the Component",
+		},
+		{
+			name: "should use the pipeline set in the component",
+			component: appstudiov1alpha1.Component{
+				Spec: appstudiov1alpha1.ComponentSpec{
+					BuildPipeline: &appstudiov1alpha1.BuildPipelineOverride{
+						Name:   "custom-builder",
+						Params: []appstudiov1alpha1.BuildPipelineParam{{Name: "path-context", Value: "src"}},
+					},
+				},
+				Status: appstudiov1alpha1.ComponentStatus{
+					Devfile: createDevfileStatusModelWithBuildInfo("java", ""),
+				},
+			},
+			want:       "custom-builder",
+			wantParams: []appstudiov1alpha1.BuildPipelineParam{{Name: "path-context", Value: "src"}},
+			wantReason: "Set in the buildPipeline of the Component",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := determineBuildPipeline(tt.component); got != tt.want {
-				t.Errorf("determineBuildPipeline() = %v, want %v", got, tt.want)
+			got := SelectBuildPipeline(tt.component, tt.gitopsConfig)
+			if got.Name != tt.want {
+				t.Errorf("SelectBuildPipeline() = %v, want %v", got.Name, tt.want)
+			}
+			if !reflect.DeepEqual(got.Params, tt.wantParams) {
+				t.Errorf("SelectBuildPipeline() params = %v, want %v", got.Params, tt.wantParams)
+			}
+			if tt.wantReason != "" && got.Reason != tt.wantReason {
+				t.Errorf("SelectBuildPipeline() reason = %q, want %q", got.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestMergeBuildPipelineParams(t *testing.T) {
+	stringParam := func(name, value string) tektonapi.Param {
+		return tektonapi.Param{Name: name, Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: value}}
+	}
+	params := []tektonapi.Param{stringParam("git-url", "https://github.com/user/repo"), stringParam("output-image", "quay.io/user/image")}
+
+	tests := []struct {
+		name             string
+		additionalParams []appstudiov1alpha1.BuildPipelineParam
+		want             []tektonapi.Param
+	}{
+		{
+			name: "No additional parameters",
+			want: params,
+		},
+		{
+			name:             "Additional parameters are appended or replace the parameters of the same name",
+			additionalParams: []appstudiov1alpha1.BuildPipelineParam{{Name: "output-image", Value: "quay.io/user/other"}, {Name: "dockerfile", Value: "Containerfile"}},
+			want:             []tektonapi.Param{stringParam("git-url", "https://github.com/user/repo"), stringParam("output-image", "quay.io/user/other"), stringParam("dockerfile", "Containerfile")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeBuildPipelineParams(params, tt.additionalParams); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeBuildPipelineParams() = %v, want %v", got, tt.want)
+			}
+			// The parameters derived from the component are left untouched
+			if params[1].Value.StringVal != "quay.io/user/image" {
+				t.Errorf("mergeBuildPipelineParams() modified the given parameters")
 			}
 		})
 	}
@@ -786,6 +1114,9 @@ func TestGenerateTriggerTemplate(t *testing.T) {
 						if !ok || appA != tt.component.Spec.Application {
 							t.Errorf("GenerateTriggerTemplate() app annotation incorrect: %v %s", ok, appA)
 						}
+						if pr.Labels[BuildEventTypeLabel] != BuildPushEventType {
+							t.Errorf("GenerateTriggerTemplate() event type label incorrect: %s", pr.Labels[BuildEventTypeLabel])
+						}
 					}
 				}
 			}
@@ -793,6 +1124,42 @@ func TestGenerateTriggerTemplate(t *testing.T) {
 	}
 }
 
+func TestGeneratePullRequestTriggerTemplate(t *testing.T) {
+	component := appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testcomponent",
+			Namespace: "kcpworkspacename",
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			ContainerImage: "quay.io/foo/bar:mytag",
+			Source: appstudiov1alpha1.ComponentSource{
+				ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+					GitSource: &appstudiov1alpha1.GitSource{
+						URL:      "https://github.com/user/git-repo.git",
+						Revision: "main",
+					},
+				},
+			},
+		},
+	}
+
+	got, err := GeneratePullRequestTriggerTemplate(component, gitopsprepare.GitopsConfig{})
+	testutils.AssertNoError(t, err)
+	assert.Equal(t, "testcomponent-pull-request", got.Name)
+	assert.Equal(t, []triggersapi.ParamSpec{{Name: "git-revision"}, {Name: "pull-request-number"}}, got.Spec.Params)
+
+	var pr tektonapi.PipelineRun
+	testutils.AssertNoError(t, json.Unmarshal(got.Spec.ResourceTemplates[0].Raw, &pr))
+	params := map[string]string{}
+	for _, param := range pr.Spec.Params {
+		params[param.Name] = param.Value.StringVal
+	}
+	assert.Equal(t, "quay.io/foo/bar:mytag-pr-$(tt.params.pull-request-number)", params["output-image"])
+	assert.Equal(t, "$(tt.params.git-revision)", params["revision"])
+	assert.Equal(t, "testcomponent/pr-$(tt.params.pull-request-number)/$(tt.params.git-revision)", pr.Spec.Workspaces[0].SubPath)
+	assert.Equal(t, BuildPullRequestEventType, pr.Labels[BuildEventTypeLabel])
+}
+
 func TestGetParamsForComponentBuild(t *testing.T) {
 	getDevfileWithOuterloopBuildDockerfile := func() string {
 		devfileVersion := string(data.APISchemaVersion220)
@@ -1028,7 +1395,7 @@ func TestGetParamsForComponentBuild(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := getParamsForComponentBuild(tt.component, tt.IsInitialBuild)
+			got, err := getParamsForComponentBuild(tt.component, tt.IsInitialBuild, appstudiov1alpha1.ImageTagging{})
 			if err != nil && !tt.wantErr {
 				t.Errorf("GetParamsForComponentBuild() unexpected error: %s", err.Error())
 			}
@@ -1064,8 +1431,12 @@ func TestGeneratePACRepository(t *testing.T) {
 	tests := []struct {
 		name                      string
 		repoUrl                   string
+		annotations               map[string]string
+		gitProviderURL            string
+		gitProviderUser           string
 		pacConfig                 map[string][]byte
 		expectedGitProviderConfig *pacv1alpha1.GitProvider
+		expectError               bool
 	}{
 		{
 			name:    "should create PaC repository for Github application",
@@ -1143,32 +1514,182 @@ func TestGeneratePACRepository(t *testing.T) {
 				URL: "https://gitlab.com",
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			component := getComponent(tt.repoUrl)
-
-			pacRepo, err := GeneratePACRepository(component, tt.pacConfig)
-
-			if err != nil {
-				t.Errorf("Failed to generate PaC repository object. Cause: %v", err)
-			}
-
-			if pacRepo.Name != component.Name {
-				t.Errorf("Generated PaC repository must have the same name as corresponding component")
-			}
-			if pacRepo.Namespace != component.Namespace {
-				t.Errorf("Generated PaC repository must have the same namespace as corresponding component")
-			}
-			if len(pacRepo.Annotations) == 0 {
-				t.Errorf("Generated PaC repository must have annotations")
-			}
-			if pacRepo.Annotations["build.appstudio.openshift.io/component"] != component.Name {
-				t.Errorf("Generated PaC repository must have component annotation")
-			}
-
-			if pacRepo.Spec.URL != tt.repoUrl {
+		{
+			name:    "should create PaC repository for self-hosted GitLab webhook",
+			repoUrl: "https://gitlab.example.com/user/test-component-repository",
+			annotations: map[string]string{
+				GitProviderAnnotationName: "gitlab",
+			},
+			pacConfig: map[string][]byte{
+				"gitlab.token":                    []byte("glpat-token"),
+				"gitlab.gitlab.example.com.token": []byte("glpat-example-token"),
+			},
+			expectedGitProviderConfig: &pacv1alpha1.GitProvider{
+				Secret: &pacv1alpha1.Secret{
+					Name: gitopsprepare.PipelinesAsCodeSecretName,
+					Key:  "gitlab.gitlab.example.com.token",
+				},
+				WebhookSecret: &pacv1alpha1.Secret{
+					Name: PipelinesAsCodeWebhooksSecretName,
+					Key:  GetWebhookSecretKeyForComponent(getComponent("https://gitlab.example.com/user/test-component-repository")),
+				},
+				URL: "https://gitlab.example.com",
+			},
+		},
+		{
+			name:    "should create PaC repository for self-hosted GitLab webhook with explicit API URL",
+			repoUrl: "https://git.example.com/user/test-component-repository",
+			annotations: map[string]string{
+				GitProviderAnnotationName:    "gitlab",
+				GitProviderURLAnnotationName: "https://gitlab-api.example.com/",
+			},
+			pacConfig: map[string][]byte{
+				"gitlab.token": []byte("glpat-token"),
+			},
+			expectedGitProviderConfig: &pacv1alpha1.GitProvider{
+				Secret: &pacv1alpha1.Secret{
+					Name: gitopsprepare.PipelinesAsCodeSecretName,
+					Key:  "gitlab.token",
+				},
+				WebhookSecret: &pacv1alpha1.Secret{
+					Name: PipelinesAsCodeWebhooksSecretName,
+					Key:  GetWebhookSecretKeyForComponent(getComponent("https://git.example.com/user/test-component-repository")),
+				},
+				URL: "https://gitlab-api.example.com",
+			},
+		},
+		{
+			name:    "should create PaC repository for Bitbucket Cloud webhook",
+			repoUrl: "https://bitbucket.org/user/test-component-repository",
+			pacConfig: map[string][]byte{
+				"bitbucket.token": []byte("app-password"),
+				"bitbucket.user":  []byte("bitbucket-user"),
+			},
+			expectedGitProviderConfig: &pacv1alpha1.GitProvider{
+				Secret: &pacv1alpha1.Secret{
+					Name: gitopsprepare.PipelinesAsCodeSecretName,
+					Key:  "bitbucket.token",
+				},
+				WebhookSecret: &pacv1alpha1.Secret{
+					Name: PipelinesAsCodeWebhooksSecretName,
+					Key:  GetWebhookSecretKeyForComponent(getComponent("https://bitbucket.org/user/test-component-repository")),
+				},
+				User: "bitbucket-user",
+			},
+		},
+		{
+			name:    "should create PaC repository for Bitbucket Server webhook",
+			repoUrl: "https://bitbucket.example.com/scm/project/test-component-repository.git",
+			annotations: map[string]string{
+				GitProviderAnnotationName:     "bitbucket",
+				GitProviderUserAnnotationName: "project-admin",
+			},
+			pacConfig: map[string][]byte{
+				"bitbucket.bitbucket.example.com.token": []byte("personal-token"),
+				"bitbucket.user":                        []byte("bitbucket-user"),
+			},
+			expectedGitProviderConfig: &pacv1alpha1.GitProvider{
+				Secret: &pacv1alpha1.Secret{
+					Name: gitopsprepare.PipelinesAsCodeSecretName,
+					Key:  "bitbucket.bitbucket.example.com.token",
+				},
+				WebhookSecret: &pacv1alpha1.Secret{
+					Name: PipelinesAsCodeWebhooksSecretName,
+					Key:  GetWebhookSecretKeyForComponent(getComponent("https://bitbucket.example.com/scm/project/test-component-repository.git")),
+				},
+				URL:  "https://bitbucket.example.com/rest",
+				User: "project-admin",
+			},
+		},
+		{
+			name:    "should create PaC repository for Bitbucket Server webhook with the git source fields",
+			repoUrl: "https://bitbucket.example.com/scm/project/test-component-repository.git",
+			annotations: map[string]string{
+				GitProviderAnnotationName:     "bitbucket",
+				GitProviderUserAnnotationName: "project-admin",
+				GitProviderURLAnnotationName:  "https://bitbucket-annotation.example.com/rest",
+			},
+			gitProviderURL:  "https://bitbucket-api.example.com/rest/",
+			gitProviderUser: "repository-admin",
+			pacConfig: map[string][]byte{
+				"bitbucket.token": []byte("personal-token"),
+			},
+			expectedGitProviderConfig: &pacv1alpha1.GitProvider{
+				Secret: &pacv1alpha1.Secret{
+					Name: gitopsprepare.PipelinesAsCodeSecretName,
+					Key:  "bitbucket.token",
+				},
+				WebhookSecret: &pacv1alpha1.Secret{
+					Name: PipelinesAsCodeWebhooksSecretName,
+					Key:  GetWebhookSecretKeyForComponent(getComponent("https://bitbucket.example.com/scm/project/test-component-repository.git")),
+				},
+				URL:  "https://bitbucket-api.example.com/rest",
+				User: "repository-admin",
+			},
+		},
+		{
+			name:    "should create PaC repository for Bitbucket webhook without user",
+			repoUrl: "https://bitbucket.org/user/test-component-repository",
+			pacConfig: map[string][]byte{
+				"bitbucket.token": []byte("app-password"),
+			},
+			expectedGitProviderConfig: &pacv1alpha1.GitProvider{
+				Secret: &pacv1alpha1.Secret{
+					Name: gitopsprepare.PipelinesAsCodeSecretName,
+					Key:  "bitbucket.token",
+				},
+				WebhookSecret: &pacv1alpha1.Secret{
+					Name: PipelinesAsCodeWebhooksSecretName,
+					Key:  GetWebhookSecretKeyForComponent(getComponent("https://bitbucket.org/user/test-component-repository")),
+				},
+			},
+		},
+		{
+			name:    "should fail to create PaC repository with invalid git provider URL",
+			repoUrl: "https://gitlab.com/user/test-component-repository",
+			annotations: map[string]string{
+				GitProviderURLAnnotationName: "gitlab.example.com",
+			},
+			pacConfig: map[string][]byte{
+				"gitlab.token": []byte("glpat-token"),
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getComponent(tt.repoUrl)
+			component.Annotations = tt.annotations
+			component.Spec.Source.GitSource.GitProviderURL = tt.gitProviderURL
+			component.Spec.Source.GitSource.GitProviderUser = tt.gitProviderUser
+
+			pacRepo, err := GeneratePACRepository(component, tt.pacConfig)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, but got PaC repository: %#v", pacRepo)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to generate PaC repository object. Cause: %v", err)
+			}
+
+			if pacRepo.Name != component.Name {
+				t.Errorf("Generated PaC repository must have the same name as corresponding component")
+			}
+			if pacRepo.Namespace != component.Namespace {
+				t.Errorf("Generated PaC repository must have the same namespace as corresponding component")
+			}
+			if len(pacRepo.Annotations) == 0 {
+				t.Errorf("Generated PaC repository must have annotations")
+			}
+			if pacRepo.Annotations["build.appstudio.openshift.io/component"] != component.Name {
+				t.Errorf("Generated PaC repository must have component annotation")
+			}
+
+			if pacRepo.Spec.URL != tt.repoUrl {
 				t.Errorf("Wrong git repository URL in PaC repository: %s, want %s", pacRepo.Spec.URL, tt.repoUrl)
 			}
 			if !reflect.DeepEqual(pacRepo.Spec.GitProvider, tt.expectedGitProviderConfig) {
@@ -1178,33 +1699,171 @@ func TestGeneratePACRepository(t *testing.T) {
 	}
 }
 
-func TestGetProviderTokenKey(t *testing.T) {
+func TestGetProviderCredentialKey(t *testing.T) {
 	tests := []struct {
-		name     string
-		provider string
-		want     string
+		name       string
+		provider   string
+		host       string
+		credential string
+		config     map[string][]byte
+		want       string
 	}{
 		{
-			name:     "check github key",
+			name:       "should return provider token key",
+			provider:   "gitlab",
+			host:       "gitlab.com",
+			credential: "token",
+			config: map[string][]byte{
+				"gitlab.token": []byte("glpat-token"),
+			},
+			want: "gitlab.token",
+		},
+		{
+			name:       "should prefer host token key",
+			provider:   "gitlab",
+			host:       "gitlab.example.com",
+			credential: "token",
+			config: map[string][]byte{
+				"gitlab.token":                    []byte("glpat-token"),
+				"gitlab.gitlab.example.com.token": []byte("glpat-example-token"),
+			},
+			want: "gitlab.gitlab.example.com.token",
+		},
+		{
+			name:       "should return provider user key if the secret has no host user",
+			provider:   "bitbucket",
+			host:       "bitbucket.example.com",
+			credential: "user",
+			config: map[string][]byte{
+				"bitbucket.bitbucket.example.com.token": []byte("personal-token"),
+			},
+			want: "bitbucket.user",
+		},
+		{
+			name:       "should return provider token key without host",
+			provider:   "github",
+			credential: "token",
+			config:     map[string][]byte{},
+			want:       "github.token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetProviderCredentialKey(tt.provider, tt.host, tt.credential, tt.config); got != tt.want {
+				t.Errorf("Wrong git provider credential key: %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetGitProviderURL(t *testing.T) {
+	tests := []struct {
+		name           string
+		repoUrl        string
+		provider       string
+		providerURL    string
+		gitProviderURL string
+		want           string
+		expectError    bool
+	}{
+		{
+			name:     "should use default GitHub API",
+			repoUrl:  "https://github.com/user/test-component-repository",
 			provider: "github",
-			want:     "github.token",
+			want:     "",
 		},
 		{
-			name:     "check gitlab key",
+			name:     "should derive GitHub Enterprise API URL",
+			repoUrl:  "https://github.example.com/user/test-component-repository",
+			provider: "github",
+			want:     "https://github.example.com/api/v3",
+		},
+		{
+			name:     "should derive GitLab URL",
+			repoUrl:  "https://gitlab.com/user/test-component-repository",
 			provider: "gitlab",
-			want:     "gitlab.token",
+			want:     "https://gitlab.com",
 		},
 		{
-			name:     "check bitbucket key",
+			name:     "should derive self-hosted GitLab URL with port",
+			repoUrl:  "http://gitlab.example.com:8080/user/test-component-repository",
+			provider: "gitlab",
+			want:     "http://gitlab.example.com:8080",
+		},
+		{
+			name:     "should use default Bitbucket Cloud API",
+			repoUrl:  "https://bitbucket.org/user/test-component-repository",
+			provider: "bitbucket",
+			want:     "",
+		},
+		{
+			name:     "should derive Bitbucket Server API URL",
+			repoUrl:  "https://bitbucket.example.com/scm/project/test-component-repository.git",
 			provider: "bitbucket",
-			want:     "bitbucket.token",
+			want:     "https://bitbucket.example.com/rest",
+		},
+		{
+			name:        "should use git-provider-url annotation",
+			repoUrl:     "https://bitbucket.example.com/scm/project/test-component-repository.git",
+			provider:    "bitbucket",
+			providerURL: "https://bitbucket-api.example.com/bitbucket/rest",
+			want:        "https://bitbucket-api.example.com/bitbucket/rest",
+		},
+		{
+			name:           "should prefer the gitProviderUrl field to the git-provider-url annotation",
+			repoUrl:        "https://bitbucket.example.com/scm/project/test-component-repository.git",
+			provider:       "bitbucket",
+			providerURL:    "https://bitbucket-api.example.com/bitbucket/rest",
+			gitProviderURL: "https://bitbucket-field.example.com/rest",
+			want:           "https://bitbucket-field.example.com/rest",
+		},
+		{
+			name:           "should fail on relative gitProviderUrl field",
+			repoUrl:        "https://gitlab.example.com/user/test-component-repository",
+			provider:       "gitlab",
+			gitProviderURL: "/api",
+			expectError:    true,
+		},
+		{
+			name:        "should fail on relative git-provider-url annotation",
+			repoUrl:     "https://gitlab.example.com/user/test-component-repository",
+			provider:    "gitlab",
+			providerURL: "/api",
+			expectError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := GetProviderTokenKey(tt.provider); got != tt.want {
-				t.Errorf("Wrong git provider access token key: %s, want %s", got, tt.want)
+			component := appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testcomponent",
+					Namespace: "workspace-name",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL:            tt.repoUrl,
+								GitProviderURL: tt.gitProviderURL,
+							},
+						},
+					},
+				},
+			}
+			if tt.providerURL != "" {
+				component.Annotations = map[string]string{
+					GitProviderURLAnnotationName: tt.providerURL,
+				}
+			}
+
+			got, err := GetGitProviderURL(component, tt.provider)
+			if tt.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, but got: %v", tt.expectError, err)
+			}
+			if got != tt.want {
+				t.Errorf("Wrong git provider URL: %s, want %s", got, tt.want)
 			}
 		})
 	}
@@ -1417,3 +2076,336 @@ func TestIsPaCApplicationConfigured(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateEventListener(t *testing.T) {
+	triggerTemplate := triggersapi.TriggerTemplate{ObjectMeta: metav1.ObjectMeta{Name: "testcomponent"}}
+
+	tests := []struct {
+		name            string
+		url             string
+		revision        string
+		context         string
+		gitopsConfig    gitopsprepare.GitopsConfig
+		wantProvider    string
+		wantEventTypes  string
+		wantFilter      string
+		wantBinding     string
+		wantBindingKind triggersapi.TriggerBindingKind
+		wantUnsigned    bool
+	}{
+		{
+			name:            "GitHub push events to the default branch",
+			url:             "https://github.com/user/git-repo.git",
+			wantProvider:    "github",
+			wantEventTypes:  `["push"]`,
+			wantFilter:      `"header.match(\"X-GitHub-Event\", \"push\") && body.ref == 'refs/heads/' + body.repository.default_branch"`,
+			wantBinding:     "github-push",
+			wantBindingKind: triggersapi.ClusterTriggerBindingKind,
+		},
+		{
+			name:            "GitLab push events to the component revision",
+			url:             "https://gitlab.com/user/git-repo.git",
+			revision:        "release",
+			wantProvider:    "gitlab",
+			wantEventTypes:  `["Push Hook"]`,
+			wantFilter:      `"header.match(\"X-Gitlab-Event\", \"Push Hook\") && body.ref == \"refs/heads/release\""`,
+			wantBinding:     "gitlab-push",
+			wantBindingKind: triggersapi.ClusterTriggerBindingKind,
+		},
+		{
+			name:            "Bitbucket push events parsed with a generated binding",
+			url:             "https://bitbucket.org/user/git-repo.git",
+			gitopsConfig:    gitopsprepare.GitopsConfig{MissingClusterTriggerBindings: map[string]bool{"bitbucket-push": true}},
+			wantProvider:    "bitbucket",
+			wantEventTypes:  `["repo:push"]`,
+			wantFilter:      `"header.match(\"X-Event-Key\", \"repo:push\")"`,
+			wantBinding:     "testcomponent-bitbucket-push",
+			wantBindingKind: triggersapi.NamespacedTriggerBindingKind,
+			wantUnsigned:    true,
+		},
+		{
+			name:            "GitHub push events changing the component context",
+			url:             "https://github.com/user/git-repo.git",
+			context:         "./backend",
+			wantProvider:    "github",
+			wantEventTypes:  `["push"]`,
+			wantFilter:      `"header.match(\"X-GitHub-Event\", \"push\") && body.ref == 'refs/heads/' + body.repository.default_branch && body.commits.exists(c, c.added.exists(f, f == \"backend\" || f.startsWith(\"backend/\")) || c.modified.exists(f, f == \"backend\" || f.startsWith(\"backend/\")) || c.removed.exists(f, f == \"backend\" || f.startsWith(\"backend/\")))"`,
+			wantBinding:     "github-push",
+			wantBindingKind: triggersapi.ClusterTriggerBindingKind,
+		},
+		{
+			name:            "Unknown git providers are expected to send GitHub events",
+			url:             "https://host/git-repo.git",
+			wantProvider:    "github",
+			wantEventTypes:  `["push"]`,
+			wantFilter:      `"header.match(\"X-GitHub-Event\", \"push\") && body.ref == 'refs/heads/' + body.repository.default_branch"`,
+			wantBinding:     "github-push",
+			wantBindingKind: triggersapi.ClusterTriggerBindingKind,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testcomponent",
+					Namespace: "workspace-name",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL:      tt.url,
+								Revision: tt.revision,
+								Context:  tt.context,
+							},
+						},
+					},
+				},
+			}
+
+			eventListener, err := GenerateEventListener(component, triggerTemplate, tt.gitopsConfig)
+			testutils.AssertNoError(t, err)
+
+			bindings := eventListener.Spec.Triggers[0].Bindings
+			assert.Equal(t, tt.wantBinding, bindings[0].Ref)
+			assert.Equal(t, tt.wantBindingKind, bindings[0].Kind)
+
+			interceptors := eventListener.Spec.Triggers[0].Interceptors
+			if len(interceptors) != 2 {
+				t.Fatalf("GenerateEventListener() generated %d interceptors, want 2", len(interceptors))
+			}
+			assert.Equal(t, tt.wantProvider, interceptors[0].Ref.Name)
+			providerParams := interceptors[0].Params
+			if !tt.wantUnsigned {
+				assert.Equal(t, "secretRef", providerParams[0].Name)
+				assert.JSONEq(t, `{"secretName": "testcomponent-webhook-secret", "secretKey": "webhook.secret"}`, string(providerParams[0].Value.Raw))
+				providerParams = providerParams[1:]
+			}
+			if len(providerParams) != 1 {
+				t.Fatalf("GenerateEventListener() generated the %s interceptor parameters %v", tt.wantProvider, interceptors[0].Params)
+			}
+			assert.Equal(t, "eventTypes", providerParams[0].Name)
+			assert.JSONEq(t, tt.wantEventTypes, string(providerParams[0].Value.Raw))
+			assert.Equal(t, "cel", interceptors[1].Ref.Name)
+			assert.Equal(t, "filter", interceptors[1].Params[0].Name)
+			assert.JSONEq(t, tt.wantFilter, string(interceptors[1].Params[0].Value.Raw))
+		})
+	}
+}
+
+func TestGenerateTriggerBinding(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		wantName     string
+		wantRevision string
+	}{
+		{
+			name:         "GitHub push events",
+			url:          "https://github.com/user/git-repo.git",
+			wantName:     "testcomponent-github-push",
+			wantRevision: "$(body.head_commit.id)",
+		},
+		{
+			name:         "GitLab push events",
+			url:          "https://gitlab.com/user/git-repo.git",
+			wantName:     "testcomponent-gitlab-push",
+			wantRevision: "$(body.checkout_sha)",
+		},
+		{
+			name:         "Bitbucket push events",
+			url:          "https://bitbucket.org/user/git-repo.git",
+			wantName:     "testcomponent-bitbucket-push",
+			wantRevision: "$(body.push.changes[0].new.target.hash)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testcomponent",
+					Namespace: "workspace-name",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: tt.url,
+							},
+						},
+					},
+				},
+			}
+
+			triggerBinding := GenerateTriggerBinding(component)
+			assert.Equal(t, tt.wantName, triggerBinding.Name)
+			assert.Equal(t, []triggersapi.Param{{Name: "git-revision", Value: tt.wantRevision}}, triggerBinding.Spec.Params)
+		})
+	}
+}
+
+func TestGenerateEventListenerWithPullRequestBuilds(t *testing.T) {
+	triggerTemplate := triggersapi.TriggerTemplate{ObjectMeta: metav1.ObjectMeta{Name: "testcomponent"}}
+
+	tests := []struct {
+		name           string
+		url            string
+		revision       string
+		wantEventTypes string
+		wantFilter     string
+	}{
+		{
+			name:           "GitHub pull requests against the default branch",
+			url:            "https://github.com/user/git-repo.git",
+			wantEventTypes: `["pull_request"]`,
+			wantFilter:     `"(header.match(\"X-GitHub-Event\", \"pull_request\")) && body.action in ['opened', 'synchronize', 'reopened'] && body.pull_request.base.ref == body.repository.default_branch"`,
+		},
+		{
+			name:           "GitLab merge requests against the component revision",
+			url:            "https://gitlab.com/user/git-repo.git",
+			revision:       "release",
+			wantEventTypes: `["Merge Request Hook"]`,
+			wantFilter:     `"(header.match(\"X-Gitlab-Event\", \"Merge Request Hook\")) && body.object_attributes.action in ['open', 'update', 'reopen'] && body.object_attributes.target_branch == \"release\""`,
+		},
+		{
+			name:           "Bitbucket pull requests",
+			url:            "https://bitbucket.org/user/git-repo.git",
+			wantEventTypes: `["pullrequest:created", "pullrequest:updated"]`,
+			wantFilter:     `"(header.match(\"X-Event-Key\", \"pullrequest:created\") || header.match(\"X-Event-Key\", \"pullrequest:updated\"))"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "testcomponent",
+					Namespace:   "workspace-name",
+					Annotations: map[string]string{PullRequestBuildAnnotation: "1"},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL:      tt.url,
+								Revision: tt.revision,
+							},
+						},
+					},
+				},
+			}
+
+			eventListener, err := GenerateEventListener(component, triggerTemplate, gitopsprepare.GitopsConfig{})
+			testutils.AssertNoError(t, err)
+			if len(eventListener.Spec.Triggers) != 2 {
+				t.Fatalf("GenerateEventListener() generated %d triggers, want 2", len(eventListener.Spec.Triggers))
+			}
+
+			trigger := eventListener.Spec.Triggers[1]
+			assert.Equal(t, "testcomponent-pull-request", trigger.Bindings[0].Ref)
+			assert.Equal(t, triggersapi.NamespacedTriggerBindingKind, trigger.Bindings[0].Kind)
+			assert.Equal(t, "testcomponent-pull-request", *trigger.Template.Ref)
+			providerParams := trigger.Interceptors[0].Params
+			assert.Equal(t, "eventTypes", providerParams[len(providerParams)-1].Name)
+			assert.JSONEq(t, tt.wantEventTypes, string(providerParams[len(providerParams)-1].Value.Raw))
+			assert.JSONEq(t, tt.wantFilter, string(trigger.Interceptors[1].Params[0].Value.Raw))
+		})
+	}
+}
+
+func TestGetBuildTriggerPaths(t *testing.T) {
+	tests := []struct {
+		name              string
+		context           string
+		buildTriggerPaths []string
+		want              []string
+	}{
+		{
+			name: "No context",
+			want: nil,
+		},
+		{
+			name:    "Repository root context",
+			context: "./",
+			want:    nil,
+		},
+		{
+			name:              "Build trigger paths without a context",
+			buildTriggerPaths: []string{"common", "./go.mod"},
+			want:              []string{"common", "go.mod"},
+		},
+		{
+			name:              "Build trigger paths with the repository root context",
+			context:           "./",
+			buildTriggerPaths: []string{"common"},
+			want:              []string{"common"},
+		},
+		{
+			name:              "Build trigger paths including the repository root",
+			buildTriggerPaths: []string{"common", "/"},
+			want:              nil,
+		},
+		{
+			name:              "Context and build trigger paths",
+			context:           "./services/backend/",
+			buildTriggerPaths: []string{"/common", "go.mod"},
+			want:              []string{"services/backend", "common", "go.mod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := appstudiov1alpha1.Component{
+				Spec: appstudiov1alpha1.ComponentSpec{
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL:               "https://github.com/user/git-repo.git",
+								Context:           tt.context,
+								BuildTriggerPaths: tt.buildTriggerPaths,
+							},
+						},
+					},
+				},
+			}
+			assert.Equal(t, tt.want, getBuildTriggerPaths(component))
+		})
+	}
+}
+
+func TestGetChangedPathsFilter(t *testing.T) {
+	tests := []struct {
+		name         string
+		commitsField string
+		paths        []string
+		want         string
+	}{
+		{
+			name:         "No paths",
+			commitsField: "body.commits",
+			want:         "",
+		},
+		{
+			name:  "Events without the changed files",
+			paths: []string{"backend"},
+			want:  "",
+		},
+		{
+			name:         "Several paths",
+			commitsField: "body.commits",
+			paths:        []string{"backend", "go.mod"},
+			want: `body.commits.exists(c, ` +
+				`c.added.exists(f, f == "backend" || f.startsWith("backend/") || f == "go.mod" || f.startsWith("go.mod/")) || ` +
+				`c.modified.exists(f, f == "backend" || f.startsWith("backend/") || f == "go.mod" || f.startsWith("go.mod/")) || ` +
+				`c.removed.exists(f, f == "backend" || f.startsWith("backend/") || f == "go.mod" || f.startsWith("go.mod/")))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getChangedPathsFilter(tt.commitsField, tt.paths))
+		})
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"path"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	gitopsprepare "github.com/redhat-appstudio/application-service/gitops/prepare"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// PaCPipelineRunsDir is the directory of the source repository Pipelines as Code reads the PipelineRuns from
+	PaCPipelineRunsDir = ".tekton"
+
+	pacOnEventAnnotation        = "pipelinesascode.tekton.dev/on-event"
+	pacOnTargetBranchAnnotation = "pipelinesascode.tekton.dev/on-target-branch"
+	pacMaxKeepRunsAnnotation    = "pipelinesascode.tekton.dev/max-keep-runs"
+
+	// Dynamic variables Pipelines as Code replaces in the PipelineRuns with the values of the event
+	pacRevisionVariable          = "{{revision}}"
+	pacPullRequestNumberVariable = "{{pull_request_number}}"
+
+	pacMaxKeepRuns = "3"
+
+	pacOnboardingBranchPrefix = "appstudio-"
+)
+
+// GetPaCOnboardingBranch returns the branch of the source repository the Pipelines as Code PipelineRuns of the component
+// are pushed to before being merged. The branch name is stable, so that later changes update the already opened pull request.
+func GetPaCOnboardingBranch(component appstudiov1alpha1.Component) string {
+	return pacOnboardingBranchPrefix + component.Name
+}
+
+// GeneratePaCPipelineRunFiles renders the Pipelines as Code PipelineRuns building the component on push and pull request
+// events to the target branch, indexed by their path in the source repository
+func GeneratePaCPipelineRunFiles(component appstudiov1alpha1.Component, gitopsConfig gitopsprepare.GitopsConfig, targetBranch string) (map[string][]byte, error) {
+	pushPipelineRun, pullRequestPipelineRun, err := GeneratePaCPipelineRuns(component, gitopsConfig, targetBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	for _, pipelineRun := range []tektonapi.PipelineRun{pushPipelineRun, pullRequestPipelineRun} {
+		content, err := yaml.Marshal(pipelineRun)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Join(PaCPipelineRunsDir, pipelineRun.Name+".yaml")] = content
+	}
+	return files, nil
+}
+
+// GeneratePaCPipelineRuns generates the Pipelines as Code PipelineRuns building the component on push and pull request
+// events to the target branch. The revision of the component is used as target branch if it is set.
+func GeneratePaCPipelineRuns(component appstudiov1alpha1.Component, gitopsConfig gitopsprepare.GitopsConfig, targetBranch string) (tektonapi.PipelineRun, tektonapi.PipelineRun, error) {
+	if revision := component.Spec.Source.GitSource.Revision; revision != "" {
+		targetBranch = revision
+	}
+
+	// Pipelines as Code builds are always tagged with the revision, only the additional tags of the image tagging apply
+	imageTagging := appstudiov1alpha1.ImageTagging{AdditionalTags: GetImageTagging(component, gitopsConfig).AdditionalTags}
+	pushParams, err := getParamsForPaCBuild(component, imageTagging, pacRevisionVariable, "latest-")
+	if err != nil {
+		return tektonapi.PipelineRun{}, tektonapi.PipelineRun{}, err
+	}
+	pushPipelineRun := generatePaCPipelineRun(component, component.Name+"-on-push", BuildPushEventType, targetBranch,
+		DetermineBuildExecution(component, pushParams, pacRevisionVariable, gitopsConfig))
+
+	pullRequestParams, err := getParamsForPaCBuild(component, appstudiov1alpha1.ImageTagging{}, "pr-"+pacPullRequestNumberVariable, "")
+	if err != nil {
+		return tektonapi.PipelineRun{}, tektonapi.PipelineRun{}, err
+	}
+	pullRequestPipelineRun := generatePaCPipelineRun(component, component.Name+"-on-pull-request", BuildPullRequestEventType, targetBranch,
+		DetermineBuildExecution(component, pullRequestParams, "pr-"+pacPullRequestNumberVariable+"/"+pacRevisionVariable, gitopsConfig))
+
+	return pushPipelineRun, pullRequestPipelineRun, nil
+}
+
+// generatePaCPipelineRun generates a PipelineRun with the given spec, run by Pipelines as Code on the given event to the target branch
+func generatePaCPipelineRun(component appstudiov1alpha1.Component, name string, event string, targetBranch string, pipelineRunSpec tektonapi.PipelineRunSpec) tektonapi.PipelineRun {
+	labels := getBuildCommonLabelsForComponent(&component)
+	labels[BuildEventTypeLabel] = event
+	return tektonapi.PipelineRun{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PipelineRun",
+			APIVersion: "tekton.dev/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: component.Namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				pacOnEventAnnotation:        "[" + event + "]",
+				pacOnTargetBranchAnnotation: "[" + targetBranch + "]",
+				pacMaxKeepRunsAnnotation:    pacMaxKeepRuns,
+			},
+		},
+		Spec: pipelineRunSpec,
+	}
+}
+
+// getParamsForPaCBuild returns the 'input' parameters for the Pipelines as Code PipelineRuns building the revision of the
+// event into the image of the component, tagged with the given suffix and the additional tags of the image tagging
+func getParamsForPaCBuild(component appstudiov1alpha1.Component, imageTagging appstudiov1alpha1.ImageTagging, outputImageSuffix string, defaultTagPrefix string) ([]tektonapi.Param, error) {
+	params, err := getParamsForComponentBuild(component, true, imageTagging)
+	if err != nil {
+		return []tektonapi.Param{}, err
+	}
+
+	revisionParam := tektonapi.Param{
+		Name: "revision",
+		Value: tektonapi.ArrayOrString{
+			Type:      tektonapi.ParamTypeString,
+			StringVal: pacRevisionVariable,
+		},
+	}
+	hasRevision := false
+	for i, param := range params {
+		switch param.Name {
+		case "output-image":
+			params[i].Value.StringVal = appendImageTagSuffix(param.Value.StringVal, outputImageSuffix, defaultTagPrefix)
+		case "revision":
+			params[i] = revisionParam
+			hasRevision = true
+		}
+	}
+	if !hasRevision {
+		params = append(params, revisionParam)
+	}
+	return params, nil
+}
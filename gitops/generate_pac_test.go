@@ -0,0 +1,173 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"reflect"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	gitopsprepare "github.com/redhat-appstudio/application-service/gitops/prepare"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGeneratePaCPipelineRuns(t *testing.T) {
+	getComponent := func(containerImage, revision string) appstudiov1alpha1.Component {
+		return appstudiov1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "testcomponent",
+				Namespace: "workspace-name",
+			},
+			Spec: appstudiov1alpha1.ComponentSpec{
+				ContainerImage: containerImage,
+				Source: appstudiov1alpha1.ComponentSource{
+					ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+						GitSource: &appstudiov1alpha1.GitSource{
+							URL:      "https://github.com/user/test-component-repository",
+							Revision: revision,
+						},
+					},
+				},
+			},
+		}
+	}
+	getParams := func(outputImage string) []tektonapi.Param {
+		return []tektonapi.Param{
+			{
+				Name:  "git-url",
+				Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: "https://github.com/user/test-component-repository"},
+			},
+			{
+				Name:  "output-image",
+				Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: outputImage},
+			},
+			{
+				Name:  "revision",
+				Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: "{{revision}}"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name                   string
+		component              appstudiov1alpha1.Component
+		targetBranch           string
+		wantTargetBranch       string
+		wantPushParams         []tektonapi.Param
+		wantPullRequestParams  []tektonapi.Param
+		wantPushSubPath        string
+		wantPullRequestSubPath string
+	}{
+		{
+			name:                   "should build the default branch into tagged images",
+			component:              getComponent("quay.io/user/image", ""),
+			targetBranch:           "main",
+			wantTargetBranch:       "[main]",
+			wantPushParams:         getParams("quay.io/user/image:latest-{{revision}}"),
+			wantPullRequestParams:  getParams("quay.io/user/image:pr-{{pull_request_number}}"),
+			wantPushSubPath:        "testcomponent/{{revision}}",
+			wantPullRequestSubPath: "testcomponent/pr-{{pull_request_number}}/{{revision}}",
+		},
+		{
+			name:                   "should build the revision of the component",
+			component:              getComponent("quay.io/user/image:tag", "devel"),
+			targetBranch:           "main",
+			wantTargetBranch:       "[devel]",
+			wantPushParams:         getParams("quay.io/user/image:tag-{{revision}}"),
+			wantPullRequestParams:  getParams("quay.io/user/image:tag-pr-{{pull_request_number}}"),
+			wantPushSubPath:        "testcomponent/{{revision}}",
+			wantPullRequestSubPath: "testcomponent/pr-{{pull_request_number}}/{{revision}}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			push, pullRequest, err := GeneratePaCPipelineRuns(tt.component, gitopsprepare.GitopsConfig{}, tt.targetBranch)
+			if err != nil {
+				t.Fatalf("Failed to generate the Pipelines as Code PipelineRuns. Cause: %v", err)
+			}
+
+			if push.Name != "testcomponent-on-push" || pullRequest.Name != "testcomponent-on-pull-request" {
+				t.Errorf("Wrong PipelineRun names: %s and %s", push.Name, pullRequest.Name)
+			}
+			if push.Annotations[pacOnEventAnnotation] != "[push]" || pullRequest.Annotations[pacOnEventAnnotation] != "[pull_request]" {
+				t.Errorf("Wrong PipelineRun events: %s and %s", push.Annotations[pacOnEventAnnotation], pullRequest.Annotations[pacOnEventAnnotation])
+			}
+			if push.Labels[BuildEventTypeLabel] != BuildPushEventType || pullRequest.Labels[BuildEventTypeLabel] != BuildPullRequestEventType {
+				t.Errorf("Wrong PipelineRun event type labels: %s and %s", push.Labels[BuildEventTypeLabel], pullRequest.Labels[BuildEventTypeLabel])
+			}
+			for _, pipelineRun := range []tektonapi.PipelineRun{push, pullRequest} {
+				if pipelineRun.Annotations[pacOnTargetBranchAnnotation] != tt.wantTargetBranch {
+					t.Errorf("Wrong target branch of %s: %s, want %s", pipelineRun.Name, pipelineRun.Annotations[pacOnTargetBranchAnnotation], tt.wantTargetBranch)
+				}
+				if pipelineRun.Labels[BuildComponentLabel] != "testcomponent" {
+					t.Errorf("PipelineRun %s must have the component label", pipelineRun.Name)
+				}
+			}
+			if !reflect.DeepEqual(push.Spec.Params, tt.wantPushParams) {
+				t.Errorf("Wrong push PipelineRun params: %#v, want %#v", push.Spec.Params, tt.wantPushParams)
+			}
+			if !reflect.DeepEqual(pullRequest.Spec.Params, tt.wantPullRequestParams) {
+				t.Errorf("Wrong pull request PipelineRun params: %#v, want %#v", pullRequest.Spec.Params, tt.wantPullRequestParams)
+			}
+			if push.Spec.Workspaces[0].SubPath != tt.wantPushSubPath {
+				t.Errorf("Wrong push PipelineRun workspace sub path: %s, want %s", push.Spec.Workspaces[0].SubPath, tt.wantPushSubPath)
+			}
+			if pullRequest.Spec.Workspaces[0].SubPath != tt.wantPullRequestSubPath {
+				t.Errorf("Wrong pull request PipelineRun workspace sub path: %s, want %s", pullRequest.Spec.Workspaces[0].SubPath, tt.wantPullRequestSubPath)
+			}
+		})
+	}
+}
+
+func TestGeneratePaCPipelineRunFiles(t *testing.T) {
+	component := appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testcomponent",
+			Namespace: "workspace-name",
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			ContainerImage: "quay.io/user/image",
+			Source: appstudiov1alpha1.ComponentSource{
+				ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+					GitSource: &appstudiov1alpha1.GitSource{
+						URL: "https://github.com/user/test-component-repository",
+					},
+				},
+			},
+		},
+	}
+
+	files, err := GeneratePaCPipelineRunFiles(component, gitopsprepare.GitopsConfig{}, "main")
+	if err != nil {
+		t.Fatalf("Failed to generate the Pipelines as Code PipelineRun files. Cause: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("Expected 2 files, got %d", len(files))
+	}
+	for _, filePath := range []string{".tekton/testcomponent-on-push.yaml", ".tekton/testcomponent-on-pull-request.yaml"} {
+		pipelineRun := tektonapi.PipelineRun{}
+		if err := yaml.Unmarshal(files[filePath], &pipelineRun); err != nil {
+			t.Errorf("Failed to parse the PipelineRun file %s. Cause: %v", filePath, err)
+		}
+		if pipelineRun.Kind != "PipelineRun" || pipelineRun.Annotations[pacMaxKeepRunsAnnotation] != pacMaxKeepRuns {
+			t.Errorf("Wrong PipelineRun in file %s: %#v", filePath, pipelineRun)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	gitopsprepare "github.com/redhat-appstudio/application-service/gitops/prepare"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+const (
+	// imageTagParam is the TriggerTemplate parameter holding the tag computed from the push event by the image tagging strategy
+	imageTagParam = "image-tag"
+	// imageTagExtension is the key of the CEL interceptor extension holding the computed tag
+	imageTagExtension = "image_tag"
+	// additionalTagsParam is the build pipeline parameter listing the additional tags of the built image
+	additionalTagsParam = "additional-tags"
+
+	// semverTagPattern matches the git tags built by the git-tag strategy
+	semverTagPattern = "^v?[0-9]+[.][0-9]+[.][0-9]+"
+
+	// branchOnlyTagCharacters are the characters allowed in git branch names but not in image tags
+	branchOnlyTagCharacters = "/!\"#$%&'()+,;<=>@]`{|}"
+	// maxImageTagLength is the maximum length of an image tag
+	maxImageTagLength = 128
+)
+
+var (
+	// imageReferenceRegexp matches a container image reference with an optional tag, following the grammar of
+	// https://github.com/distribution/distribution/blob/main/reference/reference.go
+	imageReferenceRegexp = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?/)?` +
+		`[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*)*` +
+		`(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?$`)
+
+	// imageTagVariableRegexp matches the TriggerTemplate parameters and Pipelines as Code variables in an image reference
+	imageTagVariableRegexp = regexp.MustCompile(`\$\(tt\.params\.[a-z-]+\)|\{\{[a-z_]+\}\}`)
+
+	// invalidImageTagCharactersRegexp matches the characters that are not allowed in an image tag
+	invalidImageTagCharactersRegexp = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+)
+
+// GetImageTagging returns the tagging of the images built for the component: its own image tagging, or the default one of
+// its Application, or the revision strategy
+func GetImageTagging(component appstudiov1alpha1.Component, gitopsConfig gitopsprepare.GitopsConfig) appstudiov1alpha1.ImageTagging {
+	imageTagging := appstudiov1alpha1.ImageTagging{}
+	if component.Spec.ImageTagging != nil {
+		imageTagging = *component.Spec.ImageTagging
+	} else if gitopsConfig.ApplicationImageTagging != nil {
+		imageTagging = *gitopsConfig.ApplicationImageTagging
+	}
+	if imageTagging.Strategy == "" {
+		imageTagging.Strategy = appstudiov1alpha1.RevisionImageTagStrategy
+	}
+	return imageTagging
+}
+
+// isImageTagComputed returns true if the tag of the images is computed from the push event by the CEL interceptor,
+// instead of being the pushed revision extracted by the TriggerBinding
+func isImageTagComputed(strategy appstudiov1alpha1.ImageTagStrategy) bool {
+	return strategy != "" && strategy != appstudiov1alpha1.RevisionImageTagStrategy
+}
+
+// getImageTagExpression returns the CEL expression computing the image tag of the given strategy from the push events of the provider
+func getImageTagExpression(strategy appstudiov1alpha1.ImageTagStrategy, provider buildTriggerProvider) string {
+	revision := strings.TrimSuffix(strings.TrimPrefix(provider.revisionField, "$("), ")")
+	switch strategy {
+	case appstudiov1alpha1.ShortRevisionImageTagStrategy:
+		return revision + ".truncate(7)"
+	case appstudiov1alpha1.BranchImageTagStrategy:
+		return getBranchImageTagExpression(provider.branchField)
+	case appstudiov1alpha1.GitTagImageTagStrategy:
+		return fmt.Sprintf("%s.startsWith('v') ? %s.substring(1) : %s", provider.tagField, provider.tagField, provider.tagField)
+	case appstudiov1alpha1.DateImageTagStrategy:
+		return fmt.Sprintf("%s.truncate(10).replace('-', '') + '-' + %s.truncate(7)", provider.revisionDateField, revision)
+	}
+	return revision
+}
+
+// getInitialBuildImageTag returns the tag of the image of the initial build of the component, which is not triggered by a
+// push event. Empty string is returned if the strategy needs the push event to compute the tag.
+func getInitialBuildImageTag(component appstudiov1alpha1.Component, strategy appstudiov1alpha1.ImageTagStrategy) string {
+	switch strategy {
+	case appstudiov1alpha1.BranchImageTagStrategy:
+		return getBranchImageTag(component.Spec.Source.GitSource.Revision)
+	case appstudiov1alpha1.DateImageTagStrategy:
+		return time.Now().Format("20060102")
+	}
+	return ""
+}
+
+// getBranchImageTagExpression returns the CEL expression of the image tag of the branch, replacing the characters of the
+// branch name that are not allowed in an image tag with dashes. The Tekton Triggers CEL environment has no regular
+// expression replacement, so only the ASCII characters allowed in git branch names are replaced.
+func getBranchImageTagExpression(branchField string) string {
+	expression := branchField
+	for _, character := range branchOnlyTagCharacters {
+		literal := string(character)
+		if character == '\'' {
+			literal = `\'`
+		}
+		expression += ".replace('" + literal + "', '-')"
+	}
+	return fmt.Sprintf("%s.truncate(%d)", expression, maxImageTagLength)
+}
+
+// getBranchImageTag returns the image tag of the branch, replacing the characters of the branch name that are not allowed
+// in an image tag with dashes
+func getBranchImageTag(branch string) string {
+	tag := invalidImageTagCharactersRegexp.ReplaceAllString(branch, "-")
+	if len(tag) > maxImageTagLength {
+		tag = tag[:maxImageTagLength]
+	}
+	return tag
+}
+
+// getAdditionalTagsParam returns the build pipeline parameter listing the additional tags of the built image
+func getAdditionalTagsParam(additionalTags []appstudiov1alpha1.ImageTag) tektonapi.Param {
+	tags := []string{}
+	for _, tag := range additionalTags {
+		tags = append(tags, string(tag))
+	}
+	return tektonapi.Param{
+		Name: additionalTagsParam,
+		Value: tektonapi.ArrayOrString{
+			Type:     tektonapi.ParamTypeArray,
+			ArrayVal: tags,
+		},
+	}
+}
+
+// validateOutputImage returns an error if the image is not a legal image reference once the variables in its tag are
+// replaced with their values
+func validateOutputImage(outputImage string) error {
+	if !imageReferenceRegexp.MatchString(imageTagVariableRegexp.ReplaceAllString(outputImage, "0")) {
+		return fmt.Errorf("invalid output image %s", outputImage)
+	}
+	return nil
+}
+
+// validateAdditionalTags returns an error if one of the additional tags is not a legal image tag, or if it could overwrite
+// the images of another namespace in the default repository
+func validateAdditionalTags(additionalTags []appstudiov1alpha1.ImageTag, outputImage string, namespace string) error {
+	for _, tag := range additionalTags {
+		if !imageReferenceRegexp.MatchString("image:" + string(tag)) {
+			return fmt.Errorf("invalid additional image tag %s", tag)
+		}
+		if strings.HasPrefix(outputImage, GetDefaultImageRepo()) && !strings.HasPrefix(string(tag), namespace+"-") {
+			return fmt.Errorf("invalid additional image tag %s of default repo %s and component namespace %s", tag, GetDefaultImageRepo(), namespace)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	gitopsprepare "github.com/redhat-appstudio/application-service/gitops/prepare"
+	"github.com/redhat-developer/gitops-generator/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersapi "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getImageTaggingComponent(url string, containerImage string, imageTagging *appstudiov1alpha1.ImageTagging) appstudiov1alpha1.Component {
+	return appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testcomponent",
+			Namespace: "workspace-name",
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			ContainerImage: containerImage,
+			ImageTagging:   imageTagging,
+			Source: appstudiov1alpha1.ComponentSource{
+				ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+					GitSource: &appstudiov1alpha1.GitSource{
+						URL: url,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGetImageTagging(t *testing.T) {
+	branchTagging := &appstudiov1alpha1.ImageTagging{Strategy: appstudiov1alpha1.BranchImageTagStrategy}
+	dateTagging := &appstudiov1alpha1.ImageTagging{Strategy: appstudiov1alpha1.DateImageTagStrategy, AdditionalTags: []appstudiov1alpha1.ImageTag{"latest"}}
+
+	tests := []struct {
+		name                 string
+		componentTagging     *appstudiov1alpha1.ImageTagging
+		applicationTagging   *appstudiov1alpha1.ImageTagging
+		wantImageTaggingSpec appstudiov1alpha1.ImageTagging
+	}{
+		{
+			name:                 "should default to the revision strategy",
+			wantImageTaggingSpec: appstudiov1alpha1.ImageTagging{Strategy: appstudiov1alpha1.RevisionImageTagStrategy},
+		},
+		{
+			name:                 "should use the default tagging of the application",
+			applicationTagging:   dateTagging,
+			wantImageTaggingSpec: *dateTagging,
+		},
+		{
+			name:                 "should prefer the tagging of the component",
+			componentTagging:     branchTagging,
+			applicationTagging:   dateTagging,
+			wantImageTaggingSpec: *branchTagging,
+		},
+		{
+			name:             "should default the strategy of the additional tags to the revision",
+			componentTagging: &appstudiov1alpha1.ImageTagging{AdditionalTags: []appstudiov1alpha1.ImageTag{"stable"}},
+			wantImageTaggingSpec: appstudiov1alpha1.ImageTagging{
+				Strategy:       appstudiov1alpha1.RevisionImageTagStrategy,
+				AdditionalTags: []appstudiov1alpha1.ImageTag{"stable"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getImageTaggingComponent("https://github.com/user/git-repo.git", "", tt.componentTagging)
+			got := GetImageTagging(component, gitopsprepare.GitopsConfig{ApplicationImageTagging: tt.applicationTagging})
+			if !reflect.DeepEqual(got, tt.wantImageTaggingSpec) {
+				t.Errorf("GetImageTagging() = %#v, want %#v", got, tt.wantImageTaggingSpec)
+			}
+		})
+	}
+}
+
+// branchTagExpression is the expression of the branch tag of the GitHub and GitLab push events
+const branchTagExpression = "body.ref.replace('refs/heads/', '').replace('/', '-').replace('!', '-').replace('\"', '-').replace('#', '-').replace('$', '-').replace('%', '-').replace('&', '-').replace('\\'', '-').replace('(', '-').replace(')', '-').replace('+', '-').replace(',', '-').replace(';', '-').replace('<', '-').replace('=', '-').replace('>', '-').replace('@', '-').replace(']', '-').replace('`', '-').replace('{', '-').replace('|', '-').replace('}', '-').truncate(128)"
+
+func TestGetImageTagExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		strategy appstudiov1alpha1.ImageTagStrategy
+		want     string
+	}{
+		{
+			name:     "GitHub short revision",
+			url:      "https://github.com/user/git-repo.git",
+			strategy: appstudiov1alpha1.ShortRevisionImageTagStrategy,
+			want:     "body.head_commit.id.truncate(7)",
+		},
+		{
+			name:     "GitLab branch",
+			url:      "https://gitlab.com/user/git-repo.git",
+			strategy: appstudiov1alpha1.BranchImageTagStrategy,
+			want:     branchTagExpression,
+		},
+		{
+			name:     "GitHub git tag",
+			url:      "https://github.com/user/git-repo.git",
+			strategy: appstudiov1alpha1.GitTagImageTagStrategy,
+			want:     "body.ref.replace('refs/tags/', '').startsWith('v') ? body.ref.replace('refs/tags/', '').substring(1) : body.ref.replace('refs/tags/', '')",
+		},
+		{
+			name:     "Bitbucket date",
+			url:      "https://bitbucket.org/user/git-repo.git",
+			strategy: appstudiov1alpha1.DateImageTagStrategy,
+			want:     "body.push.changes[0].new.target.date.truncate(10).replace('-', '') + '-' + body.push.changes[0].new.target.hash.truncate(7)",
+		},
+		{
+			name:     "GitLab revision",
+			url:      "https://gitlab.com/user/git-repo.git",
+			strategy: appstudiov1alpha1.RevisionImageTagStrategy,
+			want:     "body.checkout_sha",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, provider := getBuildTriggerProvider(getImageTaggingComponent(tt.url, "", nil))
+			if got := getImageTagExpression(tt.strategy, provider); got != tt.want {
+				t.Errorf("getImageTagExpression() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBranchImageTag(t *testing.T) {
+	tests := []struct {
+		name   string
+		branch string
+		want   string
+	}{
+		{
+			name:   "branch with slashes",
+			branch: "feature/login",
+			want:   "feature-login",
+		},
+		{
+			name:   "branch with characters not allowed in tags",
+			branch: "fix/issue#12@v1+rc",
+			want:   "fix-issue-12-v1-rc",
+		},
+		{
+			name:   "long branch",
+			branch: strings.Repeat("a", 200),
+			want:   strings.Repeat("a", maxImageTagLength),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getBranchImageTag(tt.branch); got != tt.want {
+				t.Errorf("getBranchImageTag() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOutputImage(t *testing.T) {
+	tests := []struct {
+		name        string
+		outputImage string
+		wantErr     bool
+	}{
+		{
+			name:        "image with a revision tag",
+			outputImage: "quay.io/user/image:latest-$(tt.params.git-revision)",
+		},
+		{
+			name:        "image with a computed tag",
+			outputImage: "registry.example.com:5000/user/image:$(tt.params.image-tag)",
+		},
+		{
+			name:        "image with a Pipelines as Code tag",
+			outputImage: "quay.io/user/image:pr-{{pull_request_number}}",
+		},
+		{
+			name:        "image with a branch tag",
+			outputImage: "quay.io/user/image:feature-login",
+		},
+		{
+			name:        "image with an illegal tag",
+			outputImage: "quay.io/user/image:feature/login",
+			wantErr:     true,
+		},
+		{
+			name:        "image with an upper case repository",
+			outputImage: "quay.io/User/image:tag",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOutputImage(tt.outputImage)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateOutputImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetParamsForComponentBuildWithImageTagging(t *testing.T) {
+	tests := []struct {
+		name            string
+		containerImage  string
+		isInitialBuild  bool
+		imageTagging    appstudiov1alpha1.ImageTagging
+		wantOutputImage string
+		wantTags        []string
+		wantErr         bool
+	}{
+		{
+			name:            "webhook build tagged with the revision",
+			imageTagging:    appstudiov1alpha1.ImageTagging{Strategy: appstudiov1alpha1.RevisionImageTagStrategy},
+			wantOutputImage: "quay.io/user/image:latest-$(tt.params.git-revision)",
+		},
+		{
+			name:            "webhook build tagged with a computed tag and additional tags",
+			imageTagging:    appstudiov1alpha1.ImageTagging{Strategy: appstudiov1alpha1.GitTagImageTagStrategy, AdditionalTags: []appstudiov1alpha1.ImageTag{"latest", "stable"}},
+			wantOutputImage: "quay.io/user/image:$(tt.params.image-tag)",
+			wantTags:        []string{"latest", "stable"},
+		},
+		{
+			name:            "initial build not tagged by the short revision",
+			isInitialBuild:  true,
+			imageTagging:    appstudiov1alpha1.ImageTagging{Strategy: appstudiov1alpha1.ShortRevisionImageTagStrategy},
+			wantOutputImage: "quay.io/user/image",
+		},
+		{
+			name:         "illegal additional tag",
+			imageTagging: appstudiov1alpha1.ImageTagging{AdditionalTags: []appstudiov1alpha1.ImageTag{"-latest"}},
+			wantErr:      true,
+		},
+		{
+			name:            "additional tag of the default repo prefixed with the namespace",
+			containerImage:  DefaultImageRepo + ":workspace-name-testcomponent",
+			imageTagging:    appstudiov1alpha1.ImageTagging{AdditionalTags: []appstudiov1alpha1.ImageTag{"workspace-name-latest"}},
+			wantOutputImage: DefaultImageRepo + ":workspace-name-testcomponent-$(tt.params.git-revision)",
+			wantTags:        []string{"workspace-name-latest"},
+		},
+		{
+			name:           "additional tag of the default repo overwriting the images of other namespaces",
+			containerImage: DefaultImageRepo + ":workspace-name-testcomponent",
+			imageTagging:   appstudiov1alpha1.ImageTagging{AdditionalTags: []appstudiov1alpha1.ImageTag{"latest"}},
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containerImage := tt.containerImage
+			if containerImage == "" {
+				containerImage = "quay.io/user/image"
+			}
+			component := getImageTaggingComponent("https://github.com/user/git-repo.git", containerImage, nil)
+			params, err := getParamsForComponentBuild(component, tt.isInitialBuild, tt.imageTagging)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getParamsForComponentBuild() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var outputImage string
+			var tags []string
+			for _, param := range params {
+				switch param.Name {
+				case "output-image":
+					outputImage = param.Value.StringVal
+				case additionalTagsParam:
+					assert.Equal(t, tektonapi.ParamTypeArray, param.Value.Type)
+					tags = param.Value.ArrayVal
+				}
+			}
+			assert.Equal(t, tt.wantOutputImage, outputImage)
+			assert.Equal(t, tt.wantTags, tags)
+		})
+	}
+}
+
+func TestGenerateEventListenerWithImageTagging(t *testing.T) {
+	triggerTemplate := triggersapi.TriggerTemplate{ObjectMeta: metav1.ObjectMeta{Name: "testcomponent"}}
+
+	tests := []struct {
+		name           string
+		url            string
+		imageTagging   *appstudiov1alpha1.ImageTagging
+		wantEventTypes string
+		wantFilter     string
+		wantOverlays   string
+	}{
+		{
+			name:           "GitHub pushed semantic version tags",
+			url:            "https://github.com/user/git-repo.git",
+			imageTagging:   &appstudiov1alpha1.ImageTagging{Strategy: appstudiov1alpha1.GitTagImageTagStrategy},
+			wantEventTypes: `["push"]`,
+			wantFilter:     `"header.match(\"X-GitHub-Event\", \"push\") && body.ref.startsWith('refs/tags/') && body.ref.replace('refs/tags/', '').matches('^v?[0-9]+[.][0-9]+[.][0-9]+')"`,
+			wantOverlays:   `[{"key": "image_tag", "expression": "body.ref.replace('refs/tags/', '').startsWith('v') ? body.ref.replace('refs/tags/', '').substring(1) : body.ref.replace('refs/tags/', '')"}]`,
+		},
+		{
+			name:           "GitLab pushed tags",
+			url:            "https://gitlab.com/user/git-repo.git",
+			imageTagging:   &appstudiov1alpha1.ImageTagging{Strategy: appstudiov1alpha1.GitTagImageTagStrategy},
+			wantEventTypes: `["Tag Push Hook"]`,
+			wantFilter:     `"header.match(\"X-Gitlab-Event\", \"Tag Push Hook\") && body.ref.startsWith('refs/tags/') && body.ref.replace('refs/tags/', '').matches('^v?[0-9]+[.][0-9]+[.][0-9]+')"`,
+			wantOverlays:   `[{"key": "image_tag", "expression": "body.ref.replace('refs/tags/', '').startsWith('v') ? body.ref.replace('refs/tags/', '').substring(1) : body.ref.replace('refs/tags/', '')"}]`,
+		},
+		{
+			name:           "GitHub pushed commits tagged with the branch",
+			url:            "https://github.com/user/git-repo.git",
+			imageTagging:   &appstudiov1alpha1.ImageTagging{Strategy: appstudiov1alpha1.BranchImageTagStrategy},
+			wantEventTypes: `["push"]`,
+			wantFilter:     `"header.match(\"X-GitHub-Event\", \"push\") && body.ref == 'refs/heads/' + body.repository.default_branch"`,
+			wantOverlays:   `[{"key": "image_tag", "expression": ` + strconv.Quote(branchTagExpression) + `}]`,
+		},
+		{
+			name:           "GitHub pushed commits tagged with the revision",
+			url:            "https://github.com/user/git-repo.git",
+			wantEventTypes: `["push"]`,
+			wantFilter:     `"header.match(\"X-GitHub-Event\", \"push\") && body.ref == 'refs/heads/' + body.repository.default_branch"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getImageTaggingComponent(tt.url, "", tt.imageTagging)
+			eventListener, err := GenerateEventListener(component, triggerTemplate, gitopsprepare.GitopsConfig{})
+			testutils.AssertNoError(t, err)
+
+			interceptors := eventListener.Spec.Triggers[0].Interceptors
+			assert.JSONEq(t, tt.wantEventTypes, string(interceptors[0].Params[1].Value.Raw))
+			assert.JSONEq(t, tt.wantFilter, string(interceptors[1].Params[0].Value.Raw))
+
+			bindings := eventListener.Spec.Triggers[0].Bindings
+			if tt.wantOverlays == "" {
+				assert.Len(t, interceptors[1].Params, 1)
+				assert.Len(t, bindings, 1)
+				return
+			}
+			if len(interceptors[1].Params) != 2 {
+				t.Fatalf("GenerateEventListener() generated %d CEL interceptor params, want 2", len(interceptors[1].Params))
+			}
+			assert.Equal(t, "overlays", interceptors[1].Params[1].Name)
+			assert.JSONEq(t, tt.wantOverlays, string(interceptors[1].Params[1].Value.Raw))
+			if len(bindings) != 2 {
+				t.Fatalf("GenerateEventListener() generated %d bindings, want 2", len(bindings))
+			}
+			assert.Equal(t, imageTagParam, bindings[1].Name)
+			assert.Equal(t, "$(extensions.image_tag)", *bindings[1].Value)
+		})
+	}
+}
+
+func TestGenerateTriggerTemplateWithImageTagging(t *testing.T) {
+	imageTagging := &appstudiov1alpha1.ImageTagging{Strategy: appstudiov1alpha1.ShortRevisionImageTagStrategy}
+	component := getImageTaggingComponent("https://github.com/user/git-repo.git", "quay.io/user/image", imageTagging)
+
+	triggerTemplate, err := GenerateTriggerTemplate(component, gitopsprepare.GitopsConfig{})
+	testutils.AssertNoError(t, err)
+
+	paramNames := []string{}
+	for _, param := range triggerTemplate.Spec.Params {
+		paramNames = append(paramNames, param.Name)
+	}
+	assert.Equal(t, []string{"git-revision", imageTagParam}, paramNames)
+
+	var pipelineRun tektonapi.PipelineRun
+	testutils.AssertNoError(t, json.Unmarshal(triggerTemplate.Spec.ResourceTemplates[0].Raw, &pipelineRun))
+	for _, param := range pipelineRun.Spec.Params {
+		if param.Name == "output-image" {
+			assert.Equal(t, "quay.io/user/image:$(tt.params.image-tag)", param.Value.StringVal)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	routev1 "github.com/openshift/api/route/v1"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+	gitopsgen "github.com/redhat-developer/gitops-generator/pkg"
+	"github.com/redhat-developer/gitops-generator/pkg/yaml"
+	"github.com/spf13/afero"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	componentRouteFileName   = "route.yaml"
+	componentIngressFileName = "ingress.yaml"
+)
+
+// IngressHostData is the data available to the Ingress host templates
+type IngressHostData struct {
+	// Name is the name of the Ingress
+	Name      string
+	Namespace string
+	Component string
+}
+
+// RenderIngressHost renders the host of an Ingress from the host template.
+// An empty host is returned if the template is empty.
+func RenderIngressHost(hostTemplate string, data IngressHostData) (string, error) {
+	if hostTemplate == "" {
+		return "", nil
+	}
+	tmpl, err := template.New("host").Option("missingkey=error").Parse(hostTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the ingress host template: %v", err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render the ingress host template: %v", err)
+	}
+	return sb.String(), nil
+}
+
+// ValidateIngressHostTemplate returns an error if the host template is empty, invalid, or does not render a distinct host
+// for every Ingress name and namespace. The Ingresses share the hosts of the cluster, so a host rendered for several
+// Ingresses would route the traffic of all of them to a single service.
+func ValidateIngressHostTemplate(hostTemplate string) error {
+	if hostTemplate == "" {
+		return fmt.Errorf("a host template is required to generate distinct Ingress hosts")
+	}
+	data := IngressHostData{Name: "name", Namespace: "namespace", Component: "component"}
+	host, err := RenderIngressHost(hostTemplate, data)
+	if err != nil {
+		return err
+	}
+	for _, other := range []IngressHostData{
+		{Name: "other-name", Namespace: data.Namespace, Component: data.Component},
+		{Name: data.Name, Namespace: "other-namespace", Component: data.Component},
+	} {
+		otherHost, err := RenderIngressHost(hostTemplate, other)
+		if err != nil {
+			return err
+		}
+		if otherHost == host {
+			return fmt.Errorf("the host template %q must render a distinct host for every Ingress name and namespace", hostTemplate)
+		}
+	}
+	return nil
+}
+
+// generateIngress returns an Ingress routing all the traffic for host to the given port of the service.
+// If host is empty, the host is rendered from the host template of the Ingress configuration.
+func generateIngress(meta metav1.ObjectMeta, component appstudiov1alpha1.Component, host string, serviceName string, port int32, config prepare.IngressConfig) (networkingv1.Ingress, error) {
+	if host == "" {
+		var err error
+		host, err = RenderIngressHost(config.HostTemplate, IngressHostData{Name: meta.Name, Namespace: meta.Namespace, Component: component.Name})
+		if err != nil {
+			return networkingv1.Ingress{}, err
+		}
+		if host == "" {
+			return networkingv1.Ingress{}, fmt.Errorf("no host template is configured for the Ingress %q", meta.Name)
+		}
+	}
+
+	pathType := networkingv1.PathTypePrefix
+	ingress := networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Ingress",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: meta,
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: serviceName,
+											Port: networkingv1.ServiceBackendPort{Number: port},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if config.ClassName != "" {
+		className := config.ClassName
+		ingress.Spec.IngressClassName = &className
+	}
+	if config.TLSSecretName != "" {
+		tls := networkingv1.IngressTLS{SecretName: config.TLSSecretName}
+		if host != "" {
+			tls.Hosts = []string{host}
+		}
+		ingress.Spec.TLS = []networkingv1.IngressTLS{tls}
+	}
+	return ingress, nil
+}
+
+// GenerateBuildWebhookIngress returns the Ingress resource that would enable
+// ingress traffic into the webhook endpoint ( aka EventListener) on clusters without OpenShift Routes
+func GenerateBuildWebhookIngress(component appstudiov1alpha1.Component, config prepare.IngressConfig) (networkingv1.Ingress, error) {
+	meta := metav1.ObjectMeta{
+		Name:        "el" + component.Name,
+		Namespace:   component.Namespace,
+		Annotations: getBuildCommonLabelsForComponent(&component),
+	}
+	return generateIngress(meta, component, "", "el-"+component.Name, 8080, config)
+}
+
+// GenerateComponentIngress replaces the Route generated for the component with an equivalent Ingress,
+// for clusters without OpenShift Routes. Nothing is done if no Ingress configuration is set or the component has no Route.
+func GenerateComponentIngress(outputPath string, component appstudiov1alpha1.Component, appFs afero.Afero, context string, gitopsConfig prepare.GitopsConfig) error {
+	if gitopsConfig.Ingress == nil {
+		return nil
+	}
+	componentPath := filepath.Join(outputPath, component.Name, context, "components", component.Name, "base")
+	routePath := filepath.Join(componentPath, componentRouteFileName)
+	if exists, err := appFs.Exists(routePath); err != nil || !exists {
+		return err
+	}
+
+	routeBytes, err := appFs.ReadFile(routePath)
+	if err != nil {
+		return fmt.Errorf("failed to read the route of component %q: %v", component.Name, err)
+	}
+	route := routev1.Route{}
+	if err := sigsyaml.Unmarshal(routeBytes, &route); err != nil {
+		return fmt.Errorf("failed to parse the route of component %q: %v", component.Name, err)
+	}
+	var port int32
+	if route.Spec.Port != nil {
+		port = route.Spec.Port.TargetPort.IntVal
+	}
+	meta := metav1.ObjectMeta{
+		Name:      route.Name,
+		Namespace: route.Namespace,
+		Labels:    route.Labels,
+	}
+	ingress, err := generateIngress(meta, component, route.Spec.Host, route.Spec.To.Name, port, *gitopsConfig.Ingress)
+	if err != nil {
+		return err
+	}
+
+	if err := appFs.Remove(routePath); err != nil {
+		return fmt.Errorf("failed to remove the route of component %q: %v", component.Name, err)
+	}
+	if _, err := yaml.WriteResources(appFs, componentPath, map[string]interface{}{componentIngressFileName: ingress}); err != nil {
+		return fmt.Errorf("failed to write the ingress of component %q: %v", component.Name, err)
+	}
+	// Update the kustomize file so that it references the ingress instead of the route
+	if err := gitopsgen.UpdateExistingKustomize(appFs, componentPath); err != nil {
+		return fmt.Errorf("failed to update kustomize file for the ingress in %q for component %q: %s", componentPath, component.Name, err)
+	}
+	return nil
+}
+
+// GetIngressHost returns the host the Ingress is reachable at: the host of its first rule, or the address
+// of its load balancer if the rule accepts any host. An empty string is returned if it is not reachable yet.
+func GetIngressHost(ingress networkingv1.Ingress) string {
+	if len(ingress.Spec.Rules) > 0 && ingress.Spec.Rules[0].Host != "" {
+		return ingress.Spec.Rules[0].Host
+	}
+	if len(ingress.Status.LoadBalancer.Ingress) > 0 {
+		if ingress.Status.LoadBalancer.Ingress[0].Hostname != "" {
+			return ingress.Status.LoadBalancer.Ingress[0].Hostname
+		}
+		return ingress.Status.LoadBalancer.Ingress[0].IP
+	}
+	return ""
+}
@@ -0,0 +1,332 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	gitopsgen "github.com/redhat-developer/gitops-generator/pkg"
+	"github.com/redhat-developer/gitops-generator/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestRenderIngressHost(t *testing.T) {
+	data := IngressHostData{
+		Name:      "eltest-component",
+		Namespace: "test-namespace",
+		Component: "test-component",
+	}
+
+	tests := []struct {
+		name         string
+		hostTemplate string
+		want         string
+		wantErr      bool
+	}{
+		{
+			name: "No template accepts any host",
+			want: "",
+		},
+		{
+			name:         "Template referencing all the fields",
+			hostTemplate: "{{ .Name }}-{{ .Namespace }}.apps.example.com",
+			want:         "eltest-component-test-namespace.apps.example.com",
+		},
+		{
+			name:         "Invalid template",
+			hostTemplate: "{{ .Name ",
+			wantErr:      true,
+		},
+		{
+			name:         "Template referencing an unknown field",
+			hostTemplate: "{{ .Cluster }}.example.com",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderIngressHost(tt.hostTemplate, data)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error return value. Got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RenderIngressHost() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateIngressHostTemplate(t *testing.T) {
+	tests := []struct {
+		name         string
+		hostTemplate string
+		wantErr      bool
+	}{
+		{
+			name:         "Template rendering a host per Ingress name and namespace",
+			hostTemplate: "{{ .Name }}-{{ .Namespace }}.apps.example.com",
+		},
+		{
+			name:    "No template",
+			wantErr: true,
+		},
+		{
+			name:         "Invalid template",
+			hostTemplate: "{{ .Name ",
+			wantErr:      true,
+		},
+		{
+			name:         "Template rendering the same host for every Ingress",
+			hostTemplate: "apps.example.com",
+			wantErr:      true,
+		},
+		{
+			name:         "Template rendering the same host for the Ingresses of a component",
+			hostTemplate: "{{ .Component }}-{{ .Namespace }}.apps.example.com",
+			wantErr:      true,
+		},
+		{
+			name:         "Template rendering the same host in every namespace",
+			hostTemplate: "{{ .Name }}.apps.example.com",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateIngressHostTemplate(tt.hostTemplate); tt.wantErr != (err != nil) {
+				t.Errorf("ValidateIngressHostTemplate() unexpected error return value. Got %v", err)
+			}
+		})
+	}
+}
+
+func TestGenerateBuildWebhookIngress(t *testing.T) {
+	component := appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-component",
+			Namespace: "test-namespace",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		config    prepare.IngressConfig
+		wantClass string
+		wantHost  string
+		wantTLS   []networkingv1.IngressTLS
+		wantErr   bool
+	}{
+		{
+			name:    "No host template",
+			wantErr: true,
+		},
+		{
+			name: "Ingress with a class, a host and TLS",
+			config: prepare.IngressConfig{
+				ClassName:     "nginx",
+				HostTemplate:  "{{ .Name }}.{{ .Namespace }}.example.com",
+				TLSSecretName: "webhook-tls",
+			},
+			wantClass: "nginx",
+			wantHost:  "eltest-component.test-namespace.example.com",
+			wantTLS:   []networkingv1.IngressTLS{{Hosts: []string{"eltest-component.test-namespace.example.com"}, SecretName: "webhook-tls"}},
+		},
+		{
+			name:    "Invalid host template",
+			config:  prepare.IngressConfig{HostTemplate: "{{ .Name "},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingress, err := GenerateBuildWebhookIngress(component, tt.config)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error return value. Got %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if ingress.Name != "eltest-component" || ingress.Namespace != "test-namespace" {
+				t.Errorf("GenerateBuildWebhookIngress() generated %s/%s, want test-namespace/eltest-component", ingress.Namespace, ingress.Name)
+			}
+			if got := ingress.Spec.IngressClassName; (got == nil && tt.wantClass != "") || (got != nil && *got != tt.wantClass) {
+				t.Errorf("GenerateBuildWebhookIngress() class = %v, want %q", got, tt.wantClass)
+			}
+			rule := ingress.Spec.Rules[0]
+			if rule.Host != tt.wantHost {
+				t.Errorf("GenerateBuildWebhookIngress() host = %q, want %q", rule.Host, tt.wantHost)
+			}
+			backend := rule.HTTP.Paths[0].Backend.Service
+			if backend.Name != "el-test-component" || backend.Port.Number != 8080 {
+				t.Errorf("GenerateBuildWebhookIngress() backend = %s:%d, want el-test-component:8080", backend.Name, backend.Port.Number)
+			}
+			if len(ingress.Spec.TLS) != len(tt.wantTLS) || (len(tt.wantTLS) > 0 && ingress.Spec.TLS[0].SecretName != tt.wantTLS[0].SecretName) {
+				t.Errorf("GenerateBuildWebhookIngress() TLS = %v, want %v", ingress.Spec.TLS, tt.wantTLS)
+			}
+		})
+	}
+}
+
+func TestGenerateComponentIngress(t *testing.T) {
+	outputPath := "/fake/path"
+
+	tests := []struct {
+		name         string
+		targetPort   int
+		route        string
+		gitopsConfig prepare.GitopsConfig
+		wantIngress  bool
+		wantHost     string
+	}{
+		{
+			name:         "Route is kept without an ingress configuration",
+			targetPort:   8080,
+			gitopsConfig: prepare.GitopsConfig{},
+		},
+		{
+			name:         "Nothing is generated for components without a route",
+			gitopsConfig: prepare.GitopsConfig{Ingress: &prepare.IngressConfig{}},
+		},
+		{
+			name:         "Route is replaced with an ingress using the host template",
+			targetPort:   8080,
+			gitopsConfig: prepare.GitopsConfig{Ingress: &prepare.IngressConfig{HostTemplate: "{{ .Component }}.example.com"}},
+			wantIngress:  true,
+			wantHost:     "test-component.example.com",
+		},
+		{
+			name:         "Route of the component takes precedence over the host template",
+			targetPort:   8080,
+			route:        "my-route.example.com",
+			gitopsConfig: prepare.GitopsConfig{Ingress: &prepare.IngressConfig{HostTemplate: "{{ .Component }}.example.com"}},
+			wantIngress:  true,
+			wantHost:     "my-route.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			component := appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-component",
+					Namespace: "test-namespace",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: "test-component",
+					Application:   "test-application",
+					TargetPort:    tt.targetPort,
+					Route:         tt.route,
+				},
+			}
+			gitopsFolder := filepath.Join(outputPath, component.Name, "/")
+			componentPath := filepath.Join(gitopsFolder, "components", component.Name, "base")
+			if err := gitopsgen.Generate(fs, gitopsFolder, componentPath, util.GetMappedGitOpsComponent(component)); err != nil {
+				t.Fatalf("failed to generate the gitops resources: %v", err)
+			}
+
+			err := GenerateComponentIngress(outputPath, component, fs, "/", tt.gitopsConfig)
+			testutils.AssertNoError(t, err)
+
+			routeExists, _ := fs.Exists(filepath.Join(componentPath, componentRouteFileName))
+			ingressExists, _ := fs.Exists(filepath.Join(componentPath, componentIngressFileName))
+			if ingressExists != tt.wantIngress || (routeExists && tt.wantIngress) {
+				t.Fatalf("GenerateComponentIngress() route generated: %v, ingress generated: %v, want ingress: %v", routeExists, ingressExists, tt.wantIngress)
+			}
+			if !tt.wantIngress {
+				return
+			}
+
+			kustomization, err := fs.ReadFile(filepath.Join(componentPath, kustomizeFileName))
+			testutils.AssertNoError(t, err)
+			if strings.Contains(string(kustomization), componentRouteFileName) || !strings.Contains(string(kustomization), componentIngressFileName) {
+				t.Errorf("GenerateComponentIngress() did not update the kustomization:\n%s", kustomization)
+			}
+
+			ingressBytes, err := fs.ReadFile(filepath.Join(componentPath, componentIngressFileName))
+			testutils.AssertNoError(t, err)
+			ingress := networkingv1.Ingress{}
+			testutils.AssertNoError(t, yaml.Unmarshal(ingressBytes, &ingress))
+			if ingress.Spec.Rules[0].Host != tt.wantHost {
+				t.Errorf("GenerateComponentIngress() host = %q, want %q", ingress.Spec.Rules[0].Host, tt.wantHost)
+			}
+			backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+			if backend.Name != component.Name || backend.Port.Number != int32(tt.targetPort) {
+				t.Errorf("GenerateComponentIngress() backend = %s:%d, want %s:%d", backend.Name, backend.Port.Number, component.Name, tt.targetPort)
+			}
+		})
+	}
+}
+
+func TestGetIngressHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		ingress networkingv1.Ingress
+		want    string
+	}{
+		{
+			name: "Host of the ingress rule",
+			ingress: networkingv1.Ingress{
+				Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{Host: "el.example.com"}}},
+			},
+			want: "el.example.com",
+		},
+		{
+			name: "Hostname of the load balancer",
+			ingress: networkingv1.Ingress{
+				Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{}}},
+				Status: networkingv1.IngressStatus{
+					LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}}},
+				},
+			},
+			want: "lb.example.com",
+		},
+		{
+			name: "IP of the load balancer",
+			ingress: networkingv1.Ingress{
+				Status: networkingv1.IngressStatus{
+					LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}},
+				},
+			},
+			want: "10.0.0.1",
+		},
+		{
+			name: "Ingress not admitted yet",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetIngressHost(tt.ingress); got != tt.want {
+				t.Errorf("GetIngressHost() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
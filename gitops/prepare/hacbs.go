@@ -0,0 +1,179 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package prepare
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	kcpclient "github.com/kcp-dev/apimachinery/pkg/client"
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultHACBSAPIExportName is the name of the APIExport of the HACBS APIs if none is configured
+	DefaultHACBSAPIExportName = "hacbs"
+	// DefaultHACBSDetectionTTL is how long the detection of the HACBS workflow in a workspace is cached if not configured
+	DefaultHACBSDetectionTTL = 5 * time.Minute
+
+	// The workflows a Component can be built with
+	HACBSWorkflow     = "HACBS"
+	AppStudioWorkflow = "AppStudio"
+
+	// The reasons reported for the detected workflow
+	HACBSAPIBindingReason = "HACBSAPIBinding"
+	HACBSAPIExportReason  = "HACBSAPIExport"
+	HACBSConfigMapReason  = "HACBSConfigMap"
+	DefaultWorkflowReason = "Default"
+)
+
+// HACBSDetector detects whether the HACBS workflow applies in a kcp workspace: the workspace binds the HACBS APIExport,
+// or is the workspace exporting the HACBS APIs. The detection is cached per workspace.
+type HACBSDetector struct {
+	// Client reads the APIBindings and APIExports of the workspace set in the context of the requests
+	Client client.Client
+
+	// APIExportName is the name of the APIExport of the HACBS APIs. DefaultHACBSAPIExportName is used if empty
+	APIExportName string
+
+	// TTL is how long a detection is cached. DefaultHACBSDetectionTTL is used if zero
+	TTL time.Duration
+
+	mutex      sync.Mutex
+	detections map[string]hacbsDetection
+}
+
+type hacbsDetection struct {
+	reason  string
+	expires time.Time
+}
+
+// NewHACBSDetector returns a detector of the HACBS workflow reading the workspaces with the client
+func NewHACBSDetector(cli client.Client, apiExportName string, ttl time.Duration) *HACBSDetector {
+	return &HACBSDetector{
+		Client:        cli,
+		APIExportName: apiExportName,
+		TTL:           ttl,
+	}
+}
+
+// Detect returns the reason the HACBS workflow applies in the workspace of the context, or an empty string if it does not
+func (d *HACBSDetector) Detect(ctx context.Context) (string, error) {
+	cluster, ok := kcpclient.ClusterFromContext(ctx)
+	if !ok {
+		return "", nil
+	}
+	workspace := cluster.String()
+
+	d.mutex.Lock()
+	detection, isCached := d.detections[workspace]
+	d.mutex.Unlock()
+	if isCached && time.Now().Before(detection.expires) {
+		return detection.reason, nil
+	}
+
+	reason, err := d.detect(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	ttl := d.TTL
+	if ttl == 0 {
+		ttl = DefaultHACBSDetectionTTL
+	}
+	d.mutex.Lock()
+	if d.detections == nil {
+		d.detections = make(map[string]hacbsDetection)
+	}
+	d.detections[workspace] = hacbsDetection{reason: reason, expires: time.Now().Add(ttl)}
+	d.mutex.Unlock()
+	return reason, nil
+}
+
+func (d *HACBSDetector) detect(ctx context.Context) (string, error) {
+	apiExportName := d.APIExportName
+	if apiExportName == "" {
+		apiExportName = DefaultHACBSAPIExportName
+	}
+
+	apiBindings := &apisv1alpha1.APIBindingList{}
+	if err := d.Client.List(ctx, apiBindings); err != nil {
+		if errors.IsForbidden(err) {
+			return "", fmt.Errorf("unable to list the APIBindings, check the apis.kcp.dev RBAC of the controller: %v", err)
+		}
+		if !isAPIUnavailable(err) {
+			return "", fmt.Errorf("unable to list the APIBindings: %v", err)
+		}
+	}
+	for _, apiBinding := range apiBindings.Items {
+		reference := apiBinding.Spec.Reference.Workspace
+		if reference != nil && reference.ExportName == apiExportName && apiBinding.Status.Phase == apisv1alpha1.APIBindingPhaseBound {
+			return HACBSAPIBindingReason, nil
+		}
+	}
+
+	apiExport := &apisv1alpha1.APIExport{}
+	if err := d.Client.Get(ctx, types.NamespacedName{Name: apiExportName}, apiExport); err != nil {
+		if errors.IsForbidden(err) {
+			return "", fmt.Errorf("unable to get the APIExport %s, check the apis.kcp.dev RBAC of the controller: %v", apiExportName, err)
+		}
+		if !isAPIUnavailable(err) {
+			return "", fmt.Errorf("unable to get the APIExport %s: %v", apiExportName, err)
+		}
+		return "", nil
+	}
+	return HACBSAPIExportReason, nil
+}
+
+// isAPIUnavailable returns whether the error means the resource does not exist in the workspace, rather than a
+// transient failure. A forbidden access is a misconfiguration of the RBAC and is not considered unavailable.
+func isAPIUnavailable(err error) bool {
+	return errors.IsNotFound(err) || meta.IsNoMatchError(err)
+}
+
+// Return true when the HACBS workflow applies to the components of the namespace
+func IsHACBS(ctx context.Context, cli client.Client, hacbsDetector *HACBSDetector, namespace string) bool {
+	return DetectHACBS(ctx, cli, hacbsDetector, namespace) != ""
+}
+
+// DetectHACBS returns the reason the HACBS workflow applies to the components of the namespace, or an empty string
+// if it does not. The APIBindings and APIExports of the workspace are looked up first with the detector, if any,
+// then the hacbs ConfigMap.
+func DetectHACBS(ctx context.Context, cli client.Client, hacbsDetector *HACBSDetector, namespace string) string {
+	if hacbsDetector != nil {
+		// A failed detection falls back to the ConfigMap, and is retried on the next call
+		reason, err := hacbsDetector.Detect(ctx)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Unable to detect the HACBS workflow from the APIBindings, falling back to the ConfigMap", "namespace", namespace)
+		} else if reason != "" {
+			return reason
+		}
+	}
+
+	var configMap = corev1.ConfigMap{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: HACBSConfigMapName, Namespace: namespace}, &configMap); err == nil {
+		return HACBSConfigMapReason
+	}
+	return ""
+}
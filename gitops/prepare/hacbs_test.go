@@ -0,0 +1,207 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package prepare
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kcpclient "github.com/kcp-dev/apimachinery/pkg/client"
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/logicalcluster"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getHACBSAPIBinding(exportName string, phase apisv1alpha1.APIBindingPhaseType) *apisv1alpha1.APIBinding {
+	return &apisv1alpha1.APIBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: exportName},
+		Spec: apisv1alpha1.APIBindingSpec{
+			Reference: apisv1alpha1.ExportReference{
+				Workspace: &apisv1alpha1.WorkspaceExportReference{Path: "root:hacbs", ExportName: exportName},
+			},
+		},
+		Status: apisv1alpha1.APIBindingStatus{Phase: phase},
+	}
+}
+
+func TestDetectHACBS(t *testing.T) {
+	namespace := "myNamespace"
+	hacbsConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: HACBSConfigMapName, Namespace: namespace},
+	}
+
+	tests := []struct {
+		name          string
+		objects       []client.Object
+		withDetector  bool
+		apiExportName string
+		want          string
+	}{
+		{
+			name:         "should detect HACBS from a bound APIBinding of the HACBS APIExport",
+			objects:      []client.Object{getHACBSAPIBinding(DefaultHACBSAPIExportName, apisv1alpha1.APIBindingPhaseBound)},
+			withDetector: true,
+			want:         HACBSAPIBindingReason,
+		},
+		{
+			name:          "should detect HACBS from a bound APIBinding of the configured APIExport",
+			objects:       []client.Object{getHACBSAPIBinding("hacbs-apis", apisv1alpha1.APIBindingPhaseBound)},
+			withDetector:  true,
+			apiExportName: "hacbs-apis",
+			want:          HACBSAPIBindingReason,
+		},
+		{
+			name:         "should ignore APIBindings of other APIExports",
+			objects:      []client.Object{getHACBSAPIBinding("other", apisv1alpha1.APIBindingPhaseBound)},
+			withDetector: true,
+			want:         "",
+		},
+		{
+			name:         "should ignore APIBindings that are not bound yet",
+			objects:      []client.Object{getHACBSAPIBinding(DefaultHACBSAPIExportName, apisv1alpha1.APIBindingPhaseBinding)},
+			withDetector: true,
+			want:         "",
+		},
+		{
+			name: "should detect HACBS in the workspace exporting the HACBS APIs",
+			objects: []client.Object{&apisv1alpha1.APIExport{
+				ObjectMeta: metav1.ObjectMeta{Name: DefaultHACBSAPIExportName},
+			}},
+			withDetector: true,
+			want:         HACBSAPIExportReason,
+		},
+		{
+			name:         "should fall back to the hacbs ConfigMap",
+			objects:      []client.Object{getHACBSAPIBinding(DefaultHACBSAPIExportName, apisv1alpha1.APIBindingPhaseBinding), hacbsConfigMap},
+			withDetector: true,
+			want:         HACBSConfigMapReason,
+		},
+		{
+			name:    "should detect HACBS from the hacbs ConfigMap without detector",
+			objects: []client.Object{getHACBSAPIBinding(DefaultHACBSAPIExportName, apisv1alpha1.APIBindingPhaseBound), hacbsConfigMap},
+			want:    HACBSConfigMapReason,
+		},
+		{
+			name:         "should not detect HACBS",
+			withDetector: true,
+			want:         "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = apisv1alpha1.AddToScheme(scheme)
+			cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.objects...).Build()
+			ctx := kcpclient.WithCluster(context.Background(), logicalcluster.New("root:org:ws"))
+
+			var detector *HACBSDetector
+			if tt.withDetector {
+				detector = NewHACBSDetector(cli, tt.apiExportName, 0)
+			}
+
+			if got := DetectHACBS(ctx, cli, detector, namespace); got != tt.want {
+				t.Errorf("DetectHACBS() = %q, want %q", got, tt.want)
+			}
+			if got := IsHACBS(ctx, cli, detector, namespace); got != (tt.want != "") {
+				t.Errorf("IsHACBS() = %v, want %v", got, tt.want != "")
+			}
+		})
+	}
+}
+
+func TestHACBSDetectorCache(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = apisv1alpha1.AddToScheme(scheme)
+	apiBinding := getHACBSAPIBinding(DefaultHACBSAPIExportName, apisv1alpha1.APIBindingPhaseBound)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(apiBinding).Build()
+	detector := NewHACBSDetector(cli, "", time.Hour)
+
+	hacbsCtx := kcpclient.WithCluster(context.Background(), logicalcluster.New("root:org:hacbs"))
+	if got, err := detector.Detect(hacbsCtx); err != nil || got != HACBSAPIBindingReason {
+		t.Fatalf("Detect() = %q, %v, want %q", got, err, HACBSAPIBindingReason)
+	}
+
+	// The detection is cached until it expires
+	if err := cli.Delete(context.Background(), apiBinding); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := detector.Detect(hacbsCtx); got != HACBSAPIBindingReason {
+		t.Errorf("Detect() = %q, want the cached %q", got, HACBSAPIBindingReason)
+	}
+
+	// Other workspaces are detected separately
+	otherCtx := kcpclient.WithCluster(context.Background(), logicalcluster.New("root:org:other"))
+	if got, _ := detector.Detect(otherCtx); got != "" {
+		t.Errorf("Detect() = %q for another workspace, want none", got)
+	}
+
+	detector.detections["root:org:hacbs"] = hacbsDetection{reason: HACBSAPIBindingReason, expires: time.Now().Add(-time.Second)}
+	if got, _ := detector.Detect(hacbsCtx); got != "" {
+		t.Errorf("Detect() = %q once expired, want none", got)
+	}
+
+	// No workspace is detected outside of kcp
+	if got, _ := detector.Detect(context.Background()); got != "" {
+		t.Errorf("Detect() = %q without workspace, want none", got)
+	}
+}
+
+// forbiddenClient forbids the listing of the APIBindings, as a controller missing the apis.kcp.dev RBAC
+type forbiddenClient struct {
+	client.Client
+}
+
+func (c forbiddenClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*apisv1alpha1.APIBindingList); ok {
+		return errors.NewForbidden(schema.GroupResource{Group: "apis.kcp.dev", Resource: "apibindings"}, "", nil)
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func TestHACBSDetectorForbidden(t *testing.T) {
+	namespace := "myNamespace"
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = apisv1alpha1.AddToScheme(scheme)
+	hacbsConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: HACBSConfigMapName, Namespace: namespace},
+	}
+	cli := forbiddenClient{fake.NewClientBuilder().WithScheme(scheme).WithObjects(hacbsConfigMap).Build()}
+	detector := NewHACBSDetector(cli, "", time.Hour)
+	ctx := kcpclient.WithCluster(context.Background(), logicalcluster.New("root:org:ws"))
+
+	// The forbidden access is reported, and not cached as a workspace without HACBS
+	if _, err := detector.Detect(ctx); err == nil {
+		t.Fatalf("Detect() error = nil, want forbidden")
+	}
+	if _, isCached := detector.detections["root:org:ws"]; isCached {
+		t.Errorf("Detect() cached a forbidden detection")
+	}
+
+	// The ConfigMap is still looked up
+	if got := DetectHACBS(ctx, cli, detector, namespace); got != HACBSConfigMapReason {
+		t.Errorf("DetectHACBS() = %q, want %q", got, HACBSConfigMapReason)
+	}
+}
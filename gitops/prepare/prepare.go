@@ -20,8 +20,14 @@ import (
 
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -32,19 +38,29 @@ const (
 	// data key within a configMap that holds the URL to a build bundle
 	BuildBundleConfigMapKey = "default_build_bundle"
 	HACBSBundleConfigMapKey = "hacbs_build_bundle"
+	// data key within a configMap that holds the rules selecting the build pipeline of a component
+	BuildPipelineSelectorsConfigMapKey = "build_pipeline_selectors"
 
 	// fallback bundle that will be used in case the bundle resolution fails
 	FallbackBuildBundle = "quay.io/redhat-appstudio/build-templates-bundle:8201a567956ba6d2095d615ea2c0f6ab35f9ba5f"
 	// default secret for app studio registry
 	RegistrySecret = "redhat-appstudio-registry-pull-secret"
+	// default ServiceAccount running the builds
+	DefaultBuildServiceAccountName = "pipeline"
 	// Pipelines as Code global configuration secret name
 	PipelinesAsCodeSecretName = "pipelines-as-code-secret"
-	// ConfigMap name for detection hacbs workflow
-	// Note: HACBS detection by configmap is temporary solution, will be changed to detection based
-	// on APIBinding API in KCP environment.
+	// ConfigMap name for detection hacbs workflow, when the workspace does not bind the HACBS APIExport
 	HACBSConfigMapName = "hacbs"
 )
 
+// PushClusterTriggerBindings are the names of the ClusterTriggerBindings extracting the parameters of the push events
+// of each supported git provider
+var PushClusterTriggerBindings = map[string]string{
+	"github":    "github-push",
+	"gitlab":    "gitlab-push",
+	"bitbucket": "bitbucket-push",
+}
+
 // Holds data that needs to be queried from the cluster in order for the gitops generation function to work
 // This struct is left here so more data can be added as needed
 type GitopsConfig struct {
@@ -56,25 +72,133 @@ type GitopsConfig struct {
 	PipelinesAsCodeCredentials map[string][]byte
 
 	IsHACBS bool
+
+	// HACBSReason is the reason the HACBS workflow applies, empty if it does not
+	HACBSReason string
+
+	// Rules selecting the build pipeline of the component, in order of priority.
+	// The default rules are used if empty
+	BuildPipelineSelectors []BuildPipelineSelector
+
+	// Names of the PushClusterTriggerBindings the cluster does not provide, so that the build resources include
+	// their own TriggerBinding instead
+	MissingClusterTriggerBindings map[string]bool
+
+	// Ingress is set when the cluster has no OpenShift Routes, so that the services are exposed with Kubernetes Ingresses instead
+	Ingress *IngressConfig
+
+	// BuildWorkspace configures the storage of the workspace of the build PipelineRuns
+	BuildWorkspace BuildWorkspaceConfig
+
+	// ApplicationImageTagging is the default image tagging of the Components of the component's Application, if any
+	ApplicationImageTagging *appstudiov1alpha1.ImageTagging
+
+	// ApplicationBuildCredentials are the default build credentials of the Components of the component's Application, if any
+	ApplicationBuildCredentials *appstudiov1alpha1.BuildCredentials
+}
+
+// BuildWorkspaceStrategy is the kind of storage backing the workspace of the build PipelineRuns
+type BuildWorkspaceStrategy string
+
+const (
+	// SharedBuildWorkspace binds the builds of all the components to an existing claim, in a sub path per component
+	SharedBuildWorkspace BuildWorkspaceStrategy = "shared"
+	// ComponentBuildWorkspace binds the builds of each component to a claim generated with its build resources
+	ComponentBuildWorkspace BuildWorkspaceStrategy = "component"
+	// PipelineRunBuildWorkspace binds each build to a claim created for the PipelineRun and deleted with it
+	PipelineRunBuildWorkspace BuildWorkspaceStrategy = "pipelinerun"
+
+	// DefaultBuildWorkspaceClaimName is the name of the claim shared by the builds if none is configured
+	DefaultBuildWorkspaceClaimName = "appstudio"
+	// DefaultBuildWorkspaceSize is the size of the generated build workspace claims if none is configured
+	DefaultBuildWorkspaceSize = "1Gi"
+)
+
+// BuildWorkspaceConfig holds the settings of the storage of the build workspace
+type BuildWorkspaceConfig struct {
+	// Strategy is the kind of storage of the workspace. SharedBuildWorkspace is used if empty
+	Strategy BuildWorkspaceStrategy
+
+	// ClaimName is the name of the claim shared by the builds. DefaultBuildWorkspaceClaimName is used if empty
+	ClaimName string
+
+	// StorageClassName is the StorageClass of the generated claims. The cluster's default class is used if empty
+	StorageClassName string
+
+	// Size is the requested storage of the generated claims. DefaultBuildWorkspaceSize is used if zero
+	Size resource.Quantity
+}
+
+// IngressConfig holds the settings of the Ingresses generated in place of OpenShift Routes
+type IngressConfig struct {
+	// ClassName is the IngressClass of the generated Ingresses. The cluster's default class is used if empty
+	ClassName string
+
+	// HostTemplate is a Go template rendering the host of an Ingress from its Name, Namespace and Component.
+	// The Ingresses accept any host if empty
+	HostTemplate string
+
+	// TLSSecretName is the name of the Secret holding the TLS certificate of the Ingresses. TLS is not configured if empty
+	TLSSecretName string
+}
+
+// BuildPipelineSelector is a rule selecting the build pipeline of the components matching all its non-empty conditions
+type BuildPipelineSelector struct {
+	// Name identifies the rule in the reason reported in the component's status
+	Name string `json:"name"`
+
+	// PipelineName is the name of the pipeline in the build bundle
+	PipelineName string `json:"pipelineName"`
+
+	// Language matches the language of the component's devfile, ignoring the case
+	Language string `json:"language,omitempty"`
+
+	// ProjectType matches the project type of the component's devfile, ignoring the case
+	ProjectType string `json:"projectType,omitempty"`
+
+	// Dockerfile matches the components whose devfile has an image component built from a Dockerfile
+	Dockerfile bool `json:"dockerfile,omitempty"`
+
+	// Params are additional parameters passed to the pipeline
+	Params []appstudiov1alpha1.BuildPipelineParam `json:"params,omitempty"`
 }
 
-func PrepareGitopsConfig(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) GitopsConfig {
+func PrepareGitopsConfig(ctx context.Context, cli client.Client, hacbsDetector *HACBSDetector, component appstudiov1alpha1.Component) GitopsConfig {
 	data := GitopsConfig{}
 
 	data.AppStudioRegistrySecretPresent = resolveRegistrySecretPresence(ctx, cli, component)
-	data.IsHACBS = IsHACBS(ctx, cli, component.Namespace)
-	resolvedBundle := ResolveBuildBundle(ctx, cli, component.Namespace, data.IsHACBS)
-	if resolvedBundle == "" {
-		data.BuildBundle = FallbackBuildBundle
-	} else {
-		data.BuildBundle = resolvedBundle
-	}
+	data.HACBSReason = DetectHACBS(ctx, cli, hacbsDetector, component.Namespace)
+	data.IsHACBS = data.HACBSReason != ""
+	data.BuildBundle = resolveComponentBuildBundle(ctx, cli, component, data.IsHACBS)
+
+	data.BuildPipelineSelectors = ResolveBuildPipelineSelectors(ctx, cli, component.Namespace)
 
 	data.PipelinesAsCodeCredentials = getPipelinesAsCodeConfigurationSecretData(ctx, cli, component)
+	data.MissingClusterTriggerBindings = resolveMissingClusterTriggerBindings(ctx, cli)
+	if application := resolveApplication(ctx, cli, component); application != nil {
+		data.ApplicationImageTagging = application.Spec.ImageTagging
+		data.ApplicationBuildCredentials = application.Spec.BuildCredentials
+	}
 
 	return data
 }
 
+// ResolveComponentBuildBundle returns the build bundle of the component: the bundle pinned by the component,
+// or the bundle configured in the namespace of the component, or in the default namespace, or the fallback bundle.
+func ResolveComponentBuildBundle(ctx context.Context, cli client.Client, hacbsDetector *HACBSDetector, component appstudiov1alpha1.Component) string {
+	return resolveComponentBuildBundle(ctx, cli, component, IsHACBS(ctx, cli, hacbsDetector, component.Namespace))
+}
+
+func resolveComponentBuildBundle(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component, isHACBS bool) string {
+	if component.Spec.BuildPipeline != nil && component.Spec.BuildPipeline.Bundle != "" {
+		return component.Spec.BuildPipeline.Bundle
+	}
+	if resolvedBundle := ResolveBuildBundle(ctx, cli, component.Namespace, isHACBS); resolvedBundle != "" {
+		return resolvedBundle
+	}
+	return FallbackBuildBundle
+}
+
 // Tries to load a custom build bundle path from a configmap.
 // The following priority is used: component's namespace -> default namespace -> empty string.
 func ResolveBuildBundle(ctx context.Context, cli client.Client, namespace string, isHACBS bool) string {
@@ -99,11 +223,33 @@ func ResolveBuildBundle(ctx context.Context, cli client.Client, namespace string
 	return ""
 }
 
-// Return true when hacbs configmap exists in the namespace
-func IsHACBS(ctx context.Context, cli client.Client, namespace string) bool {
-	var configMap = corev1.ConfigMap{}
-	err := cli.Get(ctx, types.NamespacedName{Name: HACBSConfigMapName, Namespace: namespace}, &configMap)
-	return err == nil
+// Tries to load the build pipeline selectors from the configmap holding the build bundle.
+// The following priority is used: component's namespace -> default namespace -> no selectors.
+// Malformed selectors are logged and skipped.
+func ResolveBuildPipelineSelectors(ctx context.Context, cli client.Client, namespace string) []BuildPipelineSelector {
+	namespaces := [2]string{namespace, BuildBundleDefaultNamespace}
+
+	for _, namespace := range namespaces {
+		var configMap = corev1.ConfigMap{}
+
+		// All errors during the loading of the configmaps should be treated as non-fatal
+		_ = cli.Get(ctx, types.NamespacedName{Name: BuildBundleConfigMapName, Namespace: namespace}, &configMap)
+
+		value, isPresent := configMap.Data[BuildPipelineSelectorsConfigMapKey]
+		if !isPresent || value == "" {
+			continue
+		}
+		var selectors []BuildPipelineSelector
+		if err := yaml.Unmarshal([]byte(value), &selectors); err != nil {
+			log.FromContext(ctx).Error(err, "Ignoring the malformed build pipeline selectors", "namespace", namespace, "configMap", BuildBundleConfigMapName)
+			continue
+		}
+		if len(selectors) > 0 {
+			return selectors
+		}
+	}
+
+	return nil
 }
 
 // Determines whether the 'redhat-appstudio-registry-pull-secret' Secret exists, so that the Generate* functions
@@ -122,3 +268,27 @@ func getPipelinesAsCodeConfigurationSecretData(ctx context.Context, cli client.C
 	}
 	return pacSecret.Data
 }
+
+// Returns the Application of the component, which holds the default image tagging and build credentials of its Components.
+// Errors are treated as non-fatal, so that the Component's own configuration or the default one is used.
+func resolveApplication(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) *appstudiov1alpha1.Application {
+	application := &appstudiov1alpha1.Application{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: component.Spec.Application, Namespace: component.Namespace}, application); err != nil {
+		return nil
+	}
+	return application
+}
+
+// Determines which of the push event ClusterTriggerBindings are missing from the cluster.
+// Only the bindings that are known not to exist are reported, so that any other error keeps the cluster-wide bindings in use.
+func resolveMissingClusterTriggerBindings(ctx context.Context, cli client.Client) map[string]bool {
+	missing := make(map[string]bool)
+	for _, bindingName := range PushClusterTriggerBindings {
+		binding := &unstructured.Unstructured{}
+		binding.SetGroupVersionKind(schema.GroupVersionKind{Group: "triggers.tekton.dev", Version: "v1beta1", Kind: "ClusterTriggerBinding"})
+		if err := cli.Get(ctx, types.NamespacedName{Name: bindingName}, binding); errors.IsNotFound(err) {
+			missing[bindingName] = true
+		}
+	}
+	return missing
+}
@@ -20,9 +20,15 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -78,6 +84,11 @@ func TestPrepareGitopsConfig(t *testing.T) {
 				PipelinesAsCodeCredentials: map[string][]byte{
 					"github.token": []byte("ghp_token"),
 				},
+				MissingClusterTriggerBindings: map[string]bool{
+					"github-push":    true,
+					"gitlab-push":    true,
+					"bitbucket-push": true,
+				},
 			},
 		},
 	}
@@ -85,7 +96,7 @@ func TestPrepareGitopsConfig(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			client := fake.NewClientBuilder().WithRuntimeObjects(&tt.buildBundleConfigMap, &tt.pacSecret).Build()
-			if got := PrepareGitopsConfig(context.TODO(), client, component); !reflect.DeepEqual(got, tt.want) {
+			if got := PrepareGitopsConfig(context.TODO(), client, nil, component); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("PrepareGitopsConfig() = %v, want %v", got, tt.want)
 			}
 		})
@@ -237,6 +248,162 @@ func TestResolveBuildBundle(t *testing.T) {
 	}
 }
 
+func TestResolveComponentBuildBundle(t *testing.T) {
+	ctx := context.TODO()
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BuildBundleConfigMapName,
+			Namespace: BuildBundleDefaultNamespace,
+		},
+		Data: map[string]string{
+			BuildBundleConfigMapKey: "quay.io/org/configured-bundle:1",
+		},
+	}
+
+	tests := []struct {
+		name          string
+		buildPipeline *appstudiov1alpha1.BuildPipelineOverride
+		configMap     *corev1.ConfigMap
+		want          string
+	}{
+		{
+			name:          "should use the bundle pinned by the component",
+			buildPipeline: &appstudiov1alpha1.BuildPipelineOverride{Bundle: "quay.io/org/pinned-bundle:1"},
+			configMap:     configMap,
+			want:          "quay.io/org/pinned-bundle:1",
+		},
+		{
+			name:          "should use the configured bundle if the component selects only the pipeline",
+			buildPipeline: &appstudiov1alpha1.BuildPipelineOverride{Name: "docker-build"},
+			configMap:     configMap,
+			want:          "quay.io/org/configured-bundle:1",
+		},
+		{
+			name: "should fall back to the default bundle",
+			want: FallbackBuildBundle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "myName",
+					Namespace: "myNamespace",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					BuildPipeline: tt.buildPipeline,
+				},
+			}
+			clientBuilder := fake.NewClientBuilder()
+			if tt.configMap != nil {
+				clientBuilder = clientBuilder.WithRuntimeObjects(tt.configMap)
+			}
+
+			if got := ResolveComponentBuildBundle(ctx, clientBuilder.Build(), nil, component); got != tt.want {
+				t.Errorf("ResolveComponentBuildBundle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveBuildPipelineSelectors(t *testing.T) {
+	ctx := context.TODO()
+	namespace := "myNamespace"
+
+	selectorsConfigMap := func(namespace string, selectors string) corev1.ConfigMap {
+		return corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+			},
+			Data: map[string]string{
+				BuildPipelineSelectorsConfigMapKey: selectors,
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      BuildBundleConfigMapName,
+				Namespace: namespace,
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		data       []corev1.ConfigMap
+		want       []BuildPipelineSelector
+		wantLogged bool
+	}{
+		{
+			name: "should resolve the selectors from the component's namespace first",
+			data: []corev1.ConfigMap{
+				selectorsConfigMap(namespace, `
+- name: python
+  pipelineName: python-builder
+  language: python
+  params:
+  - name: python-version
+    value: "3.9"
+- name: dockerfile
+  pipelineName: docker-build
+  dockerfile: true
+`),
+				selectorsConfigMap(BuildBundleDefaultNamespace, `[{"name": "default", "pipelineName": "noop"}]`),
+			},
+			want: []BuildPipelineSelector{
+				{Name: "python", PipelineName: "python-builder", Language: "python", Params: []appstudiov1alpha1.BuildPipelineParam{{Name: "python-version", Value: "3.9"}}},
+				{Name: "dockerfile", PipelineName: "docker-build", Dockerfile: true},
+			},
+		},
+		{
+			name: "should resolve the selectors from the default namespace",
+			data: []corev1.ConfigMap{
+				selectorsConfigMap(BuildBundleDefaultNamespace, `[{"name": "quarkus", "pipelineName": "java-builder", "projectType": "quarkus"}]`),
+			},
+			want: []BuildPipelineSelector{
+				{Name: "quarkus", PipelineName: "java-builder", ProjectType: "quarkus"},
+			},
+		},
+		{
+			name: "should ignore malformed selectors",
+			data: []corev1.ConfigMap{
+				selectorsConfigMap(namespace, "name: not-a-list"),
+				selectorsConfigMap(BuildBundleDefaultNamespace, `[{"name": "default", "pipelineName": "noop"}]`),
+			},
+			want: []BuildPipelineSelector{
+				{Name: "default", PipelineName: "noop"},
+			},
+			wantLogged: true,
+		},
+		{
+			name: "should return no selectors if none are configured",
+			data: []corev1.ConfigMap{
+				selectorsConfigMap(namespace, ""),
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientBuilder := fake.NewClientBuilder()
+			for i := range tt.data {
+				clientBuilder = clientBuilder.WithRuntimeObjects(&tt.data[i])
+			}
+			client := clientBuilder.Build()
+
+			logged := false
+			logCtx := logr.NewContext(ctx, funcr.New(func(prefix, args string) { logged = true }, funcr.Options{}))
+			if got := ResolveBuildPipelineSelectors(logCtx, client, namespace); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveBuildPipelineSelectors() = %v, want %v", got, tt.want)
+			}
+			if logged != tt.wantLogged {
+				t.Errorf("ResolveBuildPipelineSelectors() logged = %v, want %v", logged, tt.wantLogged)
+			}
+		})
+	}
+}
+
 func TestResolveRegistrySecretPresence(t *testing.T) {
 	ctx := context.TODO()
 
@@ -343,3 +510,109 @@ func TestGetPipelinesAsCodeConfigurationSecretData(t *testing.T) {
 	}
 
 }
+
+func TestResolveMissingClusterTriggerBindings(t *testing.T) {
+	githubBinding := &unstructured.Unstructured{}
+	githubBinding.SetGroupVersionKind(schema.GroupVersionKind{Group: "triggers.tekton.dev", Version: "v1beta1", Kind: "ClusterTriggerBinding"})
+	githubBinding.SetName("github-push")
+
+	tests := []struct {
+		name   string
+		client crclient.Client
+		want   map[string]bool
+	}{
+		{
+			name:   "should report the bindings missing from the cluster",
+			client: fake.NewClientBuilder().WithObjects(githubBinding).Build(),
+			want: map[string]bool{
+				"gitlab-push":    true,
+				"bitbucket-push": true,
+			},
+		},
+		{
+			name:   "should report all the bindings if none exists",
+			client: fake.NewClientBuilder().Build(),
+			want: map[string]bool{
+				"github-push":    true,
+				"gitlab-push":    true,
+				"bitbucket-push": true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveMissingClusterTriggerBindings(context.TODO(), tt.client); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveMissingClusterTriggerBindings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareGitopsConfigApplicationImageTagging(t *testing.T) {
+	ctx := context.TODO()
+
+	scheme := runtime.NewScheme()
+	if err := appstudiov1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("unable to add the appstudio types to the scheme: %v", err)
+	}
+
+	component := appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myName",
+			Namespace: "myNamespace",
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			Application: "myApplication",
+		},
+	}
+	imageTagging := &appstudiov1alpha1.ImageTagging{
+		Strategy:       appstudiov1alpha1.BranchImageTagStrategy,
+		AdditionalTags: []appstudiov1alpha1.ImageTag{"latest"},
+	}
+
+	tests := []struct {
+		name        string
+		application *appstudiov1alpha1.Application
+		want        *appstudiov1alpha1.ImageTagging
+	}{
+		{
+			name: "application with image tagging",
+			application: &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "myApplication",
+					Namespace: component.Namespace,
+				},
+				Spec: appstudiov1alpha1.ApplicationSpec{
+					ImageTagging: imageTagging,
+				},
+			},
+			want: imageTagging,
+		},
+		{
+			name: "application without image tagging",
+			application: &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "myApplication",
+					Namespace: component.Namespace,
+				},
+			},
+		},
+		{
+			name: "application does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+			if tt.application != nil {
+				clientBuilder = clientBuilder.WithRuntimeObjects(tt.application)
+			}
+
+			if got := PrepareGitopsConfig(ctx, clientBuilder.Build(), nil, component).ApplicationImageTagging; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
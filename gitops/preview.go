@@ -0,0 +1,191 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+	"github.com/redhat-appstudio/application-service/pkg/util"
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	gitopsgen "github.com/redhat-developer/gitops-generator/pkg"
+	"github.com/spf13/afero"
+)
+
+const (
+	// GitOpsDryRunAnnotation, when set to "1" on a Component, renders the GitOps resources without pushing them
+	GitOpsDryRunAnnotation = "gitops-dry-run"
+
+	// PreviewManifestsKey is the ConfigMap data key holding the rendered GitOps resources
+	PreviewManifestsKey = "manifests.yaml"
+	// PreviewDiffKey is the ConfigMap data key holding the diff of the rendered resources against the GitOps repository
+	PreviewDiffKey = "diff"
+
+	previewOutputPath = "/preview"
+)
+
+// IsGitOpsDryRun returns true if the GitOps resources of the component should only be rendered, not pushed
+func IsGitOpsDryRun(component appstudiov1alpha1.Component) bool {
+	return component.Annotations[GitOpsDryRunAnnotation] == "1"
+}
+
+// GetPreviewConfigMapName returns the name of the ConfigMap that holds the GitOps preview of the component
+func GetPreviewConfigMapName(component appstudiov1alpha1.Component) string {
+	return component.Name + "-gitops-preview"
+}
+
+// GeneratePreview renders the GitOps and build resources of the component into an in-memory filesystem.
+// The returned map contains the content of each generated file, keyed by its path relative to the component's base folder.
+func GeneratePreview(component appstudiov1alpha1.Component, context string, gitopsConfig prepare.GitopsConfig) (map[string]string, error) {
+	appFs := ioutils.NewMemoryFilesystem()
+	gitopsFolder := filepath.Join(previewOutputPath, component.Name, context)
+	componentPath := filepath.Join(gitopsFolder, "components", component.Name, "base")
+
+	if err := gitopsgen.Generate(appFs, gitopsFolder, componentPath, util.GetMappedGitOpsComponent(component)); err != nil {
+		return nil, fmt.Errorf("failed to render the gitops resources for component %q: %v", component.Name, err)
+	}
+	if err := GenerateComponentIngress(previewOutputPath, component, appFs, context, gitopsConfig); err != nil {
+		return nil, err
+	}
+	if err := GenerateTektonBuild(previewOutputPath, component, appFs, context, gitopsConfig); err != nil {
+		return nil, err
+	}
+
+	return ReadResources(appFs, componentPath)
+}
+
+// CloneAndReadResources clones the GitOps repository into outputPath and returns the component's current base resources,
+// keyed by their path relative to the component's base folder. Nothing is committed or pushed to the repository.
+// An empty map is returned if the branch or the component folder does not exist yet.
+func CloneAndReadResources(outputPath string, remote string, componentName string, e gitopsgen.Executor, appFs afero.Afero, branch string, context string) (map[string]string, error) {
+	if out, err := e.Execute(outputPath, "git", "clone", remote, componentName); err != nil {
+		return nil, fmt.Errorf("failed to clone git repository in %q %q: %s", outputPath, string(out), err)
+	}
+
+	repoPath := filepath.Join(outputPath, componentName)
+	if out, err := e.Execute(repoPath, "git", "switch", branch); err != nil {
+		remoteBranches, lsErr := e.Execute(repoPath, "git", "ls-remote", "--heads", "origin", branch)
+		if lsErr != nil {
+			return nil, fmt.Errorf("failed to list the remote branch %q %q: %s", branch, string(remoteBranches), lsErr)
+		}
+		if strings.TrimSpace(string(remoteBranches)) != "" {
+			return nil, fmt.Errorf("failed to switch to branch %q %q: %s", branch, string(out), err)
+		}
+		// The branch has not been created yet, so every rendered resource is new
+		return map[string]string{}, nil
+	}
+
+	return ReadResources(appFs, filepath.Join(repoPath, context, "components", componentName, "base"))
+}
+
+// ReadResources returns the content of every file under dir, keyed by the file's path relative to dir
+func ReadResources(appFs afero.Afero, dir string) (map[string]string, error) {
+	resources := make(map[string]string)
+	if exists, err := appFs.DirExists(dir); err != nil || !exists {
+		return resources, err
+	}
+
+	err := appFs.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := appFs.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		resources[filepath.ToSlash(relPath)] = string(content)
+		return nil
+	})
+	return resources, err
+}
+
+// FormatPreviewManifests concatenates the rendered resources into a single multi-document YAML stream,
+// with each document preceded by a comment naming its file
+func FormatPreviewManifests(resources map[string]string) string {
+	var sb strings.Builder
+	for _, path := range sortedKeys(resources) {
+		sb.WriteString("---\n# Source: " + path + "\n")
+		sb.WriteString(resources[path])
+		if !strings.HasSuffix(resources[path], "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// DiffResources returns a unified diff going from the current resources to the rendered ones
+func DiffResources(current, rendered map[string]string) (string, error) {
+	paths := make(map[string]string)
+	for path, content := range current {
+		paths[path] = content
+	}
+	for path, content := range rendered {
+		paths[path] = content
+	}
+
+	var sb strings.Builder
+	for _, path := range sortedKeys(paths) {
+		currentContent, inCurrent := current[path]
+		renderedContent, inRendered := rendered[path]
+		fromFile, toFile := "a/"+path, "b/"+path
+		if !inCurrent {
+			fromFile = "/dev/null"
+		}
+		if !inRendered {
+			toFile = "/dev/null"
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(currentContent),
+			B:        splitLines(renderedContent),
+			FromFile: fromFile,
+			ToFile:   toFile,
+			Context:  3,
+		})
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(diff)
+	}
+	return sb.String(), nil
+}
+
+// splitLines splits the content into lines, returning no lines at all for empty content
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return difflib.SplitLines(content)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
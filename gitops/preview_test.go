@@ -0,0 +1,291 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
+	"github.com/redhat-developer/gitops-generator/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsGitOpsDryRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{
+			name: "No annotations",
+			want: false,
+		},
+		{
+			name:        "Dry-run annotation set",
+			annotations: map[string]string{GitOpsDryRunAnnotation: "1"},
+			want:        true,
+		},
+		{
+			name:        "Dry-run annotation with another value",
+			annotations: map[string]string{GitOpsDryRunAnnotation: "0"},
+			want:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := appstudiov1alpha1.Component{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := IsGitOpsDryRun(component); got != tt.want {
+				t.Errorf("IsGitOpsDryRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratePreview(t *testing.T) {
+	tests := []struct {
+		name      string
+		component appstudiov1alpha1.Component
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name: "Git component with a target port",
+			component: appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testcomponent",
+					Namespace: "workspace-name",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "testcomponent",
+					Application:    "testapplication",
+					ContainerImage: "quay.io/foo/bar",
+					TargetPort:     8080,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "https://host/git-repo.git",
+							},
+						},
+					},
+				},
+			},
+			want: []string{
+				"deployment.yaml",
+				"kustomization.yaml",
+				"route.yaml",
+				"service.yaml",
+				".tekton/" + buildEventListenerFileName,
+				".tekton/" + buildTriggerTemplateFileName,
+				".tekton/" + buildWebhookRouteFileName,
+				".tekton/" + kustomizeFileName,
+			},
+		},
+		{
+			name: "Image component has no build resources",
+			component: appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testcomponent",
+					Namespace: "workspace-name",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "testcomponent",
+					Application:    "testapplication",
+					ContainerImage: "quay.io/foo/bar",
+				},
+			},
+			want: []string{
+				"deployment.yaml",
+				"kustomization.yaml",
+			},
+		},
+		{
+			name: "Build generation fails for an invalid image",
+			component: appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testcomponent",
+					Namespace: "workspace-name",
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName:  "testcomponent",
+					Application:    "testapplication",
+					ContainerImage: DefaultImageRepo + ":other-namespace-testcomponent",
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: "https://host/git-repo.git",
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GeneratePreview(tt.component, "/", prepare.GitopsConfig{})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error return value. Got %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, len(tt.want), len(got), "unexpected rendered resources %v", got)
+			for _, path := range tt.want {
+				assert.NotEmpty(t, got[path], "Expected resource %s missing in the preview", path)
+			}
+		})
+	}
+}
+
+func TestCloneAndReadResources(t *testing.T) {
+	outputPath := "/clone"
+	componentName := "testcomponent"
+	basePath := filepath.Join(outputPath, componentName, "components", componentName, "base")
+
+	tests := []struct {
+		name    string
+		errors  []error
+		outputs [][]byte
+		files   map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "Existing component resources are read",
+			files: map[string]string{
+				"deployment.yaml":             "kind: Deployment\n",
+				".tekton/pac-repository.yaml": "kind: Repository\n",
+			},
+			want: map[string]string{
+				"deployment.yaml":             "kind: Deployment\n",
+				".tekton/pac-repository.yaml": "kind: Repository\n",
+			},
+		},
+		{
+			name: "Component not present in the repository yet",
+			want: map[string]string{},
+		},
+		{
+			name: "Branch not present in the repository yet",
+			// Errors are popped from the top of the stack, so the ls-remote error is pushed first
+			errors: []error{nil, errors.New("no such branch"), nil},
+			files: map[string]string{
+				"deployment.yaml": "kind: Deployment\n",
+			},
+			want: map[string]string{},
+		},
+		{
+			name:    "Switching to an existing branch fails",
+			errors:  []error{nil, errors.New("local changes would be overwritten"), nil},
+			outputs: [][]byte{[]byte("ca82a6dff817ec66f44342007202690a93763949\trefs/heads/main"), nil, nil},
+			wantErr: true,
+		},
+		{
+			name:    "Listing the remote branch fails",
+			errors:  []error{errors.New("unable to reach the remote"), errors.New("no such branch"), nil},
+			wantErr: true,
+		},
+		{
+			name:    "Clone fails",
+			errors:  []error{errors.New("unable to clone")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			for path, content := range tt.files {
+				testutils.AssertNoError(t, fs.WriteFile(filepath.Join(basePath, path), []byte(content), 0644))
+			}
+			e := testutils.NewMockExecutor(tt.outputs...)
+			for _, err := range tt.errors {
+				e.Errors.Push(err)
+			}
+
+			got, err := CloneAndReadResources(outputPath, "https://github.com/foo/bar", componentName, e, fs, "main", "/")
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error return value. Got %v", err)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CloneAndReadResources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPreviewManifests(t *testing.T) {
+	resources := map[string]string{
+		"kustomization.yaml": "resources:\n- deployment.yaml\n",
+		"deployment.yaml":    "kind: Deployment",
+	}
+	want := "---\n# Source: deployment.yaml\nkind: Deployment\n---\n# Source: kustomization.yaml\nresources:\n- deployment.yaml\n"
+
+	if got := FormatPreviewManifests(resources); got != want {
+		t.Errorf("FormatPreviewManifests() = %q, want %q", got, want)
+	}
+}
+
+func TestDiffResources(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  map[string]string
+		rendered map[string]string
+		want     []string
+	}{
+		{
+			name:     "No changes",
+			current:  map[string]string{"deployment.yaml": "replicas: 1\n"},
+			rendered: map[string]string{"deployment.yaml": "replicas: 1\n"},
+		},
+		{
+			name:     "Added, modified and removed resources",
+			current:  map[string]string{"deployment.yaml": "replicas: 1\n", "route.yaml": "kind: Route\n"},
+			rendered: map[string]string{"deployment.yaml": "replicas: 2\n", "service.yaml": "kind: Service\n"},
+			want: []string{
+				"--- a/deployment.yaml\n+++ b/deployment.yaml\n",
+				"-replicas: 1\n+replicas: 2\n",
+				"--- a/route.yaml\n+++ /dev/null\n",
+				"-kind: Route\n",
+				"--- /dev/null\n+++ b/service.yaml\n",
+				"+kind: Service\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffResources(tt.current, tt.rendered)
+			testutils.AssertNoError(t, err)
+			if len(tt.want) == 0 && got != "" {
+				t.Errorf("DiffResources() = %q, want no diff", got)
+			}
+			for _, want := range tt.want {
+				assert.True(t, strings.Contains(got, want), "Expected %q in the diff %q", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"fmt"
+	"path/filepath"
+
+	gitopsgen "github.com/redhat-developer/gitops-generator/pkg"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// GitOpsDeliveryModeAnnotation selects how changes are delivered to the GitOps repository.
+	// It can be set on an Application, to apply to all of its Components, or on an Environment, to apply to its Bindings.
+	GitOpsDeliveryModeAnnotation = "gitops-delivery-mode"
+
+	// PullRequestDeliveryMode pushes the changes to a generated branch and opens a pull request against the GitOps branch
+	PullRequestDeliveryMode = "pull-request"
+
+	pullRequestBranchPrefix = "appstudio-gitops-"
+)
+
+// IsPullRequestDelivery returns true if the object requests the GitOps changes to be delivered through a pull request
+func IsPullRequestDelivery(obj metav1.Object) bool {
+	return obj.GetAnnotations()[GitOpsDeliveryModeAnnotation] == PullRequestDeliveryMode
+}
+
+// GetPullRequestBranch returns the branch the GitOps changes for the given name are pushed to in pull request mode.
+// The branch name is stable, so that later changes update the already opened pull request instead of opening a new one.
+func GetPullRequestBranch(name string) string {
+	return pullRequestBranchPrefix + name
+}
+
+// CommitAndPushToPullRequestBranch commits the changes in the cloned GitOps repository on top of the checked out branch
+// and force pushes them to the pull request branch. The pull request branch is reset on every push, so it always holds
+// the latest generated resources on top of the base branch.
+// Returns false if there were no changes to push.
+func CommitAndPushToPullRequestBranch(outputPath string, repoPathOverride string, remote string, componentName string, e gitopsgen.Executor, prBranch string, commitMessage string) (bool, error) {
+	repoPath := filepath.Join(outputPath, componentName)
+	if repoPathOverride != "" {
+		repoPath = filepath.Join(outputPath, repoPathOverride)
+	}
+
+	if out, err := e.Execute(repoPath, "git", "checkout", "-B", prBranch); err != nil {
+		return false, fmt.Errorf("failed to checkout branch %q in %q %q: %s", prBranch, repoPath, string(out), err)
+	}
+	if out, err := e.Execute(repoPath, "git", "add", "."); err != nil {
+		return false, fmt.Errorf("failed to add files for component %q to repository in %q %q: %s", componentName, repoPath, string(out), err)
+	}
+
+	// See if any files changed, and if so, commit and push them up to the pull request branch
+	out, err := e.Execute(repoPath, "git", "--no-pager", "diff", "--cached")
+	if err != nil {
+		return false, fmt.Errorf("failed to check git diff in repository %q %q: %s", repoPath, string(out), err)
+	}
+	if string(out) == "" {
+		return false, nil
+	}
+
+	if out, err := e.Execute(repoPath, "git", "commit", "-m", commitMessage); err != nil {
+		return false, fmt.Errorf("failed to commit files to repository in %q %q: %s", repoPath, string(out), err)
+	}
+	if out, err := e.Execute(repoPath, "git", "push", "--force", "origin", prBranch); err != nil {
+		return false, fmt.Errorf("failed push remote to repository %q %q: %s", remote, string(out), err)
+	}
+	return true, nil
+}
@@ -0,0 +1,128 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	"github.com/redhat-developer/gitops-generator/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsPullRequestDelivery(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{
+			name: "No annotations",
+			want: false,
+		},
+		{
+			name:        "Pull request delivery mode",
+			annotations: map[string]string{GitOpsDeliveryModeAnnotation: PullRequestDeliveryMode},
+			want:        true,
+		},
+		{
+			name:        "Unknown delivery mode",
+			annotations: map[string]string{GitOpsDeliveryModeAnnotation: "push"},
+			want:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			application := appstudiov1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := IsPullRequestDelivery(&application); got != tt.want {
+				t.Errorf("IsPullRequestDelivery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitAndPushToPullRequestBranch(t *testing.T) {
+	outputPath := "/fake/path"
+	repoPath := filepath.Join(outputPath, "test-component")
+	remote := "https://github.com/foo/bar"
+	prBranch := GetPullRequestBranch("test-component")
+
+	tests := []struct {
+		name       string
+		outputs    [][]byte
+		errors     []error
+		wantPushed bool
+		want       []testutils.Execution
+		wantErr    string
+	}{
+		{
+			name: "Changes are committed and force pushed to the pull request branch",
+			// Outputs are popped from the top of the stack, so the diff output is pushed first
+			outputs:    [][]byte{[]byte("diff"), nil, nil},
+			wantPushed: true,
+			want: []testutils.Execution{
+				{BaseDir: repoPath, Command: "git", Args: []string{"checkout", "-B", prBranch}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"add", "."}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"--no-pager", "diff", "--cached"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"commit", "-m", "Generating Tekton resources"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"push", "--force", "origin", prBranch}},
+			},
+		},
+		{
+			name:       "Nothing is pushed without changes",
+			wantPushed: false,
+			want: []testutils.Execution{
+				{BaseDir: repoPath, Command: "git", Args: []string{"checkout", "-B", prBranch}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"add", "."}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"--no-pager", "diff", "--cached"}},
+			},
+		},
+		{
+			name:    "Pull request branch checkout fails",
+			errors:  []error{errors.New("checkout failed")},
+			wantErr: "failed to checkout branch",
+		},
+		{
+			name:    "Push fails",
+			outputs: [][]byte{[]byte("diff"), nil, nil},
+			errors:  []error{errors.New("push failed"), nil, nil, nil, nil},
+			wantErr: "failed push remote to repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := testutils.NewMockExecutor(tt.outputs...)
+			for _, err := range tt.errors {
+				e.Errors.Push(err)
+			}
+
+			pushed, err := CommitAndPushToPullRequestBranch(outputPath, "", remote, "test-component", e, prBranch, "Generating Tekton resources")
+			if tt.wantErr != "" {
+				testutils.AssertErrorMatch(t, tt.wantErr, err)
+				return
+			}
+			testutils.AssertNoError(t, err)
+			if pushed != tt.wantPushed {
+				t.Errorf("CommitAndPushToPullRequestBranch() pushed = %v, want %v", pushed, tt.wantPushed)
+			}
+			e.AssertCommandsExecuted(t, tt.want)
+		})
+	}
+}
@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -31,11 +33,15 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/kcp"
@@ -48,11 +54,14 @@ import (
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
 	"github.com/redhat-appstudio/application-service/controllers"
 	appservicegitops "github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
 	"github.com/redhat-appstudio/application-service/pkg/devfile"
+	"github.com/redhat-appstudio/application-service/pkg/imageregistry"
 	"github.com/redhat-appstudio/application-service/pkg/spi"
 	"github.com/redhat-appstudio/application-service/pkg/util/ioutils"
 	appstudioshared "github.com/redhat-appstudio/managed-gitops/appstudio-shared/apis/appstudio.redhat.com/v1alpha1"
 	gitopsgen "github.com/redhat-developer/gitops-generator/pkg"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 
 	//+kubebuilder:scaffold:imports
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
@@ -69,6 +78,8 @@ func init() {
 	utilruntime.Must(appstudiov1alpha1.AddToScheme(scheme))
 
 	utilruntime.Must(appstudioshared.AddToScheme(scheme))
+
+	utilruntime.Must(tektonapi.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -107,8 +118,14 @@ func main() {
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "f50829e1.redhat.com",
 		LeaderElectionConfig:   restConfig,
+		// The ConfigMaps are read from the API server, so that only the build bundle ConfigMaps are cached and watched
+		ClientDisableCacheFor: []client.Object{&corev1.ConfigMap{}},
+	}
+	cacheSelectors := cache.SelectorsByObject{
+		&corev1.ConfigMap{}: {Field: fields.OneTermEqualSelector("metadata.name", prepare.BuildBundleConfigMapName)},
 	}
-	if kcpAPIsGroupPresent(restConfig) {
+	isKCP := kcpAPIsGroupPresent(restConfig)
+	if isKCP {
 		setupLog.Info("Looking up virtual workspace URL")
 		cfg, err := restConfigForAPIExport(ctx, restConfig, apiExportName)
 		if err != nil {
@@ -118,6 +135,10 @@ func main() {
 		setupLog.Info("Using virtual workspace URL", "url", cfg.Host)
 
 		options.LeaderElectionConfig = restConfig
+		options.NewCache = func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+			opts.SelectorsByObject = cacheSelectors
+			return kcp.NewClusterAwareCache(config, opts)
+		}
 		mgr, err = kcp.NewClusterAwareManager(cfg, options)
 		if err != nil {
 			setupLog.Error(err, "unable to start cluster aware manager")
@@ -125,6 +146,7 @@ func main() {
 		}
 	} else {
 		setupLog.Info("The apis.kcp.dev group is not present - creating standard manager")
+		options.NewCache = cache.BuilderWithOptions(cache.Options{SelectorsByObject: cacheSelectors})
 		mgr, err = ctrl.NewManager(restConfig, options)
 		if err != nil {
 			setupLog.Error(err, "unable to start manager")
@@ -155,6 +177,43 @@ func main() {
 	}
 	appservicegitops.SetDefaultImageRepo(imageRepository)
 
+	// Determine whether the services are exposed with OpenShift Routes or Kubernetes Ingresses
+	ingressConfig, err := resolveIngressConfig(restConfig, isKCP)
+	if err != nil {
+		setupLog.Error(err, "unable to resolve the ingress configuration")
+		os.Exit(1)
+	}
+	if ingressConfig != nil {
+		setupLog.Info("Exposing the services with Kubernetes Ingresses", "ingressClass", ingressConfig.ClassName)
+	}
+
+	// Determine the storage of the build workspaces
+	buildWorkspaceConfig, err := resolveBuildWorkspaceConfig()
+	if err != nil {
+		setupLog.Error(err, "unable to resolve the build workspace configuration")
+		os.Exit(1)
+	}
+
+	// Determine the image registry provisioning the image repositories of the Components, if any
+	imageRegistry, imageRepositoryScope, err := resolveImageRegistry()
+	if err != nil {
+		setupLog.Error(err, "unable to resolve the image registry configuration")
+		os.Exit(1)
+	}
+	if imageRegistry != nil {
+		setupLog.Info("Provisioning the image repositories of the Components", "provider", os.Getenv("IMAGE_REGISTRY_PROVIDER"), "scope", imageRepositoryScope)
+	}
+
+	// Detect the HACBS workflow from the APIBindings and APIExports of the workspaces on kcp
+	var hacbsDetector *prepare.HACBSDetector
+	if isKCP {
+		hacbsDetector, err = resolveHACBSDetector(restConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to resolve the HACBS detection configuration")
+			os.Exit(1)
+		}
+	}
+
 	// Retrieve the option to specify a custom devfile registry
 	devfileRegistryURL := os.Getenv("DEVFILE_REGISTRY_URL")
 	if devfileRegistryURL == "" {
@@ -184,6 +243,14 @@ func main() {
 		GitToken:        ghToken,
 		ImageRepository: imageRepository,
 		SPIClient:       spi.SPIClient{},
+		GitHubClient:    client,
+		Ingress:         ingressConfig,
+		BuildWorkspace:  buildWorkspaceConfig,
+
+		ImageRegistry:        imageRegistry,
+		ImageRepositoryScope: imageRepositoryScope,
+		DigestResolver:       imageregistry.NewDigestResolver(),
+		HACBSDetector:        hacbsDetector,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Component")
 		os.Exit(1)
@@ -214,12 +281,13 @@ func main() {
 	}
 
 	if err = (&controllers.ApplicationSnapshotEnvironmentBindingReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Log:      ctrl.Log.WithName("controllers").WithName("ApplicationSnapshotEnvironmentBinding"),
-		Executor: gitopsgen.NewCmdExecutor(),
-		AppFS:    ioutils.NewFilesystem(),
-		GitToken: ghToken,
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Log:          ctrl.Log.WithName("controllers").WithName("ApplicationSnapshotEnvironmentBinding"),
+		Executor:     gitopsgen.NewCmdExecutor(),
+		AppFS:        ioutils.NewFilesystem(),
+		GitToken:     ghToken,
+		GitHubClient: client,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ApplicationSnapshotEnvironmentBinding")
 		os.Exit(1)
@@ -288,6 +356,15 @@ func restConfigForAPIExport(ctx context.Context, cfg *rest.Config, apiExportName
 }
 
 func kcpAPIsGroupPresent(restConfig *rest.Config) bool {
+	return apiGroupVersionPresent(restConfig, apisv1alpha1.SchemeGroupVersion.Group, apisv1alpha1.SchemeGroupVersion.Version)
+}
+
+// routeAPIsGroupPresent returns whether the cluster serves OpenShift Routes
+func routeAPIsGroupPresent(restConfig *rest.Config) bool {
+	return apiGroupVersionPresent(restConfig, routev1.GroupVersion.Group, routev1.GroupVersion.Version)
+}
+
+func apiGroupVersionPresent(restConfig *rest.Config, groupName string, groupVersion string) bool {
 	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
 	if err != nil {
 		setupLog.Error(err, "failed to create discovery client")
@@ -300,9 +377,9 @@ func kcpAPIsGroupPresent(restConfig *rest.Config) bool {
 	}
 
 	for _, group := range apiGroupList.Groups {
-		if group.Name == apisv1alpha1.SchemeGroupVersion.Group {
+		if group.Name == groupName {
 			for _, version := range group.Versions {
-				if version.Version == apisv1alpha1.SchemeGroupVersion.Version {
+				if version.Version == groupVersion {
 					return true
 				}
 			}
@@ -310,3 +387,109 @@ func kcpAPIsGroupPresent(restConfig *rest.Config) bool {
 	}
 	return false
 }
+
+// resolveIngressConfig returns the configuration of the Ingresses to generate in place of OpenShift Routes,
+// or nil if Routes are used. The USE_INGRESS environment variable forces the choice, otherwise
+// Ingresses are used if the cluster does not serve Routes. On kcp, Routes are always used unless forced.
+func resolveIngressConfig(restConfig *rest.Config, isKCP bool) (*prepare.IngressConfig, error) {
+	useIngress := false
+	if value := os.Getenv("USE_INGRESS"); value != "" {
+		var err error
+		if useIngress, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid value %q for USE_INGRESS: %v", value, err)
+		}
+	} else if !isKCP {
+		useIngress = !routeAPIsGroupPresent(restConfig)
+	}
+	if !useIngress {
+		return nil, nil
+	}
+
+	ingressConfig := &prepare.IngressConfig{
+		ClassName:     os.Getenv("INGRESS_CLASS"),
+		HostTemplate:  os.Getenv("INGRESS_HOST_TEMPLATE"),
+		TLSSecretName: os.Getenv("INGRESS_TLS_SECRET"),
+	}
+	if err := appservicegitops.ValidateIngressHostTemplate(ingressConfig.HostTemplate); err != nil {
+		return nil, fmt.Errorf("invalid value for INGRESS_HOST_TEMPLATE: %v", err)
+	}
+	return ingressConfig, nil
+}
+
+// resolveBuildWorkspaceConfig returns the configuration of the storage of the build workspaces from the BUILD_WORKSPACE_STRATEGY,
+// BUILD_WORKSPACE_CLAIM, BUILD_WORKSPACE_STORAGE_CLASS and BUILD_WORKSPACE_SIZE environment variables.
+func resolveBuildWorkspaceConfig() (prepare.BuildWorkspaceConfig, error) {
+	workspaceConfig := prepare.BuildWorkspaceConfig{
+		Strategy:         prepare.BuildWorkspaceStrategy(os.Getenv("BUILD_WORKSPACE_STRATEGY")),
+		ClaimName:        os.Getenv("BUILD_WORKSPACE_CLAIM"),
+		StorageClassName: os.Getenv("BUILD_WORKSPACE_STORAGE_CLASS"),
+	}
+	switch workspaceConfig.Strategy {
+	case "", prepare.SharedBuildWorkspace, prepare.ComponentBuildWorkspace, prepare.PipelineRunBuildWorkspace:
+	default:
+		return workspaceConfig, fmt.Errorf("invalid value %q for BUILD_WORKSPACE_STRATEGY, expected one of %s, %s or %s", workspaceConfig.Strategy,
+			prepare.SharedBuildWorkspace, prepare.ComponentBuildWorkspace, prepare.PipelineRunBuildWorkspace)
+	}
+	if value := os.Getenv("BUILD_WORKSPACE_SIZE"); value != "" {
+		size, err := resource.ParseQuantity(value)
+		if err != nil {
+			return workspaceConfig, fmt.Errorf("invalid value %q for BUILD_WORKSPACE_SIZE: %v", value, err)
+		}
+		workspaceConfig.Size = size
+	}
+	return workspaceConfig, nil
+}
+
+// resolveImageRegistry returns the image registry provisioning the image repositories of the Components, configured with
+// the IMAGE_REGISTRY_* environment variables, and the scope of the repositories. No registry is returned if
+// IMAGE_REGISTRY_PROVIDER is not set, the Components being built into the shared IMAGE_REPOSITORY.
+func resolveImageRegistry() (imageregistry.ImageRegistry, string, error) {
+	provider := os.Getenv("IMAGE_REGISTRY_PROVIDER")
+	if provider == "" {
+		return nil, "", nil
+	}
+	scope := os.Getenv("IMAGE_REPOSITORY_SCOPE")
+	switch scope {
+	case "":
+		scope = imageregistry.ComponentScope
+	case imageregistry.ComponentScope, imageregistry.ApplicationScope:
+	default:
+		return nil, "", fmt.Errorf("invalid value %q for IMAGE_REPOSITORY_SCOPE, expected %s or %s", scope, imageregistry.ComponentScope, imageregistry.ApplicationScope)
+	}
+	registry, err := imageregistry.NewImageRegistry(provider, os.Getenv("IMAGE_REGISTRY_URL"), os.Getenv("IMAGE_REGISTRY_NAMESPACE"),
+		os.Getenv("IMAGE_REGISTRY_USERNAME"), os.Getenv("IMAGE_REGISTRY_TOKEN"))
+	if err != nil {
+		return nil, "", err
+	}
+	return registry, scope, nil
+}
+
+// resolveHACBSDetector returns the detector of the HACBS workflow reading the APIBindings and APIExports of the workspaces,
+// configured with the HACBS_API_EXPORT_NAME and HACBS_DETECTION_TTL environment variables.
+func resolveHACBSDetector(restConfig *rest.Config) (*prepare.HACBSDetector, error) {
+	ttl := prepare.DefaultHACBSDetectionTTL
+	if value := os.Getenv("HACBS_DETECTION_TTL"); value != "" {
+		var err error
+		if ttl, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid value %q for HACBS_DETECTION_TTL: %v", value, err)
+		}
+	}
+
+	apisScheme := runtime.NewScheme()
+	if err := apisv1alpha1.AddToScheme(apisScheme); err != nil {
+		return nil, fmt.Errorf("error adding apis.kcp.dev/v1alpha1 to scheme: %w", err)
+	}
+	httpClient, err := kcp.ClusterAwareHTTPClient(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := kcp.NewClusterAwareMapperProvider(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	workspaceClient, err := client.New(restConfig, client.Options{Scheme: apisScheme, Mapper: mapper, HTTPClient: httpClient})
+	if err != nil {
+		return nil, fmt.Errorf("error creating the workspace client: %w", err)
+	}
+	return prepare.NewHACBSDetector(workspaceClient, os.Getenv("HACBS_API_EXPORT_NAME"), ttl), nil
+}
@@ -0,0 +1,401 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// The project types of the components detected from their deployment descriptors
+const (
+	ComposeProjectType    = "Compose"
+	KubernetesProjectType = "Kubernetes"
+	HelmProjectType       = "Helm"
+)
+
+// HelmChartFileName is the file describing a Helm chart at the root of its directory
+const HelmChartFileName = "Chart.yaml"
+
+// ComposeFileNames are the names of the compose files searched at the root of a repository, in order of preference
+var ComposeFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// kubernetesWorkloadKinds are the kinds of the Kubernetes resources making a directory of manifests a component
+var kubernetesWorkloadKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "DeploymentConfig"}
+
+// DeploymentComponent is a component described by a compose service, Kubernetes manifests or a Helm chart
+type DeploymentComponent struct {
+	// Name is the name of the compose service, of the first Kubernetes workload or of the Helm chart
+	Name string
+
+	// ProjectType is ComposeProjectType, KubernetesProjectType or HelmProjectType
+	ProjectType string
+
+	// Context is the path of the component relative to the local path, ./ for the local path itself: the build
+	// context of the compose service, or the directory of the manifests or of the chart.
+	// Empty for the compose services deployed from an image
+	Context string
+
+	// DockerfileURI is the path of the Dockerfile building the compose service relative to the local path
+	DockerfileURI string
+
+	// Image is the container image deploying the component
+	Image string
+
+	// TargetPort is the first port exposed by the container of the component
+	TargetPort int
+
+	// Env are the environment variables of the container of the component
+	Env []corev1.EnvVar
+}
+
+// DetectDeploymentComponents returns the services of the compose file at the root of the local path, then the components
+// of the directories of Kubernetes manifests and of the Helm charts, searched in the directories allowed by the scan options.
+// The malformed compose files and Helm charts are logged and skipped.
+func DetectDeploymentComponents(log logr.Logger, localpath string, options ScanOptions) ([]DeploymentComponent, error) {
+	components, err := detectComposeServices(log, localpath)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := newScanRules(localpath, options)
+	if err != nil {
+		return nil, err
+	}
+	descriptorComponents, err := detectDescriptorDirectories(log, localpath, "", 0, rules)
+	if err != nil {
+		return nil, err
+	}
+	return append(components, descriptorComponents...), nil
+}
+
+// detectDescriptorDirectories detects the Helm chart or the Kubernetes manifests of the directory at the context, and
+// otherwise searches its subdirectories until the maximum depth. The subdirectories of a chart or manifests are not searched.
+func detectDescriptorDirectories(log logr.Logger, localpath string, context string, depth int, rules scanRules) ([]DeploymentComponent, error) {
+	dirPath := path.Join(localpath, context)
+	componentContext := context
+	if componentContext == "" {
+		componentContext = "./"
+	}
+
+	if context == "" || rules.isIncluded(context) {
+		chart, err := readHelmChart(dirPath, componentContext)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Skipping the malformed Helm chart of %s", componentContext))
+		} else if chart != nil {
+			return []DeploymentComponent{*chart}, nil
+		}
+		manifests, err := readKubernetesManifests(dirPath, componentContext)
+		if err != nil {
+			return nil, err
+		} else if manifests != nil {
+			return []DeploymentComponent{*manifests}, nil
+		}
+	}
+
+	var components []DeploymentComponent
+	if depth >= rules.maxDepth {
+		return components, nil
+	}
+	files, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		subContext := path.Join(context, f.Name())
+		if !f.IsDir() || rules.isExcluded(subContext) {
+			continue
+		}
+		subComponents, err := detectDescriptorDirectories(log, localpath, subContext, depth+1, rules)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, subComponents...)
+	}
+	return components, nil
+}
+
+// composeFile holds the fields of a compose file used for the detection
+type composeFile struct {
+	Services map[string]composeService `json:"services"`
+}
+
+type composeService struct {
+	Image       string             `json:"image,omitempty"`
+	Build       *composeBuild      `json:"build,omitempty"`
+	Ports       []composePort      `json:"ports,omitempty"`
+	Environment composeEnvironment `json:"environment,omitempty"`
+}
+
+// composeBuild is the build of a compose service, either its context or its context and Dockerfile
+type composeBuild struct {
+	Context    string `json:"context,omitempty"`
+	Dockerfile string `json:"dockerfile,omitempty"`
+}
+
+func (b *composeBuild) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &b.Context); err == nil {
+		return nil
+	}
+	type build composeBuild
+	return json.Unmarshal(data, (*build)(b))
+}
+
+// composePort is the container port of a port mapping of a compose service, e.g. 80, "8080:80" or "127.0.0.1:8080:80/tcp",
+// or of its long syntax. It is zero if the port cannot be read, e.g. a range.
+type composePort int
+
+func (p *composePort) UnmarshalJSON(data []byte) error {
+	var port struct {
+		Target int `json:"target"`
+	}
+	if err := json.Unmarshal(data, &port); err == nil {
+		*p = composePort(port.Target)
+		return nil
+	}
+	var mapping interface{}
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return err
+	}
+	ports := strings.Split(fmt.Sprint(mapping), ":")
+	target, _ := strconv.Atoi(strings.Split(ports[len(ports)-1], "/")[0])
+	*p = composePort(target)
+	return nil
+}
+
+// composeEnvironment is the environment of a compose service, either a list of NAME=value or a map of the values
+type composeEnvironment []corev1.EnvVar
+
+func (e *composeEnvironment) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		for _, variable := range list {
+			nameValue := strings.SplitN(variable, "=", 2)
+			envVar := corev1.EnvVar{Name: nameValue[0]}
+			if len(nameValue) == 2 {
+				envVar.Value = nameValue[1]
+			}
+			*e = append(*e, envVar)
+		}
+		return nil
+	}
+
+	var variables map[string]interface{}
+	if err := json.Unmarshal(data, &variables); err != nil {
+		return err
+	}
+	for name, value := range variables {
+		envVar := corev1.EnvVar{Name: name}
+		if value != nil {
+			envVar.Value = fmt.Sprint(value)
+		}
+		*e = append(*e, envVar)
+	}
+	sort.Slice(*e, func(i, j int) bool {
+		return (*e)[i].Name < (*e)[j].Name
+	})
+	return nil
+}
+
+// detectComposeServices returns a component per service of the first valid compose file found at the root of the local path
+func detectComposeServices(log logr.Logger, localpath string) ([]DeploymentComponent, error) {
+	for _, composeFileName := range ComposeFileNames {
+		/* #nosec G304 -- the compose file is read from the cloned repository */
+		composeBytes, err := ioutil.ReadFile(path.Join(localpath, composeFileName))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		var compose composeFile
+		if err := yaml.Unmarshal(composeBytes, &compose); err != nil {
+			log.Error(err, fmt.Sprintf("Skipping the malformed compose file %s", composeFileName))
+			continue
+		}
+		var names []string
+		for name := range compose.Services {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var components []DeploymentComponent
+		for _, name := range names {
+			service := compose.Services[name]
+			component := DeploymentComponent{
+				Name:        name,
+				ProjectType: ComposeProjectType,
+				Image:       service.Image,
+				Env:         service.Environment,
+			}
+			for _, port := range service.Ports {
+				if port != 0 {
+					component.TargetPort = int(port)
+					break
+				}
+			}
+			// The services built outside of the repository are deployed from their image
+			if service.Build != nil && !strings.HasPrefix(path.Clean(service.Build.Context), "..") && !path.IsAbs(service.Build.Context) {
+				component.Context = path.Clean(service.Build.Context)
+				dockerfile := service.Build.Dockerfile
+				if dockerfile == "" {
+					dockerfile = DockerfileName
+				}
+				component.DockerfileURI = path.Join(component.Context, dockerfile)
+				if component.Context == "." {
+					component.Context = "./"
+				}
+			}
+			components = append(components, component)
+		}
+		return components, nil
+	}
+	return nil, nil
+}
+
+// helmChart holds the fields of the Chart.yaml and values.yaml files of a Helm chart used for the detection
+type helmChart struct {
+	Name       string `json:"name"`
+	AppVersion string `json:"appVersion,omitempty"`
+}
+
+type helmValues struct {
+	Image struct {
+		Repository string `json:"repository,omitempty"`
+		Tag        string `json:"tag,omitempty"`
+	} `json:"image,omitempty"`
+	Service struct {
+		Port int `json:"port,omitempty"`
+	} `json:"service,omitempty"`
+}
+
+// readHelmChart returns the component of the Helm chart of the directory, nil if the directory is not a chart.
+// The image and port of the component are read from the image and service of the default values of the chart.
+func readHelmChart(dirPath string, context string) (*DeploymentComponent, error) {
+	/* #nosec G304 -- the chart is read from the cloned repository */
+	chartBytes, err := ioutil.ReadFile(path.Join(dirPath, HelmChartFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var chart helmChart
+	if err := yaml.Unmarshal(chartBytes, &chart); err != nil {
+		return nil, fmt.Errorf("unable to parse the Helm chart %s: %v", path.Join(context, HelmChartFileName), err)
+	}
+	component := DeploymentComponent{
+		Name:        chart.Name,
+		ProjectType: HelmProjectType,
+		Context:     context,
+	}
+	if component.Name == "" {
+		component.Name = path.Base(dirPath)
+	}
+
+	// The values are only informative, the component is detected even if they cannot be read
+	/* #nosec G304 -- the values are read from the cloned repository */
+	valuesBytes, err := ioutil.ReadFile(path.Join(dirPath, "values.yaml"))
+	var values helmValues
+	if err == nil && yaml.Unmarshal(valuesBytes, &values) == nil {
+		component.Image = values.Image.Repository
+		tag := values.Image.Tag
+		if tag == "" {
+			tag = chart.AppVersion
+		}
+		if component.Image != "" && tag != "" {
+			component.Image = component.Image + ":" + tag
+		}
+		component.TargetPort = values.Service.Port
+	}
+	return &component, nil
+}
+
+// kubernetesManifest holds the fields of the Kubernetes workloads used for the detection
+type kubernetesManifest struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Template struct {
+			Spec corev1.PodSpec `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+}
+
+var yamlDocumentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// readKubernetesManifests returns the component of the Kubernetes manifests of the directory, nil if the directory has no
+// workload. The component is named after the first workload, and deploys the image of the first container of the workload.
+func readKubernetesManifests(dirPath string, context string) (*DeploymentComponent, error) {
+	files, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.IsDir() || !(strings.HasSuffix(f.Name(), ".yaml") || strings.HasSuffix(f.Name(), ".yml")) {
+			continue
+		}
+		/* #nosec G304 -- the manifests are read from the cloned repository */
+		manifestBytes, err := ioutil.ReadFile(path.Join(dirPath, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		// The files that are not Kubernetes manifests, e.g. devfiles or compose files, are skipped
+		for _, document := range yamlDocumentSeparator.Split(string(manifestBytes), -1) {
+			var manifest kubernetesManifest
+			if err := yaml.Unmarshal([]byte(document), &manifest); err != nil || !isKubernetesWorkload(manifest.Kind) {
+				continue
+			}
+			component := DeploymentComponent{
+				Name:        manifest.Metadata.Name,
+				ProjectType: KubernetesProjectType,
+				Context:     context,
+			}
+			if containers := manifest.Spec.Template.Spec.Containers; len(containers) > 0 {
+				component.Image = containers[0].Image
+				component.Env = containers[0].Env
+				if len(containers[0].Ports) > 0 {
+					component.TargetPort = int(containers[0].Ports[0].ContainerPort)
+				}
+			}
+			return &component, nil
+		}
+	}
+	return nil, nil
+}
+
+func isKubernetesWorkload(kind string) bool {
+	for _, workloadKind := range kubernetesWorkloadKinds {
+		if kind == workloadKind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDetectDeploymentComponents(t *testing.T) {
+	compose := `services:
+  web:
+    build: ./web
+    ports:
+      - "8080:80"
+    environment:
+      - MODE=production
+      - EMPTY
+  api:
+    build:
+      context: services/api
+      dockerfile: Containerfile
+    ports:
+      - target: 3000
+        published: 80
+    environment:
+      NAME: api
+      DEBUG: true
+  db:
+    image: postgres:14
+    ports:
+      - 5432
+  external:
+    build: ../other
+    image: quay.io/org/external
+`
+	manifests := `apiVersion: v1
+kind: Service
+metadata:
+  name: backend
+spec:
+  ports:
+    - port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+spec:
+  template:
+    spec:
+      containers:
+        - name: backend
+          image: quay.io/org/backend:latest
+          ports:
+            - containerPort: 8080
+          env:
+            - name: LOG_LEVEL
+              value: debug
+`
+	files := map[string]string{
+		"compose.yaml":                         compose,
+		"docker-compose.yml":                   "services:\n  ignored:\n    image: ignored\n",
+		"deploy/k8s/manifests.yaml":            manifests,
+		"deploy/k8s/overlays/deployment.yaml":  manifests,
+		"charts/app/Chart.yaml":                "apiVersion: v2\nname: app\nappVersion: 1.0.0\n",
+		"charts/app/values.yaml":               "image:\n  repository: quay.io/org/app\nservice:\n  port: 8080\n",
+		"charts/app/templates/deployment.yaml": "kind: Deployment\nmetadata:\n  name: {{ .Release.Name }}\n",
+		"config/settings.yaml":                 "kind: ConfigMap\n",
+		"vendor/lib/deployment.yaml":           manifests,
+	}
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		options ScanOptions
+		want    []DeploymentComponent
+		wantErr bool
+	}{
+		{
+			name:  "compose services, manifests and chart",
+			files: files,
+			want: []DeploymentComponent{
+				{
+					Name:          "api",
+					ProjectType:   ComposeProjectType,
+					Context:       "services/api",
+					DockerfileURI: "services/api/Containerfile",
+					TargetPort:    3000,
+					Env:           []corev1.EnvVar{{Name: "DEBUG", Value: "true"}, {Name: "NAME", Value: "api"}},
+				},
+				{
+					Name:        "db",
+					ProjectType: ComposeProjectType,
+					Image:       "postgres:14",
+					TargetPort:  5432,
+				},
+				{
+					Name:        "external",
+					ProjectType: ComposeProjectType,
+					Image:       "quay.io/org/external",
+				},
+				{
+					Name:          "web",
+					ProjectType:   ComposeProjectType,
+					Context:       "web",
+					DockerfileURI: "web/Dockerfile",
+					TargetPort:    80,
+					Env:           []corev1.EnvVar{{Name: "MODE", Value: "production"}, {Name: "EMPTY"}},
+				},
+				{
+					Name:        "app",
+					ProjectType: HelmProjectType,
+					Context:     "charts/app",
+					Image:       "quay.io/org/app:1.0.0",
+					TargetPort:  8080,
+				},
+				{
+					Name:        "backend",
+					ProjectType: KubernetesProjectType,
+					Context:     "deploy/k8s",
+					Image:       "quay.io/org/backend:latest",
+					TargetPort:  8080,
+					Env:         []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}},
+				},
+			},
+		},
+		{
+			name:    "included paths",
+			files:   files,
+			options: ScanOptions{IncludePaths: []string{"deploy/**"}},
+			want: []DeploymentComponent{
+				{Name: "api", ProjectType: ComposeProjectType, Context: "services/api", DockerfileURI: "services/api/Containerfile", TargetPort: 3000,
+					Env: []corev1.EnvVar{{Name: "DEBUG", Value: "true"}, {Name: "NAME", Value: "api"}}},
+				{Name: "db", ProjectType: ComposeProjectType, Image: "postgres:14", TargetPort: 5432},
+				{Name: "external", ProjectType: ComposeProjectType, Image: "quay.io/org/external"},
+				{Name: "web", ProjectType: ComposeProjectType, Context: "web", DockerfileURI: "web/Dockerfile", TargetPort: 80,
+					Env: []corev1.EnvVar{{Name: "MODE", Value: "production"}, {Name: "EMPTY"}}},
+				{Name: "backend", ProjectType: KubernetesProjectType, Context: "deploy/k8s", Image: "quay.io/org/backend:latest", TargetPort: 8080,
+					Env: []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}}},
+			},
+		},
+		{
+			name: "manifests at the root and compose service built from the root",
+			files: map[string]string{
+				"docker-compose.yml": "services:\n  app:\n    build: .\n",
+				"deployment.yaml":    manifests,
+				"k8s/deployment.yml": manifests,
+			},
+			want: []DeploymentComponent{
+				{Name: "app", ProjectType: ComposeProjectType, Context: "./", DockerfileURI: "Dockerfile"},
+				{Name: "backend", ProjectType: KubernetesProjectType, Context: "./", Image: "quay.io/org/backend:latest", TargetPort: 8080,
+					Env: []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}}},
+			},
+		},
+		{
+			name: "no deployment descriptor",
+			files: map[string]string{
+				"main.go":    "package main",
+				"Dockerfile": "FROM scratch",
+			},
+		},
+		{
+			name: "malformed compose file",
+			files: map[string]string{
+				"compose.yaml":       "services: [",
+				"docker-compose.yml": "services:\n  app:\n    image: quay.io/org/app\n",
+			},
+			want: []DeploymentComponent{
+				{Name: "app", ProjectType: ComposeProjectType, Image: "quay.io/org/app"},
+			},
+		},
+		{
+			name: "malformed Helm chart",
+			files: map[string]string{
+				"charts/broken/Chart.yaml":  "name: [",
+				"charts/app/Chart.yaml":     "apiVersion: v2\nname: app\n",
+				"deploy/compose.yaml":       "services: [",
+				"deploy/k8s/manifests.yaml": manifests,
+			},
+			want: []DeploymentComponent{
+				{Name: "app", ProjectType: HelmProjectType, Context: "charts/app"},
+				{Name: "backend", ProjectType: KubernetesProjectType, Context: "deploy/k8s", Image: "quay.io/org/backend:latest", TargetPort: 8080,
+					Env: []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := ioutil.TempDir("", "deploy")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(repo)
+			for file, content := range tt.files {
+				filePath := filepath.Join(repo, file)
+				if err := os.MkdirAll(filepath.Dir(filePath), 0750); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(filePath, []byte(content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := DetectDeploymentComponents(logr.Discard(), repo, tt.options)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("DetectDeploymentComponents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectDeploymentComponents() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
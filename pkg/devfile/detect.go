@@ -33,6 +33,7 @@ import (
 type Alizer interface {
 	SelectDevFileFromTypes(path string, devFileTypes []recognizer.DevFileType) (recognizer.DevFileType, error)
 	DetectComponents(path string) ([]recognizer.Component, error)
+	Analyze(path string) ([]language.Language, error)
 }
 
 type AlizerClient struct {
@@ -40,98 +41,178 @@ type AlizerClient struct {
 
 // search attempts to read and return devfiles and dockerfiles from the local path upto the specified depth
 // If no devfile(s) or dockerfile(s) are found, then the Alizer tool is used to detect and match a devfile/dockerfile from the devfile registry
-// search returns 3 maps and an error:
+// search returns 4 maps and an error:
 // Map 1 returns a context to the devfile bytes if present.
 // Map 2 returns a context to the matched devfileURL from the devfile registry if no devfile is present in the context.
 // Map 3 returns a context to the dockerfile uri or a matched dockerfileURL from the devfile registry if no dockerfile is present in the context
-func search(log logr.Logger, a Alizer, localpath string, devfileRegistryURL string) (map[string][]byte, map[string]string, map[string]string, error) {
+// Map 4 returns a context to the dockerfiles found in the context, in order of preference, if the dockerfile uri is the first one of them
+func search(log logr.Logger, a Alizer, localpath string, devfileRegistryURL string, options ScanOptions) (map[string][]byte, map[string]string, map[string]string, map[string][]string, error) {
 
 	devfileMapFromRepo := make(map[string][]byte)
 	devfilesURLMapFromRepo := make(map[string]string)
 	dockerfileContextMapFromRepo := make(map[string]string)
+	dockerfileCandidatesMapFromRepo := make(map[string][]string)
 
-	files, err := ioutil.ReadDir(localpath)
+	rules, err := newScanRules(localpath, options)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
+	// Alizer detects the components of all the subdirectories at once, they are looked up by path while scanning
+	alizerComponents, err := a.DetectComponents(localpath)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	alizerComponentPaths := make(map[string]bool)
+	for _, alizerComponent := range alizerComponents {
+		alizerComponentPaths[path.Clean(alizerComponent.Path)] = true
+	}
+
+	s := scanner{
+		log:                             log,
+		alizer:                          a,
+		devfileRegistryURL:              devfileRegistryURL,
+		rules:                           rules,
+		alizerComponentPaths:            alizerComponentPaths,
+		devfileMapFromRepo:              devfileMapFromRepo,
+		devfilesURLMapFromRepo:          devfilesURLMapFromRepo,
+		dockerfileContextMapFromRepo:    dockerfileContextMapFromRepo,
+		dockerfileCandidatesMapFromRepo: dockerfileCandidatesMapFromRepo,
+	}
+	if err := s.scanDirectory(localpath, "", 1); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	if len(devfileMapFromRepo) == 0 {
+		// if we didnt find any devfile we should return an err
+		err = &NoDevfileFound{Location: localpath}
+	}
+
+	return devfileMapFromRepo, devfilesURLMapFromRepo, dockerfileContextMapFromRepo, dockerfileCandidatesMapFromRepo, err
+}
+
+// scanner walks the directories of a repository, recording the components detected in the maps returned by search
+type scanner struct {
+	log                             logr.Logger
+	alizer                          Alizer
+	devfileRegistryURL              string
+	rules                           scanRules
+	alizerComponentPaths            map[string]bool
+	devfileMapFromRepo              map[string][]byte
+	devfilesURLMapFromRepo          map[string]string
+	dockerfileContextMapFromRepo    map[string]string
+	dockerfileCandidatesMapFromRepo map[string][]string
+}
+
+// scanDirectory searches for components in the subdirectories of the directory at the given depth, and in their own
+// subdirectories until the maximum depth. The subdirectories of a detected component are not scanned.
+func (s scanner) scanDirectory(dirPath string, parentContext string, depth int) error {
+	files, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		curPath := path.Join(dirPath, f.Name())
+		context := path.Join(parentContext, f.Name())
+		if s.rules.isExcluded(context) {
+			s.log.Info(fmt.Sprintf("Skipping the excluded directory %s", context))
+			continue
+		}
+
+		isComponent := false
+		if s.rules.isIncluded(context) {
+			if isComponent, err = s.detectComponent(curPath, context); err != nil {
+				return err
+			}
+		}
+		if !isComponent && depth < s.rules.maxDepth {
+			if err := s.scanDirectory(curPath, context, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// detectComponent searches for a devfile or dockerfiles in the directory at the context, or detects its component with
+// Alizer, and returns whether a component was detected
+func (s scanner) detectComponent(curPath string, context string) (bool, error) {
+	isDevfilePresent := false
+	isDockerfilePresent := false
+	files, err := ioutil.ReadDir(curPath)
+	if err != nil {
+		return false, err
+	}
 	for _, f := range files {
-		if f.IsDir() {
-			isDevfilePresent := false
-			isDockerfilePresent := false
-			curPath := path.Join(localpath, f.Name())
-			context := f.Name()
-			files, err := ioutil.ReadDir(curPath)
+		if f.Name() == DevfileName || f.Name() == HiddenDevfileName {
+			// Check for devfile.yaml or .devfile.yaml
+			/* #nosec G304 -- false positive, filename is not based on user input*/
+			devfileBytes, err := ioutil.ReadFile(path.Join(curPath, f.Name()))
+			if err != nil {
+				return false, err
+			}
+
+			s.devfileMapFromRepo[context] = devfileBytes
+			isDevfilePresent = true
+		} else if f.IsDir() && f.Name() == HiddenDevfileDir {
+			// Check for .devfile/devfile.yaml or .devfile/.devfile.yaml
+			// if the dir is .devfile, we dont increment currentLevel
+			// consider devfile.yaml and .devfile/devfile.yaml as the same level, for example
+			hiddenDirPath := path.Join(curPath, HiddenDevfileDir)
+			hiddenfiles, err := ioutil.ReadDir(hiddenDirPath)
 			if err != nil {
-				return nil, nil, nil, err
+				return false, err
 			}
-			for _, f := range files {
+			for _, f := range hiddenfiles {
 				if f.Name() == DevfileName || f.Name() == HiddenDevfileName {
 					// Check for devfile.yaml or .devfile.yaml
 					/* #nosec G304 -- false positive, filename is not based on user input*/
-					devfileBytes, err := ioutil.ReadFile(path.Join(curPath, f.Name()))
+					devfileBytes, err := ioutil.ReadFile(path.Join(hiddenDirPath, f.Name()))
 					if err != nil {
-						return nil, nil, nil, err
+						return false, err
 					}
 
-					devfileMapFromRepo[context] = devfileBytes
+					s.devfileMapFromRepo[context] = devfileBytes
 					isDevfilePresent = true
-				} else if f.IsDir() && f.Name() == HiddenDevfileDir {
-					// Check for .devfile/devfile.yaml or .devfile/.devfile.yaml
-					// if the dir is .devfile, we dont increment currentLevel
-					// consider devfile.yaml and .devfile/devfile.yaml as the same level, for example
-					hiddenDirPath := path.Join(curPath, HiddenDevfileDir)
-					hiddenfiles, err := ioutil.ReadDir(hiddenDirPath)
-					if err != nil {
-						return nil, nil, nil, err
-					}
-					for _, f := range hiddenfiles {
-						if f.Name() == DevfileName || f.Name() == HiddenDevfileName {
-							// Check for devfile.yaml or .devfile.yaml
-							/* #nosec G304 -- false positive, filename is not based on user input*/
-							devfileBytes, err := ioutil.ReadFile(path.Join(hiddenDirPath, f.Name()))
-							if err != nil {
-								return nil, nil, nil, err
-							}
-
-							devfileMapFromRepo[context] = devfileBytes
-							isDevfilePresent = true
-						}
-					}
-				} else if f.Name() == DockerfileName {
-					// Check for Dockerfile
-					// NOTE: if a Dockerfile is named differently, for example, Dockerfile.jvm;
-					// thats ok. As we finish iterating through all the files in the localpath
-					// we will read the devfile to ensure a dockerfile has been referenced.
-					// However, if a Dockerfile is named differently and not referenced in the devfile
-					// it will go undetected
-					dockerfileContextMapFromRepo[context] = path.Join(context, DockerfileName)
-					isDockerfilePresent = true
-				}
-			}
-			// unset the dockerfile context if we have both devfile and dockerfile
-			// at this stage, we need to ensure the dockerfile has been referenced
-			// in the devfile image component even if we detect both devfile and dockerfile
-			if isDevfilePresent && isDockerfilePresent {
-				delete(dockerfileContextMapFromRepo, context)
-				isDockerfilePresent = false
-			}
-
-			if (!isDevfilePresent && !isDockerfilePresent) || (isDevfilePresent && !isDockerfilePresent) {
-				err := AnalyzePath(a, curPath, context, devfileRegistryURL, devfileMapFromRepo, devfilesURLMapFromRepo, dockerfileContextMapFromRepo, isDevfilePresent, isDockerfilePresent)
-				if err != nil {
-					return nil, nil, nil, err
 				}
 			}
 		}
 	}
+	// Check for Dockerfile, Containerfile, Dockerfile.* or *.Dockerfile, also under the docker and build subdirectories.
+	// If we have both devfile and dockerfile, we need to ensure the dockerfile has been referenced
+	// in the devfile image component, so the dockerfiles are only used without devfile
+	if !isDevfilePresent {
+		dockerfiles, err := FindDockerfiles(curPath)
+		if err != nil {
+			return false, err
+		}
+		if len(dockerfiles) > 0 {
+			s.dockerfileContextMapFromRepo[context] = path.Join(context, dockerfiles[0])
+			s.dockerfileCandidatesMapFromRepo[context] = dockerfiles
+			isDockerfilePresent = true
+		}
+	}
 
-	if len(devfileMapFromRepo) == 0 {
-		// if we didnt find any devfile we should return an err
-		err = &NoDevfileFound{Location: localpath}
+	if !isDevfilePresent && !isDockerfilePresent && !s.alizerComponentPaths[path.Clean(curPath)] {
+		// Without devfile nor dockerfile, only the directories Alizer detected as components are matched with the
+		// devfile registry, whatever their depth
+		return false, nil
 	}
 
-	return devfileMapFromRepo, devfilesURLMapFromRepo, dockerfileContextMapFromRepo, err
+	if (!isDevfilePresent && !isDockerfilePresent) || (isDevfilePresent && !isDockerfilePresent) {
+		err := AnalyzePath(s.alizer, curPath, context, s.devfileRegistryURL, s.devfileMapFromRepo, s.devfilesURLMapFromRepo, s.dockerfileContextMapFromRepo, isDevfilePresent, isDockerfilePresent)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	_, isDevfileDetected := s.devfileMapFromRepo[context]
+	_, isDockerfileDetected := s.dockerfileContextMapFromRepo[context]
+	return isDevfileDetected || isDockerfileDetected, nil
 }
 
 // AnalyzePath checks if a devfile or a dockerfile can be found in the localpath for the given context, this is a helper func used by the CDQ controller
@@ -231,6 +312,36 @@ func SearchForDockerfile(devfile []byte) (*v1alpha2.DockerfileImage, error) {
 	return nil, nil
 }
 
+// SearchForDeploymentManifests returns the URIs of the Kubernetes and OpenShift manifests deploying the devfile
+func SearchForDeploymentManifests(devfile []byte) ([]string, error) {
+	if len(devfile) == 0 {
+		return nil, nil
+	}
+	devfileData, err := ParseDevfileModel(string(devfile))
+	if err != nil {
+		return nil, err
+	}
+	var uris []string
+	for _, componentType := range []v1alpha2.ComponentType{v1alpha2.KubernetesComponentType, v1alpha2.OpenshiftComponentType} {
+		devfileComponents, err := devfileData.GetComponents(common.DevfileOptions{
+			ComponentOptions: common.ComponentOptions{
+				ComponentType: componentType,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, component := range devfileComponents {
+			if component.Kubernetes != nil && component.Kubernetes.Uri != "" {
+				uris = append(uris, component.Kubernetes.Uri)
+			} else if component.Openshift != nil && component.Openshift.Uri != "" {
+				uris = append(uris, component.Openshift.Uri)
+			}
+		}
+	}
+	return uris, nil
+}
+
 // Analyze is a wrapper call to Alizer's Analyze()
 func (a AlizerClient) Analyze(path string) ([]language.Language, error) {
 	return recognizer.Analyze(path)
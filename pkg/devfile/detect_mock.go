@@ -17,6 +17,8 @@ package devfile
 
 import (
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
 
 	"github.com/redhat-developer/alizer/go/pkg/apis/language"
@@ -44,7 +46,8 @@ func (a MockAlizerClient) DetectComponents(path string) ([]recognizer.Component,
 			},
 		}, nil
 	} else if !strings.Contains(path, "springboot") && !strings.Contains(path, "python") {
-		return nil, nil
+		// Like Alizer, detect the components of the subdirectories
+		return a.detectSubdirectoryComponents(path)
 	}
 
 	return []recognizer.Component{
@@ -66,6 +69,61 @@ func (a MockAlizerClient) DetectComponents(path string) ([]recognizer.Component,
 	}, nil
 }
 
+// detectSubdirectoryComponents detects the components of the subdirectories of the path whose name is mocked as a component,
+// without detecting the components nested in them
+func (a MockAlizerClient) detectSubdirectoryComponents(path string) ([]recognizer.Component, error) {
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, nil
+	}
+	var components []recognizer.Component
+	for _, f := range files {
+		if !f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		subdirectoryComponents, err := a.DetectComponents(filepath.Join(path, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, subdirectoryComponents...)
+	}
+	return components, nil
+}
+
+// Analyze is a wrapper call to Alizer's Analyze()
+func (a MockAlizerClient) Analyze(path string) ([]language.Language, error) {
+	if strings.Contains(path, "errorAnalyze") {
+		return nil, fmt.Errorf("dummy Analyze err")
+	} else if strings.Contains(path, "devfile-sample-nodejs-basic") {
+		return []language.Language{
+			{
+				Name:              "JavaScript",
+				Aliases:           []string{"nodejs"},
+				UsageInPercentage: 100,
+				Tools:             []string{"NodeJs"},
+				CanBeComponent:    true,
+			},
+		}, nil
+	} else if !strings.Contains(path, "springboot") {
+		return nil, nil
+	}
+
+	return []language.Language{
+		{
+			Name:              "Java",
+			UsageInPercentage: 80,
+			Frameworks:        []string{"Spring"},
+			Tools:             []string{"Maven"},
+			CanBeComponent:    true,
+		},
+		{
+			Name:              "Python",
+			UsageInPercentage: 20,
+			CanBeComponent:    true,
+		},
+	}, nil
+}
+
 // SelectDevFileFromTypes is a wrapper call to Alizer's SelectDevFileFromTypes()
 func (a MockAlizerClient) SelectDevFileFromTypes(path string, devFileTypes []recognizer.DevFileType) (recognizer.DevFileType, error) {
 	if strings.Contains(path, "/errorSelectDevFileFromTypes") {
@@ -84,7 +84,7 @@ func TestAnalyzeAndDetectDevfile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := util.CloneRepo(tt.clonePath, tt.repo, tt.token)
+			err := util.CloneRepo(tt.clonePath, tt.repo, "", tt.token)
 			if err != nil {
 				t.Errorf("got unexpected error %v", err)
 			} else {
@@ -158,3 +158,62 @@ components:
 		})
 	}
 }
+
+func TestSearchForDeploymentManifests(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		devfileString string
+		want          []string
+		wantErr       bool
+	}{
+		{
+			name: "Successfully get the manifest Uris",
+			devfileString: `
+schemaVersion: 2.2.0
+metadata:
+  name: nodejs
+components:
+  - name: outerloop-build
+    image:
+      imageName: nodejs-image:latest
+      dockerfile:
+        uri: docker/Dockerfile
+  - name: outerloop-deploy
+    kubernetes:
+      uri: kubernetes/deploy.yaml
+  - name: outerloop-route
+    openshift:
+      uri: openshift/route.yaml`,
+			want: []string{"kubernetes/deploy.yaml", "openshift/route.yaml"},
+		},
+		{
+			name: "Inlined manifests",
+			devfileString: `
+schemaVersion: 2.2.0
+metadata:
+  name: nodejs
+components:
+  - name: outerloop-deploy
+    kubernetes:
+      inlined: "kind: Deployment"`,
+		},
+		{
+			name:          "Invalid devfile",
+			devfileString: "schemaVersion: [",
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SearchForDeploymentManifests([]byte(tt.devfileString))
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("SearchForDeploymentManifests() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchForDeploymentManifests() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
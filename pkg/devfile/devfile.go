@@ -239,13 +239,16 @@ func DownloadDevfileAndDockerfile(url string) ([]byte, []byte) {
 	return devfileBytes, dockerfileBytes
 }
 
-// ScanRepo attempts to read and return devfiles and dockerfiles from the local path upto the specified depth
-// Iterate through each sub-folder under first level, and scan for component. (devfile, dockerfile, then Alizer)
-// If no devfile(s) or dockerfile(s) are found in sub-folders of the root directory, then the Alizer tool is used to detect and match a devfile/dockerfile from the devfile registry
-// ScanRepo returns 3 maps and an error:
+// ScanRepo attempts to read and return devfiles and dockerfiles from the local path upto the depth of the scan options
+// Iterate through each sub-folder not excluded by the scan options, and scan for component. (devfile, dockerfile, then Alizer)
+// The sub-folders of a folder without component are scanned in turn, until the maximum depth.
+// If no devfile(s) or dockerfile(s) are found in a sub-folder, then the Alizer tool is used to detect and match a devfile/dockerfile from the devfile registry
+// The contexts of the components are their paths relative to the local path, e.g. services/api
+// ScanRepo returns 4 maps and an error:
 // Map 1 returns a context to the devfile bytes if present.
 // Map 2 returns a context to the matched devfileURL from the devfile registry if no devfile is present in the context.
 // Map 3 returns a context to the dockerfile uri or a matched dockerfileURL from the devfile registry if no dockerfile is present in the context
-func ScanRepo(log logr.Logger, a Alizer, localpath string, devfileRegistryURL string) (map[string][]byte, map[string]string, map[string]string, error) {
-	return search(log, a, localpath, devfileRegistryURL)
+// Map 4 returns a context to the dockerfiles found in the context, in order of preference, if the dockerfile uri is the first one of them
+func ScanRepo(log logr.Logger, a Alizer, localpath string, devfileRegistryURL string, options ScanOptions) (map[string][]byte, map[string]string, map[string]string, map[string][]string, error) {
+	return search(log, a, localpath, devfileRegistryURL, options)
 }
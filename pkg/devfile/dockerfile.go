@@ -0,0 +1,87 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ContainerfileName is the name of the Containerfiles, the Dockerfiles of the Podman and Buildah tooling
+const ContainerfileName = "Containerfile"
+
+// DockerfileDirs are the subdirectories of a component searched for Dockerfiles after its own directory
+var DockerfileDirs = []string{"docker", "build"}
+
+// dockerfilePreference returns the rank of the file name among the Dockerfile names, lower being preferred,
+// and whether the file is a Dockerfile: Dockerfile, then Containerfile, then Dockerfile.* and then *.Dockerfile
+func dockerfilePreference(name string) (int, bool) {
+	switch {
+	case name == DockerfileName:
+		return 0, true
+	case name == ContainerfileName:
+		return 1, true
+	case strings.HasPrefix(name, DockerfileName+".") && len(name) > len(DockerfileName)+1:
+		return 2, true
+	case strings.HasSuffix(name, "."+DockerfileName) && len(name) > len(DockerfileName)+1:
+		return 3, true
+	}
+	return 0, false
+}
+
+// FindDockerfiles returns the paths, relative to the directory, of the Dockerfiles of the directory and of its DockerfileDirs,
+// in order of preference. The Dockerfiles of the directory are preferred to those of its subdirectories,
+// then the Dockerfiles are ordered by name as ranked by dockerfilePreference, and alphabetically.
+func FindDockerfiles(dirPath string) ([]string, error) {
+	var dockerfiles []string
+	for _, dir := range append([]string{""}, DockerfileDirs...) {
+		dockerfileDir := path.Join(dirPath, dir)
+		// A docker or build file, such as a build script, is not a directory of Dockerfiles
+		fileInfo, err := os.Stat(dockerfileDir)
+		if os.IsNotExist(err) || (err == nil && !fileInfo.IsDir()) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		files, err := ioutil.ReadDir(dockerfileDir)
+		if err != nil {
+			return nil, err
+		}
+
+		var names []string
+		for _, f := range files {
+			if _, isDockerfile := dockerfilePreference(f.Name()); isDockerfile && !f.IsDir() {
+				names = append(names, f.Name())
+			}
+		}
+		sort.SliceStable(names, func(i, j int) bool {
+			rankI, _ := dockerfilePreference(names[i])
+			rankJ, _ := dockerfilePreference(names[j])
+			if rankI != rankJ {
+				return rankI < rankJ
+			}
+			return names[i] < names[j]
+		})
+		for _, name := range names {
+			dockerfiles = append(dockerfiles, path.Join(dir, name))
+		}
+	}
+	return dockerfiles, nil
+}
@@ -0,0 +1,190 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/redhat-developer/alizer/go/pkg/apis/language"
+	"github.com/redhat-developer/alizer/go/pkg/apis/recognizer"
+)
+
+// MaxDevfileCandidates is the maximum number of devfile stacks ranked for a component
+const MaxDevfileCandidates = 5
+
+// The weights of the frameworks and tools of a language matched by the tags of a devfile stack, the same as Alizer's
+const (
+	frameworkWeight = 10
+	toolWeight      = 5
+)
+
+// DevfileCandidate is a devfile stack of the registry matching the languages of a component
+type DevfileCandidate struct {
+	// Name is the name of the devfile stack in the registry
+	Name string
+
+	// DevfileURL is the URL of the devfile of the stack in the registry
+	DevfileURL string
+
+	// Language and ProjectType are the language and project type of the stack
+	Language    string
+	ProjectType string
+
+	// Confidence is the share, in percent, of the match score of the stack among the ranked stacks
+	Confidence int
+
+	// Selected is whether the stack is the one selected by Alizer for the devfile of the component stub
+	Selected bool
+
+	// Frameworks and Tools are the frameworks and tools of the component matched by the stack
+	Frameworks []string
+	Tools      []string
+}
+
+// ComponentAnalysis holds the languages of a component and the devfile stacks matching them
+type ComponentAnalysis struct {
+	// Languages are the languages of the component, as analyzed by Alizer
+	Languages []language.Language
+
+	// DevfileCandidates are the devfile stacks matching the languages, by decreasing confidence
+	DevfileCandidates []DevfileCandidate
+}
+
+// AnalyzeComponents analyzes the languages of the components at the contexts of the local path, and ranks the devfile
+// stacks of the registry matching them. The devfile URLs are the registry devfiles selected for the component stubs, by
+// context. The contexts whose languages cannot be analyzed are skipped.
+func AnalyzeComponents(a Alizer, localpath string, contexts []string, devfileRegistryURL string, devfilesURLMap map[string]string) (map[string]ComponentAnalysis, error) {
+	devfileTypes, err := getAlizerDevfileTypes(devfileRegistryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	analyses := make(map[string]ComponentAnalysis)
+	for _, context := range contexts {
+		languages, err := a.Analyze(path.Join(localpath, context))
+		if err != nil || len(languages) == 0 {
+			continue
+		}
+		analyses[context] = ComponentAnalysis{
+			Languages:         languages,
+			DevfileCandidates: RankDevfileTypes(languages, devfileTypes, devfileRegistryURL, devfilesURLMap[context]),
+		}
+	}
+	return analyses, nil
+}
+
+// RankDevfileTypes returns the devfile stacks matching the languages, at most MaxDevfileCandidates, by decreasing confidence.
+// Each language scores the stacks the way Alizer selects a devfile: 1 for the language of the stack, and the weights of
+// the frameworks and tools matched by the project type and the tags of the stack. The scores are weighted by the usage
+// of the languages, the languages only detected from their configuration files counting for 1%. The confidences are the
+// shares of the scores among the returned stacks, so that they add up to 100 give or take the rounding.
+// The stack whose devfile is the selected devfile URL is marked as selected, and is always returned if it matches.
+func RankDevfileTypes(languages []language.Language, devfileTypes []recognizer.DevFileType, devfileRegistryURL, selectedDevfileURL string) []DevfileCandidate {
+	var candidates []DevfileCandidate
+	var scores []float64
+	for _, devfileType := range devfileTypes {
+		candidate := DevfileCandidate{
+			Name:        devfileType.Name,
+			DevfileURL:  devfileRegistryURL + "/devfiles/" + devfileType.Name,
+			Language:    devfileType.Language,
+			ProjectType: devfileType.ProjectType,
+		}
+		score := 0.0
+		for _, lang := range languages {
+			if !strings.EqualFold(devfileType.Language, lang.Name) && !matchesAny(lang.Aliases, devfileType.Language) {
+				continue
+			}
+			languageScore := 1
+			if matchesAny(lang.Frameworks, devfileType.ProjectType) {
+				languageScore += frameworkWeight
+				candidate.Frameworks = appendUnique(candidate.Frameworks, devfileType.ProjectType)
+			}
+			for _, tag := range devfileType.Tags {
+				if matchesAny(lang.Frameworks, tag) {
+					languageScore += frameworkWeight
+					candidate.Frameworks = appendUnique(candidate.Frameworks, tag)
+				}
+				if matchesAny(lang.Tools, tag) {
+					languageScore += toolWeight
+					candidate.Tools = appendUnique(candidate.Tools, tag)
+				}
+			}
+			usage := lang.UsageInPercentage
+			if usage <= 0 {
+				usage = 1
+			}
+			score += float64(languageScore) * usage
+		}
+		if score > 0 {
+			candidates = append(candidates, candidate)
+			scores = append(scores, score)
+		}
+	}
+
+	ranks := make([]int, len(candidates))
+	for i := range ranks {
+		ranks[i] = i
+	}
+	sort.SliceStable(ranks, func(i, j int) bool {
+		if scores[ranks[i]] != scores[ranks[j]] {
+			return scores[ranks[i]] > scores[ranks[j]]
+		}
+		return candidates[ranks[i]].Name < candidates[ranks[j]].Name
+	})
+	if len(ranks) > MaxDevfileCandidates {
+		// The selected stack takes the place of the last ranked stack if it is not among them
+		for _, rank := range ranks[MaxDevfileCandidates:] {
+			if selectedDevfileURL != "" && candidates[rank].DevfileURL == selectedDevfileURL {
+				ranks[MaxDevfileCandidates-1] = rank
+			}
+		}
+		ranks = ranks[:MaxDevfileCandidates]
+	}
+
+	total := 0.0
+	for _, rank := range ranks {
+		total += scores[rank]
+	}
+	var ranked []DevfileCandidate
+	for _, rank := range ranks {
+		candidate := candidates[rank]
+		candidate.Confidence = int(math.Round(100 * scores[rank] / total))
+		candidate.Selected = selectedDevfileURL != "" && candidate.DevfileURL == selectedDevfileURL
+		ranked = append(ranked, candidate)
+	}
+	return ranked
+}
+
+// matchesAny returns whether one of the values is the value, ignoring the case
+func matchesAny(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendUnique appends the value to the values if they do not already contain it, ignoring the case
+func appendUnique(values []string, value string) []string {
+	if matchesAny(values, value) {
+		return values
+	}
+	return append(values, value)
+}
@@ -0,0 +1,139 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/redhat-developer/alizer/go/pkg/apis/language"
+	"github.com/redhat-developer/alizer/go/pkg/apis/recognizer"
+)
+
+func TestRankDevfileTypes(t *testing.T) {
+	devfileTypes := []recognizer.DevFileType{
+		{Name: "java-maven", Language: "java", ProjectType: "maven", Tags: []string{"Java", "Maven"}},
+		{Name: "java-springboot-basic", Language: "java", ProjectType: "springboot", Tags: []string{"Java", "Spring", "Maven"}},
+		{Name: "java-quarkus", Language: "java", ProjectType: "quarkus", Tags: []string{"Java", "Quarkus", "Maven"}},
+		{Name: "nodejs-basic", Language: "nodejs", ProjectType: "nodejs", Tags: []string{"NodeJS", "Express"}},
+		{Name: "python-basic", Language: "python", ProjectType: "python", Tags: []string{"Python", "pip"}},
+		{Name: "go-basic", Language: "go", ProjectType: "go", Tags: []string{"Go"}},
+	}
+
+	tests := []struct {
+		name      string
+		languages []language.Language
+		selected  string
+		want      []DevfileCandidate
+	}{
+		{
+			name: "stacks matching the frameworks and tools of the languages",
+			languages: []language.Language{
+				{Name: "Java", UsageInPercentage: 80, Frameworks: []string{"Spring"}, Tools: []string{"Maven"}},
+				{Name: "JavaScript", Aliases: []string{"nodejs"}, UsageInPercentage: 20},
+			},
+			selected: "https://registry/devfiles/java-springboot-basic",
+			want: []DevfileCandidate{
+				{Name: "java-springboot-basic", DevfileURL: "https://registry/devfiles/java-springboot-basic", Language: "java", ProjectType: "springboot",
+					Confidence: 57, Selected: true, Frameworks: []string{"Spring"}, Tools: []string{"Maven"}},
+				{Name: "java-maven", DevfileURL: "https://registry/devfiles/java-maven", Language: "java", ProjectType: "maven",
+					Confidence: 21, Tools: []string{"Maven"}},
+				{Name: "java-quarkus", DevfileURL: "https://registry/devfiles/java-quarkus", Language: "java", ProjectType: "quarkus",
+					Confidence: 21, Tools: []string{"Maven"}},
+				{Name: "nodejs-basic", DevfileURL: "https://registry/devfiles/nodejs-basic", Language: "nodejs", ProjectType: "nodejs",
+					Confidence: 1},
+			},
+		},
+		{
+			name: "language detected from its configuration files only",
+			languages: []language.Language{
+				{Name: "Go", Tools: []string{"Go"}},
+			},
+			want: []DevfileCandidate{
+				{Name: "go-basic", DevfileURL: "https://registry/devfiles/go-basic", Language: "go", ProjectType: "go", Confidence: 100, Tools: []string{"Go"}},
+			},
+		},
+		{
+			name: "no matching stack",
+			languages: []language.Language{
+				{Name: "Rust", UsageInPercentage: 100},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RankDevfileTypes(tt.languages, devfileTypes, "https://registry", tt.selected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RankDevfileTypes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	var manyTypes []recognizer.DevFileType
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		manyTypes = append(manyTypes, recognizer.DevFileType{Name: name, Language: "java"})
+	}
+	got := RankDevfileTypes([]language.Language{{Name: "Java", UsageInPercentage: 100}}, manyTypes, "https://registry", "https://registry/devfiles/g")
+	var names []string
+	for _, candidate := range got {
+		names = append(names, candidate.Name)
+		if candidate.Confidence != 100/MaxDevfileCandidates || candidate.Selected != (candidate.Name == "g") {
+			t.Errorf("RankDevfileTypes() = %+v, want the confidences shared among the returned stacks and g selected", got)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"a", "b", "c", "d", "g"}) {
+		t.Errorf("RankDevfileTypes() returned the stacks %v, want the first stacks by name and the selected stack", names)
+	}
+}
+
+func TestAnalyzeComponents(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"name": "java-springboot-basic", "language": "java", "projectType": "springboot", "tags": ["Java", "Spring", "Maven"], "type": "sample"},
+			{"name": "nodejs-basic", "language": "nodejs", "projectType": "nodejs", "tags": ["NodeJS", "Express"], "type": "sample"}
+		]`))
+	}))
+	defer registry.Close()
+
+	analyses, err := AnalyzeComponents(MockAlizerClient{}, "/tmp/repo", []string{"springboot", "devfile-sample-nodejs-basic", "errorAnalyze", "empty"}, registry.URL,
+		map[string]string{"springboot": registry.URL + "/devfiles/java-springboot-basic"})
+	if err != nil {
+		t.Fatalf("AnalyzeComponents() unexpected error = %v", err)
+	}
+
+	var contexts []string
+	for context := range analyses {
+		contexts = append(contexts, context)
+	}
+	if len(analyses) != 2 {
+		t.Fatalf("AnalyzeComponents() analyzed the contexts %v, want springboot and devfile-sample-nodejs-basic", contexts)
+	}
+	if candidates := analyses["springboot"].DevfileCandidates; len(candidates) != 1 || candidates[0].Name != "java-springboot-basic" || candidates[0].Confidence != 100 || !candidates[0].Selected {
+		t.Errorf("AnalyzeComponents() ranked %+v for springboot, want java-springboot-basic", candidates)
+	}
+	if languages := analyses["springboot"].Languages; len(languages) != 2 || languages[0].Name != "Java" {
+		t.Errorf("AnalyzeComponents() analyzed the languages %+v for springboot, want Java and Python", languages)
+	}
+	if candidates := analyses["devfile-sample-nodejs-basic"].DevfileCandidates; len(candidates) != 1 || candidates[0].Name != "nodejs-basic" || candidates[0].Selected || !reflect.DeepEqual(candidates[0].Tools, []string{"NodeJS"}) {
+		t.Errorf("AnalyzeComponents() ranked %+v for devfile-sample-nodejs-basic, want nodejs-basic", candidates)
+	}
+
+	if _, err := AnalyzeComponents(MockAlizerClient{}, "/tmp/repo", []string{"springboot"}, "http://127.0.0.1:0", nil); err == nil {
+		t.Error("AnalyzeComponents() wanted an error for an unreachable registry")
+	}
+}
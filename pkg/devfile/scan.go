@@ -0,0 +1,147 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	// DefaultScanDepth is the maximum depth of the directories scanned for components if none is configured
+	DefaultScanDepth = 3
+	// ScanIgnoreFileName is the file at the root of a repository listing the paths skipped by the scan, one per line
+	ScanIgnoreFileName = ".cdqignore"
+)
+
+// DefaultScanExcludePaths are the directories never scanned for components: the hidden directories,
+// the vendored dependencies and the test directories
+var DefaultScanExcludePaths = []string{".*", "vendor", "node_modules", "test", "tests", "testdata"}
+
+// ScanOptions configures the directories of a repository scanned for components
+type ScanOptions struct {
+	// MaxDepth is the maximum depth of the scanned directories, the first-level directories being at depth 1.
+	// DefaultScanDepth is used if zero
+	MaxDepth int
+
+	// IncludePaths are globs of the contexts scanned for components. All the contexts are scanned if empty
+	IncludePaths []string
+
+	// ExcludePaths are globs of the directories skipped with their subdirectories,
+	// in addition to DefaultScanExcludePaths and the patterns of the repository's ignore file
+	ExcludePaths []string
+}
+
+// scanRules decides which directories of a repository are scanned for components
+type scanRules struct {
+	maxDepth     int
+	includePaths []string
+	excludePaths []string
+}
+
+// newScanRules returns the scan rules of the options, completed with the patterns of the ignore file of the repository
+func newScanRules(localpath string, options ScanOptions) (scanRules, error) {
+	rules := scanRules{
+		maxDepth:     options.MaxDepth,
+		includePaths: options.IncludePaths,
+	}
+	if rules.maxDepth <= 0 {
+		rules.maxDepth = DefaultScanDepth
+	}
+	rules.excludePaths = append(rules.excludePaths, DefaultScanExcludePaths...)
+	rules.excludePaths = append(rules.excludePaths, options.ExcludePaths...)
+
+	ignorePaths, err := readScanIgnoreFile(path.Join(localpath, ScanIgnoreFileName))
+	if err != nil {
+		return rules, err
+	}
+	rules.excludePaths = append(rules.excludePaths, ignorePaths...)
+	return rules, nil
+}
+
+// readScanIgnoreFile returns the patterns of the ignore file, skipping the empty lines and the comments starting with #
+func readScanIgnoreFile(ignoreFilePath string) ([]string, error) {
+	/* #nosec G304 -- the ignore file is read from the cloned repository */
+	ignoreFile, err := os.Open(ignoreFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer ignoreFile.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(ignoreFile)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns, scanner.Err()
+}
+
+// isExcluded returns whether the directory at the context is skipped with its subdirectories
+func (r scanRules) isExcluded(context string) bool {
+	for _, pattern := range r.excludePaths {
+		if matchesPathPattern(pattern, context) {
+			return true
+		}
+	}
+	return false
+}
+
+// isIncluded returns whether a component is searched in the directory at the context
+func (r scanRules) isIncluded(context string) bool {
+	if len(r.includePaths) == 0 {
+		return true
+	}
+	for _, pattern := range r.includePaths {
+		if matchesPathPattern(pattern, context) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPathPattern returns whether the context, relative to the root of the repository, matches the glob.
+// Globs without slash match the name of the directory, e.g. vendor, other globs match the whole context,
+// e.g. services/*, and globs ending with /** match the directories under their prefix.
+func matchesPathPattern(pattern string, context string) bool {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "/"), "/")
+	context = strings.Trim(path.Clean(context), "/")
+	if pattern == "" {
+		return false
+	}
+
+	if prefix := strings.TrimSuffix(pattern, "/**"); prefix != pattern {
+		for parent := path.Dir(context); parent != "." && parent != "/"; parent = path.Dir(parent) {
+			if matchesPathPattern(prefix, parent) {
+				return true
+			}
+		}
+		return false
+	}
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(context))
+		return matched
+	}
+	matched, _ := path.Match(pattern, context)
+	return matched
+}
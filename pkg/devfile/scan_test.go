@@ -0,0 +1,334 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devfile
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-developer/alizer/go/pkg/apis/language"
+	"github.com/redhat-developer/alizer/go/pkg/apis/recognizer"
+)
+
+func TestMatchesPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		context string
+		want    bool
+	}{
+		{pattern: "vendor", context: "vendor", want: true},
+		{pattern: "vendor", context: "services/vendor", want: true},
+		{pattern: "vendor/", context: "vendor", want: true},
+		{pattern: ".*", context: "services/.github", want: true},
+		{pattern: ".*", context: "services", want: false},
+		{pattern: "services/*", context: "services/api", want: true},
+		{pattern: "services/*", context: "services/api/v1", want: false},
+		{pattern: "/services/api", context: "services/api", want: true},
+		{pattern: "examples/**", context: "examples", want: false},
+		{pattern: "examples/**", context: "examples/demo", want: true},
+		{pattern: "examples/**", context: "examples/demo/web", want: true},
+		{pattern: "", context: "services", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.context, func(t *testing.T) {
+			if got := matchesPathPattern(tt.pattern, tt.context); got != tt.want {
+				t.Errorf("matchesPathPattern(%q, %q) = %v, want %v", tt.pattern, tt.context, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindDockerfiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{
+			name: "no dockerfile",
+			files: []string{
+				"main.go",
+				"Dockerfile/README.md",
+				"Dockerfile.",
+			},
+		},
+		{
+			name: "dockerfiles in order of preference",
+			files: []string{
+				"prod.Dockerfile",
+				"Dockerfile.native",
+				"Dockerfile.jvm",
+				"Containerfile",
+				"Dockerfile",
+				"build/Containerfile",
+				"docker/app.Dockerfile",
+				"docker/Dockerfile",
+			},
+			want: []string{
+				"Dockerfile",
+				"Containerfile",
+				"Dockerfile.jvm",
+				"Dockerfile.native",
+				"prod.Dockerfile",
+				"docker/Dockerfile",
+				"docker/app.Dockerfile",
+				"build/Containerfile",
+			},
+		},
+		{
+			name: "dockerfile in the build subdirectory",
+			files: []string{
+				"src/Dockerfile",
+				"build/Dockerfile.ci",
+			},
+			want: []string{"build/Dockerfile.ci"},
+		},
+		{
+			name: "docker and build files",
+			files: []string{
+				"Containerfile",
+				"build",
+				"docker",
+			},
+			want: []string{"Containerfile"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "dockerfiles")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			for _, file := range tt.files {
+				filePath := filepath.Join(dir, file)
+				if err := os.MkdirAll(filepath.Dir(filePath), 0750); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(filePath, []byte("FROM scratch"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := FindDockerfiles(dir)
+			if err != nil {
+				t.Fatalf("FindDockerfiles() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindDockerfiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanRepoOptions(t *testing.T) {
+	// The registry has no sample, so that the directories without component are not matched with Alizer
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer registry.Close()
+
+	repo, err := ioutil.TempDir("", "scan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(repo)
+	devfileWithDockerfile := `schemaVersion: 2.2.0
+metadata:
+  name: web
+components:
+  - name: image
+    image:
+      imageName: web:latest
+      dockerfile:
+        uri: docker/Dockerfile
+`
+	files := map[string]string{
+		"frontend/Dockerfile":               "FROM scratch",
+		"frontend/docker/Dockerfile":        "FROM scratch",
+		"services/worker/Dockerfile.jvm":    "FROM scratch",
+		"services/worker/Containerfile":     "FROM scratch",
+		"services/worker/docker/Dockerfile": "FROM scratch",
+		"services/api/Dockerfile":           "FROM scratch",
+		"services/api/internal/Dockerfile":  "FROM scratch",
+		"services/web/devfile.yaml":         devfileWithDockerfile,
+		"apps/mobile/ios/Dockerfile":        "FROM scratch",
+		"deep/a/b/c/Dockerfile":             "FROM scratch",
+		"vendor/lib/Dockerfile":             "FROM scratch",
+		"test/e2e/Dockerfile":               "FROM scratch",
+		".github/Dockerfile":                "FROM scratch",
+		"docs/Dockerfile":                   "FROM scratch",
+		"examples/demo/Dockerfile":          "FROM scratch",
+		ScanIgnoreFileName:                  "# documentation\n\ndocs\n",
+	}
+	for file, content := range files {
+		filePath := filepath.Join(repo, file)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name                     string
+		options                  ScanOptions
+		wantDockerfiles          map[string]string
+		wantDockerfileCandidates map[string][]string
+		wantDevfileContext       []string
+	}{
+		{
+			name:    "default depth and exclusions",
+			options: ScanOptions{ExcludePaths: []string{"examples/**"}},
+			wantDockerfiles: map[string]string{
+				"frontend":        "frontend/Dockerfile",
+				"services/api":    "services/api/Dockerfile",
+				"services/web":    "services/web/docker/Dockerfile",
+				"services/worker": "services/worker/Containerfile",
+				"apps/mobile/ios": "apps/mobile/ios/Dockerfile",
+			},
+			wantDockerfileCandidates: map[string][]string{
+				"frontend":        {"Dockerfile", "docker/Dockerfile"},
+				"services/api":    {"Dockerfile"},
+				"services/worker": {"Containerfile", "Dockerfile.jvm", "docker/Dockerfile"},
+				"apps/mobile/ios": {"Dockerfile"},
+			},
+			wantDevfileContext: []string{"services/web"},
+		},
+		{
+			name:    "deeper scan",
+			options: ScanOptions{MaxDepth: 4},
+			wantDockerfiles: map[string]string{
+				"frontend":        "frontend/Dockerfile",
+				"services/api":    "services/api/Dockerfile",
+				"services/web":    "services/web/docker/Dockerfile",
+				"services/worker": "services/worker/Containerfile",
+				"apps/mobile/ios": "apps/mobile/ios/Dockerfile",
+				"deep/a/b/c":      "deep/a/b/c/Dockerfile",
+				"examples/demo":   "examples/demo/Dockerfile",
+			},
+			wantDevfileContext: []string{"services/web"},
+		},
+		{
+			name:    "first level only",
+			options: ScanOptions{MaxDepth: 1},
+			wantDockerfiles: map[string]string{
+				"frontend": "frontend/Dockerfile",
+			},
+		},
+		{
+			name:    "included paths",
+			options: ScanOptions{IncludePaths: []string{"services/*"}},
+			wantDockerfiles: map[string]string{
+				"services/api":    "services/api/Dockerfile",
+				"services/web":    "services/web/docker/Dockerfile",
+				"services/worker": "services/worker/Containerfile",
+			},
+			wantDevfileContext: []string{"services/web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileMap, _, dockerfileMap, dockerfileCandidatesMap, err := ScanRepo(logr.Discard(), MockAlizerClient{}, repo, registry.URL, tt.options)
+			if len(tt.wantDevfileContext) == 0 {
+				if _, ok := err.(*NoDevfileFound); !ok {
+					t.Errorf("ScanRepo() error = %v, want NoDevfileFound", err)
+				}
+			} else if err != nil {
+				t.Fatalf("ScanRepo() unexpected error = %v", err)
+			}
+
+			if !reflect.DeepEqual(dockerfileMap, tt.wantDockerfiles) {
+				t.Errorf("ScanRepo() detected the dockerfiles %v, want %v", dockerfileMap, tt.wantDockerfiles)
+			}
+			if tt.wantDockerfileCandidates != nil && !reflect.DeepEqual(dockerfileCandidatesMap, tt.wantDockerfileCandidates) {
+				t.Errorf("ScanRepo() found the dockerfile candidates %v, want %v", dockerfileCandidatesMap, tt.wantDockerfileCandidates)
+			}
+			var devfileContexts []string
+			for context := range devfileMap {
+				devfileContexts = append(devfileContexts, context)
+			}
+			sort.Strings(devfileContexts)
+			if !reflect.DeepEqual(devfileContexts, tt.wantDevfileContext) {
+				t.Errorf("ScanRepo() detected the devfiles %v, want %v", devfileContexts, tt.wantDevfileContext)
+			}
+		})
+	}
+}
+
+// pathAlizerClient detects components in the given subdirectories, and records the directories matched with the devfile registry
+type pathAlizerClient struct {
+	MockAlizerClient
+	componentPaths []string
+	selectedPaths  *[]string
+}
+
+func (a pathAlizerClient) DetectComponents(path string) ([]recognizer.Component, error) {
+	var components []recognizer.Component
+	for _, componentPath := range a.componentPaths {
+		components = append(components, recognizer.Component{
+			Path:      filepath.Join(path, componentPath),
+			Languages: []language.Language{{Name: "Java", CanBeComponent: true}},
+		})
+	}
+	return components, nil
+}
+
+func (a pathAlizerClient) SelectDevFileFromTypes(path string, devFileTypes []recognizer.DevFileType) (recognizer.DevFileType, error) {
+	*a.selectedPaths = append(*a.selectedPaths, path)
+	return recognizer.DevFileType{}, nil
+}
+
+func TestScanRepoAlizerComponents(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"name": "java-springboot-basic", "language": "java", "projectType": "springboot", "type": "sample"}]`))
+	}))
+	defer registry.Close()
+
+	repo, err := ioutil.TempDir("", "scan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(repo)
+	for _, dir := range []string{"backend", "docs", "services/api", "services/lib/src"} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The directories Alizer did not detect as components are not matched, at the maximum depth too
+	var selectedPaths []string
+	alizer := pathAlizerClient{componentPaths: []string{"backend", "services/api"}, selectedPaths: &selectedPaths}
+	if _, _, _, _, err := ScanRepo(logr.Discard(), alizer, repo, registry.URL, ScanOptions{MaxDepth: 2}); err == nil {
+		t.Fatal("ScanRepo() wanted NoDevfileFound without sample")
+	}
+	want := []string{filepath.Join(repo, "backend"), filepath.Join(repo, "services/api")}
+	sort.Strings(selectedPaths)
+	if !reflect.DeepEqual(selectedPaths, want) {
+		t.Errorf("ScanRepo() matched the directories %v with the devfile registry, want %v", selectedPaths, want)
+	}
+}
@@ -18,6 +18,8 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/brianvoe/gofakeit/v6"
@@ -65,3 +67,143 @@ func DeleteRepository(client *github.Client, ctx context.Context, orgName string
 	}
 	return nil
 }
+
+// GetOwnerAndRepoFromURL returns the owner and the repository name from the Git repo URL
+func GetOwnerAndRepoFromURL(repoURL string) (string, string, error) {
+	parsedURL, err := url.Parse(repoURL)
+	if err != nil {
+		return "", "", err
+	}
+	parts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("error: unable to parse Git repository URL: %v", repoURL)
+	}
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), nil
+}
+
+// CreateOrUpdatePullRequest opens a pull request from the head branch to the base branch of the given repository.
+// If a pull request for the head branch is already open, its title and body are updated instead of opening a duplicate.
+func CreateOrUpdatePullRequest(client *github.Client, ctx context.Context, owner string, repoName string, head string, base string, title string, body string) (*github.PullRequest, error) {
+	openPRs, _, err := client.PullRequests.List(ctx, owner, repoName, &github.PullRequestListOptions{
+		State: "open",
+		Head:  owner + ":" + head,
+		Base:  base,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(openPRs) > 0 {
+		pr, _, err := client.PullRequests.Edit(ctx, owner, repoName, openPRs[0].GetNumber(), &github.PullRequest{Title: &title, Body: &body})
+		return pr, err
+	}
+
+	pr, _, err := client.PullRequests.Create(ctx, owner, repoName, &github.NewPullRequest{
+		Title: &title,
+		Head:  &head,
+		Base:  &base,
+		Body:  &body,
+	})
+	return pr, err
+}
+
+// GetDefaultBranch returns the default branch of the given repository
+func GetDefaultBranch(client *github.Client, ctx context.Context, owner string, repoName string) (string, error) {
+	repo, _, err := client.Repositories.Get(ctx, owner, repoName)
+	if err != nil {
+		return "", err
+	}
+	return repo.GetDefaultBranch(), nil
+}
+
+// CommitFilesToBranch commits the given files, indexed by their path, on top of the base branch of the given repository
+// and force pushes the commit to the head branch, through the GitHub API. The head branch is reset on every push, so it
+// always holds a single commit on top of the base branch, and is not pushed if it already holds the files. True is returned
+// if the head branch holds files to merge in the base branch, false if the base branch already holds the files.
+func CommitFilesToBranch(client *github.Client, ctx context.Context, owner string, repoName string, base string, head string, files map[string][]byte, message string) (bool, error) {
+	baseRef, _, err := client.Git.GetRef(ctx, owner, repoName, "refs/heads/"+base)
+	if err != nil {
+		return false, fmt.Errorf("unable to retrieve the branch %s: %v", base, err)
+	}
+	baseCommit, _, err := client.Git.GetCommit(ctx, owner, repoName, baseRef.GetObject().GetSHA())
+	if err != nil {
+		return false, fmt.Errorf("unable to retrieve the head commit of the branch %s: %v", base, err)
+	}
+
+	var entries []*github.TreeEntry
+	for filePath, content := range files {
+		entries = append(entries, &github.TreeEntry{
+			Path:    github.String(filePath),
+			Mode:    github.String("100644"),
+			Type:    github.String("blob"),
+			Content: github.String(string(content)),
+		})
+	}
+	tree, _, err := client.Git.CreateTree(ctx, owner, repoName, baseCommit.GetTree().GetSHA(), entries)
+	if err != nil {
+		return false, fmt.Errorf("unable to create the tree of the files: %v", err)
+	}
+	if tree.GetSHA() == baseCommit.GetTree().GetSHA() {
+		// The base branch already holds the files
+		return false, nil
+	}
+
+	headRef, resp, err := client.Git.GetRef(ctx, owner, repoName, "refs/heads/"+head)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return false, fmt.Errorf("unable to retrieve the branch %s: %v", head, err)
+	}
+	if err == nil {
+		headCommit, _, err := client.Git.GetCommit(ctx, owner, repoName, headRef.GetObject().GetSHA())
+		if err != nil {
+			return false, fmt.Errorf("unable to retrieve the head commit of the branch %s: %v", head, err)
+		}
+		if headCommit.GetTree().GetSHA() == tree.GetSHA() {
+			return true, nil
+		}
+	}
+
+	commit, _, err := client.Git.CreateCommit(ctx, owner, repoName, &github.Commit{
+		Message: github.String(message),
+		Tree:    tree,
+		Parents: []*github.Commit{{SHA: baseCommit.SHA}},
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to create the commit of the files: %v", err)
+	}
+
+	ref := &github.Reference{
+		Ref:    github.String("refs/heads/" + head),
+		Object: &github.GitObject{SHA: commit.SHA},
+	}
+	if headRef == nil {
+		_, _, err = client.Git.CreateRef(ctx, owner, repoName, ref)
+	} else {
+		_, _, err = client.Git.UpdateRef(ctx, owner, repoName, ref, true)
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to push the branch %s: %v", head, err)
+	}
+	return true, nil
+}
+
+// GetPullRequestState returns the state of the given pull request: open, closed or merged, and the SHA of its merge commit
+// once it is merged
+func GetPullRequestState(client *github.Client, ctx context.Context, owner string, repoName string, number int) (string, string, error) {
+	pr, _, err := client.PullRequests.Get(ctx, owner, repoName, number)
+	if err != nil {
+		return "", "", err
+	}
+	state := PullRequestState(pr)
+	if state != "merged" {
+		return state, "", nil
+	}
+	return state, pr.GetMergeCommitSHA(), nil
+}
+
+// PullRequestState returns the state of the pull request, distinguishing merged pull requests from closed ones
+func PullRequestState(pr *github.PullRequest) string {
+	if pr.GetMerged() {
+		return "merged"
+	}
+	return pr.GetState()
+}
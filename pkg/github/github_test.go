@@ -162,3 +162,200 @@ func TestGetRepoNameFromURL(t *testing.T) {
 		})
 	}
 }
+
+func TestGetOwnerAndRepoFromURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		repoURL   string
+		wantOwner string
+		wantRepo  string
+		wantErr   bool
+	}{
+		{
+			name:      "Simple repo url",
+			repoURL:   "https://github.com/redhat-appstudio-appdata/test-repo-1",
+			wantOwner: "redhat-appstudio-appdata",
+			wantRepo:  "test-repo-1",
+		},
+		{
+			name:      "Repo url with .git suffix and trailing slash",
+			repoURL:   "https://github.com/redhat-appstudio-appdata/test-repo-1.git/",
+			wantOwner: "redhat-appstudio-appdata",
+			wantRepo:  "test-repo-1",
+		},
+		{
+			name:    "Repo url without a repository",
+			repoURL: "https://github.com/redhat-appstudio-appdata",
+			wantErr: true,
+		},
+		{
+			name:    "Unparseable repo url",
+			repoURL: "dsfdsf sdfsdf sdk;;;fsd ppz mne@ddsfj#$*(%",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, repoName, err := GetOwnerAndRepoFromURL(tt.repoURL)
+
+			if tt.wantErr != (err != nil) {
+				t.Errorf("TestGetOwnerAndRepoFromURL() unexpected error value: %v", err)
+			}
+
+			if !tt.wantErr && (owner != tt.wantOwner || repoName != tt.wantRepo) {
+				t.Errorf("TestGetOwnerAndRepoFromURL() error: expected %v/%v got %v/%v", tt.wantOwner, tt.wantRepo, owner, repoName)
+			}
+		})
+	}
+}
+
+func TestCreateOrUpdatePullRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		head       string
+		title      string
+		wantNumber int
+		wantErr    bool
+	}{
+		{
+			name:       "No open pull request, a new one is opened",
+			head:       "new-pr",
+			title:      "Update GitOps resources",
+			wantNumber: 2,
+		},
+		{
+			name:       "Open pull request is updated",
+			head:       "existing-pr",
+			title:      "Update GitOps resources",
+			wantNumber: 1,
+		},
+		{
+			name:    "Pull request creation fails",
+			head:    "new-pr",
+			title:   "test-error-response",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		mockedClient := GetMockedClient()
+
+		t.Run(tt.name, func(t *testing.T) {
+			pr, err := CreateOrUpdatePullRequest(mockedClient, context.Background(), "redhat-appstudio-appdata", "test-repo-1", tt.head, "main", tt.title, "")
+
+			if tt.wantErr != (err != nil) {
+				t.Errorf("TestCreateOrUpdatePullRequest() unexpected error value: %v", err)
+			}
+			if !tt.wantErr && pr.GetNumber() != tt.wantNumber {
+				t.Errorf("TestCreateOrUpdatePullRequest() error: expected pull request %v got %v", tt.wantNumber, pr.GetNumber())
+			}
+		})
+	}
+}
+
+func TestGetPullRequestState(t *testing.T) {
+	tests := []struct {
+		name            string
+		number          int
+		want            string
+		wantMergeCommit string
+	}{
+		{
+			name:   "Open pull request",
+			number: 1,
+			want:   "open",
+		},
+		{
+			name:            "Merged pull request",
+			number:          3,
+			want:            "merged",
+			wantMergeCommit: "a4c2d1e7b3f5968a0d2c4e6f8b1a3c5d7e9f0b2d",
+		},
+	}
+
+	for _, tt := range tests {
+		mockedClient := GetMockedClient()
+
+		t.Run(tt.name, func(t *testing.T) {
+			state, mergeCommit, err := GetPullRequestState(mockedClient, context.Background(), "redhat-appstudio-appdata", "test-repo-1", tt.number)
+			if err != nil {
+				t.Errorf("TestGetPullRequestState() unexpected error: %v", err)
+			}
+			if state != tt.want {
+				t.Errorf("TestGetPullRequestState() error: expected %v got %v", tt.want, state)
+			}
+			if mergeCommit != tt.wantMergeCommit {
+				t.Errorf("TestGetPullRequestState() error: expected merge commit %v got %v", tt.wantMergeCommit, mergeCommit)
+			}
+		})
+	}
+}
+
+func TestGetDefaultBranch(t *testing.T) {
+	mockedClient := GetMockedClient()
+
+	branch, err := GetDefaultBranch(mockedClient, context.Background(), "redhat-appstudio-appdata", "test-repo-1")
+	if err != nil {
+		t.Errorf("TestGetDefaultBranch() unexpected error: %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("TestGetDefaultBranch() error: expected main got %v", branch)
+	}
+}
+
+func TestCommitFilesToBranch(t *testing.T) {
+	tests := []struct {
+		name        string
+		base        string
+		head        string
+		wantChanges bool
+		wantErr     bool
+	}{
+		{
+			name:        "New branch is created",
+			base:        "main",
+			head:        "new-branch",
+			wantChanges: true,
+		},
+		{
+			name:        "Existing branch is reset",
+			base:        "main",
+			head:        "existing-branch",
+			wantChanges: true,
+		},
+		{
+			name:        "Branch already holding the files is not pushed",
+			base:        "main",
+			head:        "up-to-date-branch",
+			wantChanges: true,
+		},
+		{
+			name: "Base branch already holding the files has no changes",
+			base: "up-to-date-branch",
+			head: "new-branch",
+		},
+		{
+			name:    "Missing base branch fails",
+			base:    "missing-branch",
+			head:    "new-branch",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		mockedClient := GetMockedClient()
+
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string][]byte{".tekton/test-component-on-push.yaml": []byte("kind: PipelineRun")}
+			hasChanges, err := CommitFilesToBranch(mockedClient, context.Background(), "redhat-appstudio-appdata", "test-repo-1", tt.base, tt.head, files, "Add PipelineRuns")
+
+			if tt.wantErr != (err != nil) {
+				t.Errorf("TestCommitFilesToBranch() unexpected error value: %v", err)
+			}
+			if hasChanges != tt.wantChanges {
+				t.Errorf("TestCommitFilesToBranch() error: expected changes %v got %v", tt.wantChanges, hasChanges)
+			}
+		})
+	}
+}
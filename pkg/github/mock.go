@@ -59,6 +59,143 @@ func GetMockedClient() *github.Client {
 				}
 			}),
 		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposPullsByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				// Only the "existing-pr" branch has an open pull request
+				if strings.HasSuffix(req.URL.Query().Get("head"), ":existing-pr") {
+					w.Write(mock.MustMarshal([]github.PullRequest{
+						{
+							Number:  github.Int(1),
+							State:   github.String("open"),
+							HTMLURL: github.String("https://github.com/redhat-appstudio-appdata/test-repo-1/pull/1"),
+						},
+					}))
+				} else {
+					w.Write(mock.MustMarshal([]github.PullRequest{}))
+				}
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposPullsByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				b, _ := ioutil.ReadAll(req.Body)
+				reqBody := string(b)
+				if strings.Contains(reqBody, "test-error-response") {
+					mock.WriteError(w,
+						http.StatusUnprocessableEntity,
+						"pull request could not be created",
+					)
+				} else {
+					w.Write(mock.MustMarshal(github.PullRequest{
+						Number:  github.Int(2),
+						State:   github.String("open"),
+						HTMLURL: github.String("https://github.com/redhat-appstudio-appdata/test-repo-1/pull/2"),
+					}))
+				}
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposPullsByOwnerByRepoByPullNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Write(mock.MustMarshal(github.PullRequest{
+					Number:  github.Int(1),
+					State:   github.String("open"),
+					HTMLURL: github.String("https://github.com/redhat-appstudio-appdata/test-repo-1/pull/1"),
+				}))
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposPullsByOwnerByRepoByPullNumber,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				// Pull request 3 has been merged, every other pull request is still open
+				if strings.HasSuffix(req.URL.Path, "/pulls/3") {
+					w.Write(mock.MustMarshal(github.PullRequest{
+						Number:         github.Int(3),
+						State:          github.String("closed"),
+						Merged:         github.Bool(true),
+						MergeCommitSHA: github.String("a4c2d1e7b3f5968a0d2c4e6f8b1a3c5d7e9f0b2d"),
+					}))
+				} else {
+					w.Write(mock.MustMarshal(github.PullRequest{
+						Number: github.Int(1),
+						State:  github.String("open"),
+					}))
+				}
+			}),
+		),
+		mock.WithRequestMatch(
+			mock.GetReposByOwnerByRepo,
+			github.Repository{
+				Name:          github.String("test-repo-1"),
+				DefaultBranch: github.String("main"),
+			},
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposGitRefByOwnerByRepoByRef,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				// The "main" and "existing-branch" branches exist, and "up-to-date-branch" and the onboarding branch of the
+				// "pushed-component" component already hold the pushed files
+				heads := map[string]string{
+					"main":                       "base-sha",
+					"existing-branch":            "head-sha",
+					"up-to-date-branch":          "up-to-date-sha",
+					"appstudio-pushed-component": "up-to-date-sha",
+				}
+				for head, sha := range heads {
+					if strings.HasSuffix(req.URL.Path, "/heads/"+head) {
+						w.Write(mock.MustMarshal(github.Reference{
+							Ref:    github.String("refs/heads/" + head),
+							Object: &github.GitObject{SHA: github.String(sha)},
+						}))
+						return
+					}
+				}
+				mock.WriteError(w,
+					http.StatusNotFound,
+					"Not Found",
+				)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposGitCommitsByOwnerByRepoByCommitSha,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				trees := map[string]string{
+					"base-sha":       "base-tree",
+					"head-sha":       "old-tree",
+					"up-to-date-sha": "new-tree",
+				}
+				sha := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+				w.Write(mock.MustMarshal(github.Commit{
+					SHA:  github.String(sha),
+					Tree: &github.Tree{SHA: github.String(trees[sha])},
+				}))
+			}),
+		),
+		mock.WithRequestMatch(
+			mock.PostReposGitTreesByOwnerByRepo,
+			github.Tree{
+				SHA: github.String("new-tree"),
+			},
+		),
+		mock.WithRequestMatch(
+			mock.PostReposGitCommitsByOwnerByRepo,
+			github.Commit{
+				SHA: github.String("new-sha"),
+			},
+		),
+		mock.WithRequestMatch(
+			mock.PostReposGitRefsByOwnerByRepo,
+			github.Reference{
+				Object: &github.GitObject{SHA: github.String("new-sha")},
+			},
+		),
+		mock.WithRequestMatch(
+			mock.PatchReposGitRefsByOwnerByRepoByRef,
+			github.Reference{
+				Object: &github.GitObject{SHA: github.String("new-sha")},
+			},
+		),
 		mock.WithRequestMatchHandler(
 			mock.DeleteReposByOwnerByRepo,
 			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
@@ -0,0 +1,145 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageregistry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	dockerHubHost         = "docker.io"
+	dockerHubRegistryHost = "registry-1.docker.io"
+)
+
+var bearerChallengeParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// DigestResolver resolves the digests of the images, pulling them anonymously
+type DigestResolver struct {
+	HTTPClient *http.Client
+}
+
+// NewDigestResolver returns a resolver of image digests sending its requests with a timeout
+func NewDigestResolver() *DigestResolver {
+	return &DigestResolver{HTTPClient: newHTTPClient()}
+}
+
+// ResolveDigest returns the digest of the manifest of the image reference. The digest of references pinned by digest
+// is returned as is, the digest of the tags is resolved with the OCI Distribution API of their registry.
+func (d *DigestResolver) ResolveDigest(ctx context.Context, reference string) (string, error) {
+	if index := strings.Index(reference, "@"); index >= 0 {
+		return reference[index+1:], nil
+	}
+	host, repository, tag := parseImageReference(reference)
+
+	manifestURL := "https://" + host + "/v2/" + repository + "/manifests/" + tag
+	response, err := d.headManifest(ctx, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode == http.StatusUnauthorized {
+		// Anonymous pulls still need a token of the registry's authorization service
+		token, err := d.getAnonymousToken(ctx, response.Header.Get("WWW-Authenticate"), repository)
+		if err != nil {
+			return "", err
+		}
+		if response, err = d.headManifest(ctx, manifestURL, token); err != nil {
+			return "", err
+		}
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to resolve the digest of %s: unexpected status %d", reference, response.StatusCode)
+	}
+	digest := response.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("unable to resolve the digest of %s: the registry did not return it", reference)
+	}
+	return digest, nil
+}
+
+func (d *DigestResolver) headManifest(ctx context.Context, manifestURL string, token string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := d.HTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+	return response, nil
+}
+
+// getAnonymousToken requests a token allowed to pull the repository from the authorization service of the Bearer challenge
+func (d *DigestResolver) getAnonymousToken(ctx context.Context, challenge string, repository string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	params := make(map[string]string)
+	for _, match := range bearerChallengeParamRegexp.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	if params["realm"] == "" {
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+
+	query := url.Values{}
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	query.Set("scope", "repository:"+repository+":pull")
+	tokenResponse := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if _, err := doRequest(ctx, d.HTTPClient, http.MethodGet, params["realm"]+"?"+query.Encode(), func(*http.Request) {}, nil, &tokenResponse, http.StatusOK); err != nil {
+		return "", fmt.Errorf("unable to authenticate to the image registry: %v", err)
+	}
+	if tokenResponse.Token != "" {
+		return tokenResponse.Token, nil
+	}
+	return tokenResponse.AccessToken, nil
+}
+
+// parseImageReference splits the image reference in the host of its registry, its repository and its tag.
+// The references without registry refer to Docker Hub, the references without tag to the latest tag.
+func parseImageReference(reference string) (string, string, string) {
+	host, repository := dockerHubHost, reference
+	if index := strings.Index(reference, "/"); index >= 0 {
+		if candidate := reference[:index]; strings.ContainsAny(candidate, ".:") || candidate == "localhost" {
+			host, repository = candidate, reference[index+1:]
+		}
+	}
+	tag := "latest"
+	if index := strings.LastIndex(repository, ":"); index >= 0 {
+		repository, tag = repository[:index], repository[index+1:]
+	}
+	if host == dockerHubHost {
+		host = dockerHubRegistryHost
+		if !strings.Contains(repository, "/") {
+			repository = "library/" + repository
+		}
+	}
+	return host, repository, tag
+}
@@ -0,0 +1,115 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageregistry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolveDigest(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.URL.Query().Get("scope") != "repository:org/bundle:pull" || r.URL.Query().Get("service") != "registry" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token": "anonymous"}`))
+		case r.URL.Path == "/v2/org/bundle/manifests/v1" && r.Method == http.MethodHead:
+			if r.Header.Get("Authorization") != "Bearer anonymous" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry",scope="repository:org/bundle:pull"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:abc")
+		case r.URL.Path == "/v2/org/public/manifests/latest" && r.Method == http.MethodHead:
+			w.Header().Set("Docker-Content-Digest", "sha256:def")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+	resolver := &DigestResolver{HTTPClient: server.Client()}
+
+	tests := []struct {
+		name      string
+		reference string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "tag of a registry requiring a token",
+			reference: host + "/org/bundle:v1",
+			want:      "sha256:abc",
+		},
+		{
+			name:      "default tag of a public registry",
+			reference: host + "/org/public",
+			want:      "sha256:def",
+		},
+		{
+			name:      "reference pinned by digest",
+			reference: "quay.io/org/bundle:v1@sha256:123",
+			want:      "sha256:123",
+		},
+		{
+			name:      "missing tag",
+			reference: host + "/org/bundle:v2",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.ResolveDigest(context.Background(), tt.reference)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveDigest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveDigest() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		reference      string
+		wantHost       string
+		wantRepository string
+		wantTag        string
+	}{
+		{reference: "quay.io/org/bundle:v1", wantHost: "quay.io", wantRepository: "org/bundle", wantTag: "v1"},
+		{reference: "localhost:5000/bundle", wantHost: "localhost:5000", wantRepository: "bundle", wantTag: "latest"},
+		{reference: "org/bundle:v1", wantHost: "registry-1.docker.io", wantRepository: "org/bundle", wantTag: "v1"},
+		{reference: "bundle", wantHost: "registry-1.docker.io", wantRepository: "library/bundle", wantTag: "latest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			host, repository, tag := parseImageReference(tt.reference)
+			if host != tt.wantHost || repository != tt.wantRepository || tag != tt.wantTag {
+				t.Errorf("parseImageReference() = %s %s %s, want %s %s %s", host, repository, tag, tt.wantHost, tt.wantRepository, tt.wantTag)
+			}
+		})
+	}
+}
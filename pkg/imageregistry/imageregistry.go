@@ -0,0 +1,160 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageregistry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// QuayProvider provisions the repositories and robot accounts with the API of Quay
+	QuayProvider = "quay"
+	// OCIProvider targets any registry implementing the OCI Distribution API
+	OCIProvider = "oci"
+
+	// ComponentScope provisions a repository per Component
+	ComponentScope = "component"
+	// ApplicationScope provisions a repository per Application, shared by its Components
+	ApplicationScope = "application"
+
+	// requestTimeout is the timeout of the requests sent to the image registries, so that an unresponsive registry
+	// does not block the reconciles
+	requestTimeout = 30 * time.Second
+)
+
+// ImageRegistry provisions the image repositories the Components are built into, and the credentials pushing to them
+type ImageRegistry interface {
+	// EnsureRepository creates the repository with the given name if it does not exist, and returns its URL
+	EnsureRepository(ctx context.Context, name string) (string, error)
+
+	// DeleteRepository deletes the repository with the given name and its images. Missing repositories are ignored.
+	DeleteRepository(ctx context.Context, name string) error
+
+	// EnsurePushCredentials returns the credentials allowed to push to the repository with the given name,
+	// creating them if needed
+	EnsurePushCredentials(ctx context.Context, name string) (Credentials, error)
+
+	// DeletePushCredentials revokes the credentials pushing to the repository with the given name
+	DeletePushCredentials(ctx context.Context, name string) error
+}
+
+// Credentials authenticate to an image registry
+type Credentials struct {
+	Registry string
+	Username string
+	Password string
+}
+
+// DockerConfigJSON returns the content of a kubernetes.io/dockerconfigjson Secret holding the credentials
+func (c Credentials) DockerConfigJSON() ([]byte, error) {
+	type authConfig struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	}
+	return json.Marshal(map[string]map[string]authConfig{
+		"auths": {
+			c.Registry: {
+				Username: c.Username,
+				Password: c.Password,
+				Auth:     base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.Password)),
+			},
+		},
+	})
+}
+
+// NewImageRegistry returns the image registry of the given provider, at the given URL. The repositories are created under
+// the given namespace, the organization for Quay, with the given credentials. Quay only needs the token of an application
+// allowed to administer the organization.
+func NewImageRegistry(provider string, registryURL string, namespace string, username string, password string) (ImageRegistry, error) {
+	parsedURL, err := url.Parse(registryURL)
+	if err != nil || parsedURL.Host == "" {
+		return nil, fmt.Errorf("invalid image registry URL %q", registryURL)
+	}
+	switch provider {
+	case QuayProvider:
+		if namespace == "" {
+			return nil, fmt.Errorf("the Quay organization of the image repositories is required")
+		}
+		return NewQuayRegistry(registryURL, namespace, password), nil
+	case OCIProvider:
+		return NewOCIRegistry(registryURL, namespace, username, password), nil
+	}
+	return nil, fmt.Errorf("unsupported image registry provider %q, expected %s or %s", provider, QuayProvider, OCIProvider)
+}
+
+// GetRepositoryName returns the name of the repository provisioned in the namespace for the Component, or for its
+// Application with the application scope
+func GetRepositoryName(scope string, namespace string, application string, component string) string {
+	if scope == ApplicationScope {
+		return namespace + "-" + application
+	}
+	return namespace + "-" + component
+}
+
+// newHTTPClient returns the HTTP client sending the requests to the image registries
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: requestTimeout}
+}
+
+// doRequest sends a request with the JSON encoded body, if any, and decodes the JSON response into the result, if any.
+// The status of the response is returned, unless it is not one of the expected statuses.
+func doRequest(ctx context.Context, client *http.Client, method string, requestURL string, authorize func(*http.Request), body interface{}, result interface{}, expectedStatuses ...int) (int, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		bodyReader = strings.NewReader(string(content))
+	}
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, bodyReader)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	authorize(request)
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	for _, status := range expectedStatuses {
+		if response.StatusCode != status {
+			continue
+		}
+		if result != nil && response.StatusCode < http.StatusMultipleChoices {
+			if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+				return response.StatusCode, fmt.Errorf("unable to decode the response of %s %s: %v", method, requestURL, err)
+			}
+		}
+		return response.StatusCode, nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return response.StatusCode, fmt.Errorf("unexpected status %d of %s %s: %s", response.StatusCode, method, requestURL, strings.TrimSpace(string(message)))
+}
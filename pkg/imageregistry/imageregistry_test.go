@@ -0,0 +1,96 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewImageRegistry(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  string
+		url       string
+		namespace string
+		want      ImageRegistry
+		wantErr   bool
+	}{
+		{
+			name:      "Quay registry",
+			provider:  QuayProvider,
+			url:       "https://quay.io",
+			namespace: "org",
+			want:      NewQuayRegistry("https://quay.io", "org", "password"),
+		},
+		{
+			name:     "Quay registry without organization",
+			provider: QuayProvider,
+			url:      "https://quay.io",
+			wantErr:  true,
+		},
+		{
+			name:     "OCI registry",
+			provider: OCIProvider,
+			url:      "https://registry.example.com:5000",
+			want:     NewOCIRegistry("https://registry.example.com:5000", "", "user", "password"),
+		},
+		{
+			name:     "Relative URL",
+			provider: OCIProvider,
+			url:      "registry.example.com",
+			wantErr:  true,
+		},
+		{
+			name:     "Unknown provider",
+			provider: "docker-hub",
+			url:      "https://registry.example.com",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewImageRegistry(tt.provider, tt.url, tt.namespace, "user", "password")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewImageRegistry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetRepositoryName(t *testing.T) {
+	assert.Equal(t, "user-ns-component", GetRepositoryName(ComponentScope, "user-ns", "application", "component"))
+	assert.Equal(t, "user-ns-component", GetRepositoryName("", "user-ns", "application", "component"))
+	assert.Equal(t, "user-ns-application", GetRepositoryName(ApplicationScope, "user-ns", "application", "component"))
+}
+
+func TestDockerConfigJSON(t *testing.T) {
+	credentials := Credentials{Registry: "quay.io", Username: "org+robot", Password: "token"}
+	got, err := credentials.DockerConfigJSON()
+	if err != nil {
+		t.Fatalf("DockerConfigJSON() unexpected error: %v", err)
+	}
+	assert.JSONEq(t, `{"auths": {"quay.io": {"username": "org+robot", "password": "token", "auth": "b3JnK3JvYm90OnRva2Vu"}}}`, string(got))
+}
+
+func TestHTTPClientTimeout(t *testing.T) {
+	assert.Equal(t, requestTimeout, NewQuayRegistry("https://quay.io", "org", "token").HTTPClient.Timeout)
+	assert.Equal(t, requestTimeout, NewOCIRegistry("https://registry.example.com", "", "user", "password").HTTPClient.Timeout)
+	assert.Equal(t, requestTimeout, NewDigestResolver().HTTPClient.Timeout)
+}
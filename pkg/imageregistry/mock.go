@@ -0,0 +1,85 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageregistry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MockRegistryHost is the host of the repositories of the mock image registry
+const MockRegistryHost = "registry.local"
+
+// MockImageRegistry is an in memory image registry recording the provisioned repositories and push credentials.
+// Repositories whose name contains "test-error-response" fail to be provisioned.
+type MockImageRegistry struct {
+	mutex        sync.Mutex
+	Repositories map[string]bool
+	Credentials  map[string]Credentials
+}
+
+// NewMockImageRegistry returns an empty mock image registry
+func NewMockImageRegistry() *MockImageRegistry {
+	return &MockImageRegistry{
+		Repositories: make(map[string]bool),
+		Credentials:  make(map[string]Credentials),
+	}
+}
+
+// EnsureRepository records the repository and returns its URL
+func (m *MockImageRegistry) EnsureRepository(ctx context.Context, name string) (string, error) {
+	if strings.Contains(name, "test-error-response") {
+		return "", fmt.Errorf("unable to create the image repository %s", name)
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.Repositories[name] = true
+	return MockRegistryHost + "/" + name, nil
+}
+
+// DeleteRepository forgets the repository
+func (m *MockImageRegistry) DeleteRepository(ctx context.Context, name string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.Repositories, name)
+	return nil
+}
+
+// EnsurePushCredentials records and returns credentials named after the repository
+func (m *MockImageRegistry) EnsurePushCredentials(ctx context.Context, name string) (Credentials, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if credentials, ok := m.Credentials[name]; ok {
+		return credentials, nil
+	}
+	credentials := Credentials{
+		Registry: MockRegistryHost,
+		Username: "push_" + name,
+		Password: "token_" + name,
+	}
+	m.Credentials[name] = credentials
+	return credentials, nil
+}
+
+// DeletePushCredentials forgets the credentials of the repository
+func (m *MockImageRegistry) DeletePushCredentials(ctx context.Context, name string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.Credentials, name)
+	return nil
+}
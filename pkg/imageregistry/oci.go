@@ -0,0 +1,158 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageregistry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// manifestMediaTypes are the media types of the manifests accepted when resolving the digests of the tags
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+}
+
+// OCIRegistry targets a registry implementing the OCI Distribution API, authenticated with basic authentication.
+// The API has no repository creation nor scoped accounts: the repositories are created by the first push, and the
+// configured credentials are the push credentials of all the repositories.
+type OCIRegistry struct {
+	// URL is the URL of the registry, e.g. https://registry.example.com
+	URL string
+	// Host is the host of the images of the registry
+	Host string
+	// Namespace is the path the repositories are created under, if any
+	Namespace string
+
+	Username string
+	Password string
+
+	HTTPClient *http.Client
+}
+
+type ociTagList struct {
+	Tags []string `json:"tags"`
+}
+
+// NewOCIRegistry returns the OCI Distribution registry at the given URL, pushed to with the given credentials
+func NewOCIRegistry(registryURL string, namespace string, username string, password string) *OCIRegistry {
+	registryURL = strings.TrimSuffix(registryURL, "/")
+	parsedURL, _ := url.Parse(registryURL)
+	return &OCIRegistry{
+		URL:        registryURL,
+		Host:       parsedURL.Host,
+		Namespace:  strings.Trim(namespace, "/"),
+		Username:   username,
+		Password:   password,
+		HTTPClient: newHTTPClient(),
+	}
+}
+
+// EnsureRepository checks that the registry accepts the credentials, and returns the URL of the repository
+func (o *OCIRegistry) EnsureRepository(ctx context.Context, name string) (string, error) {
+	if _, err := o.do(ctx, http.MethodGet, "/v2/", nil, http.StatusOK); err != nil {
+		return "", fmt.Errorf("unable to access the image registry %s: %v", o.URL, err)
+	}
+	return o.Host + "/" + o.getRepositoryPath(name), nil
+}
+
+// DeleteRepository deletes the manifests of all the tags of the repository
+func (o *OCIRegistry) DeleteRepository(ctx context.Context, name string) error {
+	repositoryPath := "/v2/" + o.getRepositoryPath(name)
+	tagList := ociTagList{}
+	status, err := o.do(ctx, http.MethodGet, repositoryPath+"/tags/list", &tagList, http.StatusOK, http.StatusNotFound)
+	if err != nil {
+		return fmt.Errorf("unable to list the tags of the image repository %s: %v", name, err)
+	}
+	if status == http.StatusNotFound {
+		return nil
+	}
+
+	deletedDigests := make(map[string]bool)
+	for _, tag := range tagList.Tags {
+		digest, err := o.getManifestDigest(ctx, repositoryPath, tag)
+		if err != nil {
+			return err
+		}
+		if digest == "" || deletedDigests[digest] {
+			continue
+		}
+		if _, err := o.do(ctx, http.MethodDelete, repositoryPath+"/manifests/"+digest, nil, http.StatusAccepted, http.StatusOK, http.StatusNotFound); err != nil {
+			return fmt.Errorf("unable to delete the image %s of the image repository %s: %v", digest, name, err)
+		}
+		deletedDigests[digest] = true
+	}
+	return nil
+}
+
+// EnsurePushCredentials returns the configured credentials of the registry
+func (o *OCIRegistry) EnsurePushCredentials(ctx context.Context, name string) (Credentials, error) {
+	return Credentials{
+		Registry: o.Host,
+		Username: o.Username,
+		Password: o.Password,
+	}, nil
+}
+
+// DeletePushCredentials does nothing, as the configured credentials are shared by all the repositories
+func (o *OCIRegistry) DeletePushCredentials(ctx context.Context, name string) error {
+	return nil
+}
+
+// getManifestDigest returns the digest of the manifest of the tag, or an empty string if the tag does not exist anymore
+func (o *OCIRegistry) getManifestDigest(ctx context.Context, repositoryPath string, tag string) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, o.URL+repositoryPath+"/manifests/"+tag, nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	o.authorize(request)
+
+	response, err := o.HTTPClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusOK:
+		return response.Header.Get("Docker-Content-Digest"), nil
+	case http.StatusNotFound:
+		return "", nil
+	}
+	return "", fmt.Errorf("unable to resolve the digest of the tag %s: unexpected status %d", tag, response.StatusCode)
+}
+
+func (o *OCIRegistry) getRepositoryPath(name string) string {
+	if o.Namespace == "" {
+		return name
+	}
+	return o.Namespace + "/" + name
+}
+
+func (o *OCIRegistry) authorize(request *http.Request) {
+	if o.Username != "" || o.Password != "" {
+		request.SetBasicAuth(o.Username, o.Password)
+	}
+}
+
+func (o *OCIRegistry) do(ctx context.Context, method string, path string, result interface{}, expectedStatuses ...int) (int, error) {
+	return doRequest(ctx, o.HTTPClient, method, o.URL+path, o.authorize, nil, result, expectedStatuses...)
+}
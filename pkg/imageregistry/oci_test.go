@@ -0,0 +1,155 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageregistry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// ociStandIn serves the parts of the OCI Distribution API used by OCIRegistry, for the user "user" and the password "password".
+// The repositories are indexed by name, and map their tags to the digests of their manifests.
+type ociStandIn struct {
+	mutex        sync.Mutex
+	repositories map[string]map[string]string
+}
+
+func newOCIStandIn(repositories map[string]map[string]string) (*ociStandIn, *httptest.Server) {
+	standIn := &ociStandIn{repositories: repositories}
+	return standIn, httptest.NewServer(standIn)
+}
+
+func (s *ociStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "password" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.URL.Path == "/v2/" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	switch {
+	case strings.HasSuffix(path, "/tags/list"):
+		tags, ok := s.repositories[strings.TrimSuffix(path, "/tags/list")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		tagList := ociTagList{}
+		for tag := range tags {
+			tagList.Tags = append(tagList.Tags, tag)
+		}
+		_ = json.NewEncoder(w).Encode(tagList)
+	case strings.Contains(path, "/manifests/"):
+		parts := strings.SplitN(path, "/manifests/", 2)
+		tags := s.repositories[parts[0]]
+		if r.Method == http.MethodHead {
+			digest, ok := tags[parts[1]]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", digest)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			found := false
+			for tag, digest := range tags {
+				if digest == parts[1] {
+					delete(tags, tag)
+					found = true
+				}
+			}
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestOCIRegistry(t *testing.T) {
+	standIn, server := newOCIStandIn(map[string]map[string]string{
+		"appstudio/user-ns-component": {
+			"latest-abc": "sha256:1",
+			"v1.0.0":     "sha256:1",
+			"latest-def": "sha256:2",
+		},
+		"appstudio/other-component": {
+			"latest-abc": "sha256:3",
+		},
+	})
+	defer server.Close()
+	registry := NewOCIRegistry(server.URL, "/appstudio/", "user", "password")
+	host := strings.TrimPrefix(server.URL, "http://")
+	ctx := context.Background()
+
+	repositoryURL, err := registry.EnsureRepository(ctx, "user-ns-component")
+	if err != nil {
+		t.Fatalf("EnsureRepository() unexpected error: %v", err)
+	}
+	if repositoryURL != host+"/appstudio/user-ns-component" {
+		t.Errorf("EnsureRepository() = %s, want %s", repositoryURL, host+"/appstudio/user-ns-component")
+	}
+
+	credentials, err := registry.EnsurePushCredentials(ctx, "user-ns-component")
+	if err != nil {
+		t.Fatalf("EnsurePushCredentials() unexpected error: %v", err)
+	}
+	if want := (Credentials{Registry: host, Username: "user", Password: "password"}); credentials != want {
+		t.Errorf("EnsurePushCredentials() = %#v, want %#v", credentials, want)
+	}
+
+	if err := registry.DeleteRepository(ctx, "user-ns-component"); err != nil {
+		t.Fatalf("DeleteRepository() unexpected error: %v", err)
+	}
+	if tags := standIn.repositories["appstudio/user-ns-component"]; len(tags) != 0 {
+		t.Errorf("DeleteRepository() did not delete the tags %v", tags)
+	}
+	if tags := standIn.repositories["appstudio/other-component"]; len(tags) != 1 {
+		t.Errorf("DeleteRepository() deleted the tags of another repository")
+	}
+	if err := registry.DeleteRepository(ctx, "missing-component"); err != nil {
+		t.Errorf("DeleteRepository() unexpected error on a missing repository: %v", err)
+	}
+}
+
+func TestOCIRegistryUnauthorized(t *testing.T) {
+	_, server := newOCIStandIn(map[string]map[string]string{})
+	defer server.Close()
+	registry := NewOCIRegistry(server.URL, "", "user", "wrong-password")
+
+	if _, err := registry.EnsureRepository(context.Background(), "user-ns-component"); err == nil {
+		t.Errorf("EnsureRepository() did not fail with a wrong password")
+	}
+	if err := registry.DeleteRepository(context.Background(), "user-ns-component"); err == nil {
+		t.Errorf("DeleteRepository() did not fail with a wrong password")
+	}
+}
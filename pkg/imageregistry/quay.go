@@ -0,0 +1,137 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageregistry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var quayRobotNameRegexp = regexp.MustCompile(`[^a-z0-9_]`)
+
+// QuayRegistry provisions private repositories in a Quay organization, pushed to by a robot account per repository
+type QuayRegistry struct {
+	// APIURL is the URL of the Quay API, e.g. https://quay.io/api/v1
+	APIURL string
+	// Host is the host of the images of the registry, e.g. quay.io
+	Host string
+	// Organization is the organization the repositories and robot accounts are created in
+	Organization string
+	// Token is the OAuth token of an application allowed to administer the organization
+	Token string
+
+	HTTPClient *http.Client
+}
+
+type quayRobot struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+}
+
+// NewQuayRegistry returns the Quay registry at the given URL, provisioning the repositories in the organization with the token
+func NewQuayRegistry(registryURL string, organization string, token string) *QuayRegistry {
+	registryURL = strings.TrimSuffix(registryURL, "/")
+	parsedURL, _ := url.Parse(registryURL)
+	return &QuayRegistry{
+		APIURL:       registryURL + "/api/v1",
+		Host:         parsedURL.Host,
+		Organization: organization,
+		Token:        token,
+		HTTPClient:   newHTTPClient(),
+	}
+}
+
+// EnsureRepository creates the private repository in the organization if it does not exist, and returns its URL
+func (q *QuayRegistry) EnsureRepository(ctx context.Context, name string) (string, error) {
+	status, err := q.do(ctx, http.MethodGet, "/repository/"+q.Organization+"/"+name, nil, nil, http.StatusOK, http.StatusNotFound)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve the image repository %s: %v", name, err)
+	}
+	if status == http.StatusNotFound {
+		repository := map[string]string{
+			"namespace":   q.Organization,
+			"repository":  name,
+			"visibility":  "private",
+			"description": "Images built by AppStudio",
+			"repo_kind":   "image",
+		}
+		if _, err := q.do(ctx, http.MethodPost, "/repository", repository, nil, http.StatusCreated, http.StatusOK); err != nil {
+			return "", fmt.Errorf("unable to create the image repository %s: %v", name, err)
+		}
+	}
+	return q.Host + "/" + q.Organization + "/" + name, nil
+}
+
+// DeleteRepository deletes the repository and its images
+func (q *QuayRegistry) DeleteRepository(ctx context.Context, name string) error {
+	if _, err := q.do(ctx, http.MethodDelete, "/repository/"+q.Organization+"/"+name, nil, nil, http.StatusNoContent, http.StatusOK, http.StatusNotFound); err != nil {
+		return fmt.Errorf("unable to delete the image repository %s: %v", name, err)
+	}
+	return nil
+}
+
+// EnsurePushCredentials returns the credentials of the robot account of the repository, creating it if needed, after
+// granting it the write permission on the repository
+func (q *QuayRegistry) EnsurePushCredentials(ctx context.Context, name string) (Credentials, error) {
+	robotPath := "/organization/" + q.Organization + "/robots/" + GetQuayRobotName(name)
+	robot := quayRobot{}
+	status, err := q.do(ctx, http.MethodGet, robotPath, nil, &robot, http.StatusOK, http.StatusNotFound, http.StatusBadRequest)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("unable to retrieve the robot account of the image repository %s: %v", name, err)
+	}
+	if status != http.StatusOK {
+		description := map[string]string{"description": "Pushes the images built by AppStudio to " + name}
+		if _, err := q.do(ctx, http.MethodPut, robotPath, description, &robot, http.StatusCreated, http.StatusOK); err != nil {
+			return Credentials{}, fmt.Errorf("unable to create the robot account of the image repository %s: %v", name, err)
+		}
+	}
+
+	permissionPath := "/repository/" + q.Organization + "/" + name + "/permissions/user/" + robot.Name
+	if _, err := q.do(ctx, http.MethodPut, permissionPath, map[string]string{"role": "write"}, nil, http.StatusOK); err != nil {
+		return Credentials{}, fmt.Errorf("unable to allow the robot account %s to push to the image repository %s: %v", robot.Name, name, err)
+	}
+
+	return Credentials{
+		Registry: q.Host,
+		Username: robot.Name,
+		Password: robot.Token,
+	}, nil
+}
+
+// DeletePushCredentials deletes the robot account of the repository
+func (q *QuayRegistry) DeletePushCredentials(ctx context.Context, name string) error {
+	robotPath := "/organization/" + q.Organization + "/robots/" + GetQuayRobotName(name)
+	if _, err := q.do(ctx, http.MethodDelete, robotPath, nil, nil, http.StatusNoContent, http.StatusOK, http.StatusNotFound, http.StatusBadRequest); err != nil {
+		return fmt.Errorf("unable to delete the robot account of the image repository %s: %v", name, err)
+	}
+	return nil
+}
+
+// GetQuayRobotName returns the short name of the robot account pushing to the repository. Quay robot names only
+// contain lower case letters, digits and underscores, and start with a letter.
+func GetQuayRobotName(repository string) string {
+	return "push_" + quayRobotNameRegexp.ReplaceAllString(strings.ToLower(repository), "_")
+}
+
+func (q *QuayRegistry) do(ctx context.Context, method string, path string, body interface{}, result interface{}, expectedStatuses ...int) (int, error) {
+	return doRequest(ctx, q.HTTPClient, method, q.APIURL+path, func(request *http.Request) {
+		request.Header.Set("Authorization", "Bearer "+q.Token)
+	}, body, result, expectedStatuses...)
+}
@@ -0,0 +1,191 @@
+//
+// Copyright 2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageregistry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// quayStandIn serves the parts of the Quay API used by QuayRegistry, for the organization "org" and the token "token"
+type quayStandIn struct {
+	mutex        sync.Mutex
+	repositories map[string]bool
+	robots       map[string]string
+	permissions  map[string]string
+}
+
+func newQuayStandIn() (*quayStandIn, *httptest.Server) {
+	standIn := &quayStandIn{
+		repositories: make(map[string]bool),
+		robots:       make(map[string]string),
+		permissions:  make(map[string]string),
+	}
+	return standIn, httptest.NewServer(standIn)
+}
+
+func (s *quayStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/"), "/")
+	switch {
+	case len(path) == 1 && path[0] == "repository" && r.Method == http.MethodPost:
+		repository := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&repository)
+		if repository["namespace"] != "org" || repository["visibility"] != "private" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.repositories[repository["repository"]] = true
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"namespace": "org", "name": "` + repository["repository"] + `", "kind": "image"}`))
+	case len(path) == 3 && path[0] == "repository" && path[1] == "org":
+		name := path[2]
+		if !s.repositories[name] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(s.repositories, name)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(`{"namespace": "org", "name": "` + name + `"}`))
+	case len(path) == 6 && path[0] == "repository" && path[1] == "org" && path[3] == "permissions" && r.Method == http.MethodPut:
+		if !s.repositories[path[2]] || s.robots[strings.TrimPrefix(path[5], "org+")] == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		permission := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&permission)
+		s.permissions[path[2]+"/"+path[5]] = permission["role"]
+		_, _ = w.Write([]byte(`{"role": "` + permission["role"] + `"}`))
+	case len(path) == 4 && path[0] == "organization" && path[1] == "org" && path[2] == "robots":
+		name := path[3]
+		switch r.Method {
+		case http.MethodPut:
+			s.robots[name] = "robot-token-" + name
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			if s.robots[name] == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			delete(s.robots, name)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			if s.robots[name] == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"message": "Could not find robot with specified username"}`))
+				return
+			}
+		}
+		_, _ = w.Write([]byte(`{"name": "org+` + name + `", "token": "` + s.robots[name] + `"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestQuayRegistry(t *testing.T) {
+	standIn, server := newQuayStandIn()
+	defer server.Close()
+	registry := NewQuayRegistry(server.URL+"/", "org", "token")
+	host := strings.TrimPrefix(server.URL, "http://")
+	ctx := context.Background()
+
+	// The repository is created once
+	for i := 0; i < 2; i++ {
+		repositoryURL, err := registry.EnsureRepository(ctx, "user-ns-component")
+		if err != nil {
+			t.Fatalf("EnsureRepository() unexpected error: %v", err)
+		}
+		if repositoryURL != host+"/org/user-ns-component" {
+			t.Errorf("EnsureRepository() = %s, want %s", repositoryURL, host+"/org/user-ns-component")
+		}
+	}
+	if !standIn.repositories["user-ns-component"] {
+		t.Errorf("EnsureRepository() did not create the repository")
+	}
+
+	// The robot account is created once, and allowed to push to the repository
+	for i := 0; i < 2; i++ {
+		credentials, err := registry.EnsurePushCredentials(ctx, "user-ns-component")
+		if err != nil {
+			t.Fatalf("EnsurePushCredentials() unexpected error: %v", err)
+		}
+		want := Credentials{Registry: host, Username: "org+push_user_ns_component", Password: "robot-token-push_user_ns_component"}
+		if credentials != want {
+			t.Errorf("EnsurePushCredentials() = %#v, want %#v", credentials, want)
+		}
+	}
+	if role := standIn.permissions["user-ns-component/org+push_user_ns_component"]; role != "write" {
+		t.Errorf("EnsurePushCredentials() granted the role %q to the robot account, want write", role)
+	}
+
+	// The deletions are idempotent
+	for i := 0; i < 2; i++ {
+		if err := registry.DeletePushCredentials(ctx, "user-ns-component"); err != nil {
+			t.Errorf("DeletePushCredentials() unexpected error: %v", err)
+		}
+		if err := registry.DeleteRepository(ctx, "user-ns-component"); err != nil {
+			t.Errorf("DeleteRepository() unexpected error: %v", err)
+		}
+	}
+	if len(standIn.repositories) != 0 || len(standIn.robots) != 0 {
+		t.Errorf("The repository and the robot account were not deleted: %v %v", standIn.repositories, standIn.robots)
+	}
+}
+
+func TestQuayRegistryUnauthorized(t *testing.T) {
+	_, server := newQuayStandIn()
+	defer server.Close()
+	registry := NewQuayRegistry(server.URL, "org", "wrong-token")
+
+	if _, err := registry.EnsureRepository(context.Background(), "user-ns-component"); err == nil {
+		t.Errorf("EnsureRepository() did not fail with a wrong token")
+	}
+	if _, err := registry.EnsurePushCredentials(context.Background(), "user-ns-component"); err == nil {
+		t.Errorf("EnsurePushCredentials() did not fail with a wrong token")
+	}
+}
+
+func TestGetQuayRobotName(t *testing.T) {
+	tests := []struct {
+		repository string
+		want       string
+	}{
+		{repository: "user-ns-component", want: "push_user_ns_component"},
+		{repository: "user.ns-My_Component", want: "push_user_ns_my_component"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repository, func(t *testing.T) {
+			if got := GetQuayRobotName(tt.repository); got != tt.want {
+				t.Errorf("GetQuayRobotName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
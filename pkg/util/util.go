@@ -16,6 +16,7 @@
 package util
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
@@ -29,6 +30,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	transportHttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
 )
@@ -144,11 +148,16 @@ func CurlEndpoint(endpoint string) ([]byte, error) {
 	return nil, fmt.Errorf("received a non-200 status when curling %s", endpoint)
 }
 
-// CloneRepo clones the repoURL to clonePath
-func CloneRepo(clonePath, repoURL string, token string) error {
+// CloneRepo clones the revision of the repoURL to clonePath. The default branch is cloned if the revision is empty.
+// Branches, tags and other references, such as refs/pull/1/head, are cloned shallowly, other revisions, such as commits,
+// are checked out from a full clone.
+func CloneRepo(clonePath, repoURL string, revision string, token string) error {
 	// Set up the Clone options
 	cloneOpts := &git.CloneOptions{
-		URL: repoURL,
+		URL:          repoURL,
+		Depth:        1,
+		SingleBranch: true,
+		Tags:         git.NoTags,
 	}
 
 	// If a token was passed in, configure token auth for the git client
@@ -158,13 +167,82 @@ func CloneRepo(clonePath, repoURL string, token string) error {
 			Password: token,
 		}
 	}
+
 	// Clone the repo
-	_, err := git.PlainClone(clonePath, false, cloneOpts)
+	if revision == "" {
+		_, err := git.PlainClone(clonePath, false, cloneOpts)
+		return err
+	}
+	referenceNames := []plumbing.ReferenceName{plumbing.ReferenceName(revision)}
+	if strings.HasPrefix(revision, "refs/") && !referenceNames[0].IsBranch() && !referenceNames[0].IsTag() {
+		// Only branches and tags are fetched by the clone, the other references are fetched on their own
+		return fetchReference(clonePath, repoURL, referenceNames[0], cloneOpts.Auth)
+	}
+	if !strings.HasPrefix(revision, "refs/") {
+		referenceNames = []plumbing.ReferenceName{plumbing.NewBranchReferenceName(revision), plumbing.NewTagReferenceName(revision)}
+	}
+	for _, referenceName := range referenceNames {
+		cloneOpts.ReferenceName = referenceName
+		_, err := git.PlainClone(clonePath, false, cloneOpts)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, git.NoMatchingRefSpecError{}) {
+			return err
+		}
+	}
+
+	// The revision is neither a branch nor a tag, it is resolved in the full history
+	repo, err := git.PlainClone(clonePath, false, &git.CloneOptions{
+		URL:        repoURL,
+		Auth:       cloneOpts.Auth,
+		NoCheckout: true,
+	})
 	if err != nil {
 		return err
 	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return fmt.Errorf("unable to find the revision %s in %s: %v", revision, repoURL, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return worktree.Checkout(&git.CheckoutOptions{Hash: *hash})
+}
 
-	return nil
+// fetchReference fetches the reference of the repoURL, such as refs/pull/1/head, to a new repository at clonePath and checks it out
+func fetchReference(clonePath, repoURL string, referenceName plumbing.ReferenceName, auth transport.AuthMethod) error {
+	repo, err := git.PlainInit(clonePath, false)
+	if err != nil {
+		return err
+	}
+	remote, err := repo.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{repoURL},
+	})
+	if err != nil {
+		return err
+	}
+	err = remote.Fetch(&git.FetchOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", referenceName, referenceName))},
+		Depth:    1,
+		Auth:     auth,
+		Tags:     git.NoTags,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to find the revision %s in %s: %v", referenceName, repoURL, err)
+	}
+	reference, err := repo.Reference(referenceName, true)
+	if err != nil {
+		return err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return worktree.Checkout(&git.CheckoutOptions{Hash: reference.Hash()})
 }
 
 // SanitizeErrorMessage takes in a given error message and returns a new, santized error with things like tokens, removed
@@ -17,10 +17,17 @@ package util
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 
 	gitopsgenv1alpha1 "github.com/redhat-developer/gitops-generator/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
@@ -252,7 +259,7 @@ func TestCloneRepo(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := CloneRepo(tt.clonePath, tt.repo, tt.token)
+			err := CloneRepo(tt.clonePath, tt.repo, "", tt.token)
 			if tt.wantErr && (err == nil) {
 				t.Error("wanted error but got nil")
 			} else if !tt.wantErr && err != nil {
@@ -263,6 +270,142 @@ func TestCloneRepo(t *testing.T) {
 	}
 }
 
+func TestCloneRepoRevision(t *testing.T) {
+	// Set up a local repository with a commit on main, a tagged commit on a feature branch and a commit back on main
+	repoPath, err := ioutil.TempDir("", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(repoPath)
+	repo, err := git.PlainInit(repoPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(file string) plumbing.Hash {
+		if err := ioutil.WriteFile(filepath.Join(repoPath, file), []byte(file), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(file); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := worktree.Commit(file, &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	firstCommit := commit("first")
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}); err != nil {
+		t.Fatal(err)
+	}
+	featureCommit := commit("feature")
+	if _, err := repo.CreateTag("v1.0.0", featureCommit, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: head.Name()}); err != nil {
+		t.Fatal(err)
+	}
+	commit("second")
+	// A pull request reference, which is neither a branch nor a tag
+	if err := repo.Storer.SetReference(plumbing.NewHashReference("refs/pull/1/head", featureCommit)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		revision  string
+		wantFiles []string
+		wantErr   bool
+	}{
+		{
+			name:      "Default branch",
+			wantFiles: []string{"first", "second"},
+		},
+		{
+			name:      "Branch",
+			revision:  "feature",
+			wantFiles: []string{"feature", "first"},
+		},
+		{
+			name:      "Tag",
+			revision:  "v1.0.0",
+			wantFiles: []string{"feature", "first"},
+		},
+		{
+			name:      "Full reference",
+			revision:  "refs/heads/feature",
+			wantFiles: []string{"feature", "first"},
+		},
+		{
+			name:      "Pull request reference",
+			revision:  "refs/pull/1/head",
+			wantFiles: []string{"feature", "first"},
+		},
+		{
+			name:     "Missing reference",
+			revision: "refs/pull/2/head",
+			wantErr:  true,
+		},
+		{
+			name:      "Commit",
+			revision:  firstCommit.String(),
+			wantFiles: []string{"first"},
+		},
+		{
+			name:      "Abbreviated commit",
+			revision:  firstCommit.String()[:7],
+			wantFiles: []string{"first"},
+		},
+		{
+			name:     "Missing revision",
+			revision: "missing",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clonePath, err := ioutil.TempDir("", "clone")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(clonePath)
+
+			err = CloneRepo(clonePath, repoPath, tt.revision, "")
+			if tt.wantErr {
+				if err == nil {
+					t.Error("wanted error but got nil")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+
+			var files []string
+			entries, err := ioutil.ReadDir(clonePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				if !entry.IsDir() {
+					files = append(files, entry.Name())
+				}
+			}
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("got the files %v, want %v", files, tt.wantFiles)
+			}
+		})
+	}
+}
+
 func TestConvertGitHubURL(t *testing.T) {
 	tests := []struct {
 		name     string